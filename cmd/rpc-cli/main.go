@@ -1,18 +1,31 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"jsonrpc/internal/assertion"
+	"jsonrpc/internal/bench"
+	"jsonrpc/internal/bytesize"
+	"jsonrpc/internal/cassette"
+	"jsonrpc/internal/diff"
 	"jsonrpc/internal/executor"
 	"jsonrpc/internal/output"
 	"jsonrpc/internal/parser"
+	"jsonrpc/internal/ratelimit"
 	"jsonrpc/internal/tui"
+	"jsonrpc/pkg/constants"
 	"jsonrpc/pkg/types"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"github.com/zclconf/go-cty/cty"
 )
 
 var (
@@ -30,6 +43,48 @@ var (
 	headerFlags []string
 	configFlag  string
 	timeoutFlag int
+
+	// Variable flags
+	varFlags     []string
+	varFileFlags []string
+
+	// Report flags
+	reportFlags []string
+
+	// Execution flags
+	parallelFlag     int
+	batchFlag        bool
+	rateFlag         string
+	traceFlag        bool
+	totalTimeoutFlag time.Duration
+
+	// Response flags
+	maxResponseSizeFlag string
+	outputDirFlag       string
+
+	// Cassette flags
+	recordFlag      string
+	replayFlag      string
+	replayMatchFlag string
+
+	// TLS flags
+	cacertFlag   string
+	certFlag     string
+	keyFlag      string
+	insecureFlag bool
+
+	// Watch command flags
+	countFlag    int
+	durationFlag time.Duration
+
+	// Bench command flags
+	concurrencyFlag int
+	requestsFlag    int
+	benchRateFlag   string
+
+	// Diff command flags
+	configsFlag []string
+	ignoreFlags []string
 )
 
 func main() {
@@ -51,6 +106,10 @@ and executes them with flexible configuration management.`,
 	cmd.AddCommand(
 		lsCmd(),
 		runCmd(),
+		testCmd(),
+		watchCmd(),
+		benchCmd(),
+		diffCmd(),
 		validateCmd(),
 		versionCmd(),
 		tuiCmd(),
@@ -72,6 +131,7 @@ With request names, lists only specified requests.`,
 
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 	cmd.Flags().BoolVar(&detailed, "detailed", false, "Show detailed information")
+	addVariableFlags(cmd)
 
 	return cmd
 }
@@ -85,7 +145,10 @@ func runCmd() *cobra.Command {
 		},
 		Long: `Execute all requests or specific requests from an HCL file.
 With no request names, executes all requests.
-With request names, executes only specified requests.`,
+With request names, executes only specified requests.
+A batch name executes the requests it lists as JSON-RPC batches.
+Ctrl-C or --total-timeout cancels the run and reports the requests that
+were cancelled or never ran.`,
 		Args: cobra.MinimumNArgs(1),
 		RunE: runExecuteCommand,
 	}
@@ -95,12 +158,138 @@ With request names, executes only specified requests.`,
 	cmd.Flags().StringArrayVar(&headerFlags, "header", []string{}, "Override headers (can be repeated)")
 	cmd.Flags().StringVar(&configFlag, "config", "", "Use specific config profile")
 	cmd.Flags().IntVar(&timeoutFlag, "timeout", 0, "Override timeout in seconds")
+	addVariableFlags(cmd)
+	addReportFlags(cmd)
+	addParallelFlag(cmd)
+	addRateFlag(cmd)
+	addTLSFlags(cmd)
+	addTraceFlag(cmd)
+	addTotalTimeoutFlag(cmd)
+	addMaxResponseSizeFlag(cmd)
+	addCassetteFlags(cmd)
+	cmd.Flags().BoolVar(&batchFlag, "batch", false,
+		"Send requests sharing URL, headers and timeout as JSON-RPC batches")
+	cmd.Flags().StringVar(&outputDirFlag, "output-dir", "",
+		"Write each result to REQUEST_NAME.json in this directory instead of printing it")
+
+	return cmd
+}
+
+func testCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test <file> [request_names...]",
+		Short: "Execute requests and check their expect blocks",
+		Long: `Execute all requests or specific requests from an HCL file and evaluate the
+assertions in their expect blocks. Requests without an expect block must
+succeed. Exits with a non-zero status if any assertion fails.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runTestCommand,
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	cmd.Flags().StringVar(&urlFlag, "url", "", "Override URL for requests")
+	cmd.Flags().StringArrayVar(&headerFlags, "header", []string{}, "Override headers (can be repeated)")
+	cmd.Flags().StringVar(&configFlag, "config", "", "Use specific config profile")
+	cmd.Flags().IntVar(&timeoutFlag, "timeout", 0, "Override timeout in seconds")
+	addVariableFlags(cmd)
+	addReportFlags(cmd)
+	addParallelFlag(cmd)
+	addRateFlag(cmd)
+	addTLSFlags(cmd)
+	addTotalTimeoutFlag(cmd)
+	addMaxResponseSizeFlag(cmd)
+	addCassetteFlags(cmd)
+
+	return cmd
+}
+
+func watchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch <file> <subscription>",
+		Short: "Stream the notifications of a subscription",
+		Long: `Send the subscribe call of a subscribe block and print every notification
+until Ctrl-C, --count notifications or --duration. The subscription is
+cancelled with its unsubscribe method before exiting. Subscriptions need a
+persistent connection: a ws://, wss://, ipc:// or unix:// URL.`,
+		Args: cobra.ExactArgs(2),
+		RunE: runWatchCommand,
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output notifications as NDJSON")
+	cmd.Flags().StringVar(&urlFlag, "url", "", "Override URL for the subscription")
+	cmd.Flags().StringArrayVar(&headerFlags, "header", []string{}, "Override headers (can be repeated)")
+	cmd.Flags().StringVar(&configFlag, "config", "", "Use specific config profile")
+	cmd.Flags().IntVar(&timeoutFlag, "timeout", 0, "Override timeout in seconds for subscribe and unsubscribe calls")
+	cmd.Flags().IntVar(&countFlag, "count", 0, "Stop after this many notifications")
+	cmd.Flags().DurationVar(&durationFlag, "duration", 0, "Stop after this duration (e.g. 30s, 5m)")
+	addVariableFlags(cmd)
+
+	return cmd
+}
+
+func benchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bench <file> <request_names...>",
+		Short: "Load-test requests and report throughput and latency",
+		Long: `Send the named requests in turn, over and over, for --duration or until
+--requests have been sent (10s by default), and report throughput, errors by
+transport, HTTP status and JSON-RPC code, and latency percentiles.
+Without --rate, each of the --concurrency workers sends its next request as
+soon as the previous one finished. With --rate, requests are sent at that
+rate however long earlier ones take (open-loop), and time spent waiting for
+a free worker counts towards latency. Config profiles apply as in run.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: runBenchCommand,
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	cmd.Flags().StringVar(&urlFlag, "url", "", "Override URL for requests")
+	cmd.Flags().StringArrayVar(&headerFlags, "header", []string{}, "Override headers (can be repeated)")
+	cmd.Flags().StringVar(&configFlag, "config", "", "Use specific config profile")
+	cmd.Flags().IntVar(&timeoutFlag, "timeout", 0, "Override timeout in seconds")
+	cmd.Flags().IntVar(&concurrencyFlag, "concurrency", constants.DefaultBenchConcurrency,
+		"Number of requests in flight at most")
+	cmd.Flags().DurationVar(&durationFlag, "duration", 0, "Send requests for this duration (e.g. 30s, 5m)")
+	cmd.Flags().IntVar(&requestsFlag, "requests", 0, "Stop after sending this many requests")
+	cmd.Flags().StringVar(&benchRateFlag, "rate", "",
+		"Send requests at this rate, open-loop (e.g. 100/s, 6000/m)")
+	addVariableFlags(cmd)
+	addTLSFlags(cmd)
+	addMaxResponseSizeFlag(cmd)
+
+	return cmd
+}
+
+func diffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <file> --configs <from>,<to> [request_names...]",
+		Short: "Compare the responses of requests under two config profiles",
+		Long: `Execute all requests or specific requests once with each of two config
+profiles and compare their responses value by value. Prints the added,
+removed and changed paths of each request. --ignore leaves out values that
+are expected to differ. Exits with a non-zero status if any response differs
+or a request fails.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runDiffCommand,
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	cmd.Flags().StringSliceVar(&configsFlag, "configs", nil,
+		"The two config profiles to compare (e.g. production,staging)")
+	cmd.Flags().StringArrayVar(&ignoreFlags, "ignore", []string{},
+		"Ignore a path and everything below it, e.g. $.result.timestamp or $.result.txs[*].hash (can be repeated)")
+	cmd.Flags().StringArrayVar(&headerFlags, "header", []string{}, "Override headers (can be repeated)")
+	cmd.Flags().IntVar(&timeoutFlag, "timeout", 0, "Override timeout in seconds")
+	addVariableFlags(cmd)
+	addParallelFlag(cmd)
+	addTLSFlags(cmd)
+	addMaxResponseSizeFlag(cmd)
 
 	return cmd
 }
 
 func validateCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use: "validate <file>",
 		Aliases: []string{
 			"v",
@@ -110,6 +299,136 @@ func validateCmd() *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		RunE:  runValidateCommand,
 	}
+
+	addVariableFlags(cmd)
+
+	return cmd
+}
+
+// addReportFlags registers the --report flag on a command
+func addReportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&reportFlags, "report", []string{},
+		"Write a report as format[=path], format is junit or tap; stdout without path (can be repeated)")
+}
+
+// addParallelFlag registers the --parallel flag on a command
+func addParallelFlag(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&parallelFlag, "parallel", 1, "Number of requests to execute concurrently")
+}
+
+// addRateFlag registers the --rate flag on a command
+func addRateFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&rateFlag, "rate", "",
+		"Override the rate limit of every config profile (e.g. 25/s, 600/m)")
+}
+
+// addTraceFlag registers the -v/--trace flag on a command
+func addTraceFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&traceFlag, "trace", "v", false,
+		"Show the HTTP request and response of each call with a timing breakdown")
+}
+
+// addTotalTimeoutFlag registers the --total-timeout flag on a command
+func addTotalTimeoutFlag(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&totalTimeoutFlag, "total-timeout", 0,
+		"Cancel the whole run after this duration (e.g. 30s, 5m)")
+}
+
+// addMaxResponseSizeFlag registers the --max-response-size flag on a command
+func addMaxResponseSizeFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&maxResponseSizeFlag, "max-response-size", "",
+		"Fail responses larger than this size (e.g. 512KB, 100MB)")
+}
+
+// addCassetteFlags registers the --record, --replay and --replay-match
+// flags on a command
+func addCassetteFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&recordFlag, "record", "",
+		"Record each call and its response to this cassette file")
+	cmd.Flags().StringVar(&replayFlag, "replay", "",
+		"Answer calls from this cassette file instead of the network")
+	cmd.Flags().StringVar(&replayMatchFlag, "replay-match", string(cassette.MatchParams),
+		"How replayed calls are matched: method, params (method and params) or strict (also the URL)")
+	cmd.MarkFlagsMutuallyExclusive("record", "replay")
+}
+
+// setupCassette makes exec record to the --record cassette or replay the
+// --replay one. The returned function saves a recording; it does nothing
+// when not recording.
+func setupCassette(exec *executor.Executor) (func() error, error) {
+	save := func() error { return nil }
+
+	match, err := cassette.ParseMatch(replayMatchFlag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --replay-match: %w", err)
+	}
+
+	switch {
+	case replayFlag != "":
+		tape, err := cassette.Load(replayFlag, match)
+		if err != nil {
+			return nil, err
+		}
+		exec.SetReplay(tape)
+
+	case recordFlag != "":
+		tape := cassette.New()
+		exec.SetRecorder(tape)
+		save = func() error {
+			return tape.Save(recordFlag)
+		}
+	}
+
+	return save, nil
+}
+
+// runContext returns the context of a run, cancelled by Ctrl-C, SIGTERM or
+// --total-timeout. After the first signal, a second one kills the process.
+func runContext() (context.Context, context.CancelFunc, error) {
+	if totalTimeoutFlag < 0 {
+		return nil, nil, fmt.Errorf("--total-timeout must not be negative, got %s", totalTimeoutFlag)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	context.AfterFunc(ctx, stop)
+	if totalTimeoutFlag == 0 {
+		return ctx, stop, nil
+	}
+
+	ctx, cancel := context.WithTimeoutCause(ctx, totalTimeoutFlag,
+		fmt.Errorf("total timeout of %s exceeded", totalTimeoutFlag))
+	return ctx, func() {
+		cancel()
+		stop()
+	}, nil
+}
+
+// addTLSFlags registers the --cacert, --cert, --key and --insecure flags on
+// a command
+func addTLSFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&cacertFlag, "cacert", "", "CA certificate file (PEM) to verify servers with")
+	cmd.Flags().StringVar(&certFlag, "cert", "", "Client certificate file (PEM) for mutual TLS")
+	cmd.Flags().StringVar(&keyFlag, "key", "", "Client private key file (PEM) for mutual TLS")
+	cmd.Flags().BoolVar(&insecureFlag, "insecure", false, "Skip TLS certificate verification")
+}
+
+// newExecutor creates an executor configured from the execution flags
+func newExecutor() (*executor.Executor, error) {
+	if parallelFlag < 1 {
+		return nil, fmt.Errorf("--parallel must be at least 1, got %d", parallelFlag)
+	}
+
+	exec := executor.New()
+	exec.SetParallelism(parallelFlag)
+	exec.SetTrace(traceFlag)
+	return exec, nil
+}
+
+// addVariableFlags registers the --var and --var-file flags on a command
+func addVariableFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&varFlags, "var", []string{}, "Set a variable as name=value (can be repeated)")
+	cmd.Flags().StringArrayVar(&varFileFlags, "var-file", []string{},
+		"Load variables from an .hcl or .json file (can be repeated)")
 }
 
 func versionCmd() *cobra.Command {
@@ -129,7 +448,10 @@ func runListCommand(cmd *cobra.Command, args []string) error {
 	requestNames := args[1:]
 
 	// Parse HCL file
-	p := parser.New()
+	p, err := newParser()
+	if err != nil {
+		return err
+	}
 	hclFile, err := p.ParseFile(filename)
 	if err != nil {
 		return fmt.Errorf("failed to parse HCL file: %w", err)
@@ -160,7 +482,10 @@ func runExecuteCommand(cmd *cobra.Command, args []string) error {
 	requestNames := args[1:]
 
 	// Parse HCL file
-	p := parser.New()
+	p, err := newParser()
+	if err != nil {
+		return err
+	}
 	hclFile, err := p.ParseFile(filename)
 	if err != nil {
 		return fmt.Errorf("failed to parse HCL file: %w", err)
@@ -185,15 +510,56 @@ func runExecuteCommand(cmd *cobra.Command, args []string) error {
 	}
 
 	// Execute requests
-	exec := executor.New()
-	results, err := exec.ExecuteAll(hclFile, requestsToRun, overrides)
+	exec, err := newExecutor()
+	if err != nil {
+		return err
+	}
+	exec.SetBatch(batchFlag || selectsBatch(hclFile, requestNames))
+	saveCassette, err := setupCassette(exec)
+	if err != nil {
+		return err
+	}
+
+	formatter := output.New()
+	if outputDirFlag != "" {
+		if err := os.MkdirAll(outputDirFlag, 0o755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		formatter.SetOutputDir(outputDirFlag)
+
+		// Results are written as their requests finish. Written results are
+		// then only printed as a path, so they are released unless a later
+		// request's params refer to them.
+		referenced := referencedRequests(hclFile)
+		exec.SetOnResult(func(result *types.ExecutionResult) {
+			if formatter.WriteResultFile(result) && !referenced[result.Request.Name] {
+				result.Response.Result = nil
+			}
+		})
+	}
+
+	ctx, cancel, err := runContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	results, err := exec.ExecuteAll(ctx, hclFile, requestsToRun, overrides)
 	if err != nil {
 		return fmt.Errorf("failed to execute requests: %w", err)
 	}
+	if err := saveCassette(); err != nil {
+		return err
+	}
 
 	// Format and output results
-	formatter := output.New()
-	formatter.FormatExecutionResults(results, jsonOutput)
+	reportedToStdout, err := writeReports(formatter, filename, results)
+	if err != nil {
+		return err
+	}
+	if !reportedToStdout {
+		formatter.FormatExecutionResults(results, jsonOutput)
+	}
 
 	// Exit with error code if any request failed
 	for _, result := range results {
@@ -205,11 +571,95 @@ func runExecuteCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runTestCommand(cmd *cobra.Command, args []string) error {
+	filename := args[0]
+	requestNames := args[1:]
+
+	// Parse HCL file
+	p, err := newParser()
+	if err != nil {
+		return err
+	}
+	hclFile, err := p.ParseFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to parse HCL file: %w", err)
+	}
+
+	// Validate HCL file
+	validator := parser.NewValidator()
+	if err := validator.Validate(hclFile); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Filter requests if names specified
+	requestsToRun, err := filterRequests(hclFile, requestNames)
+	if err != nil {
+		return err
+	}
+
+	// Build CLI overrides
+	overrides, err := buildCLIOverrides()
+	if err != nil {
+		return err
+	}
+
+	// Execute requests and evaluate assertions
+	exec, err := newExecutor()
+	if err != nil {
+		return err
+	}
+	exec.SetBatch(batchFlag || selectsBatch(hclFile, requestNames))
+	saveCassette, err := setupCassette(exec)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel, err := runContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	results, err := exec.ExecuteAll(ctx, hclFile, requestsToRun, overrides)
+	if err != nil {
+		return fmt.Errorf("failed to execute requests: %w", err)
+	}
+	if err := saveCassette(); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		result.Assertions = assertion.Evaluate(result)
+	}
+
+	// Format and output results
+	formatter := output.New()
+	reportedToStdout, err := writeReports(formatter, filename, results)
+	if err != nil {
+		return err
+	}
+	if !reportedToStdout {
+		formatter.FormatTestResults(results, jsonOutput)
+	}
+
+	// Exit with error code if any assertion failed
+	for _, result := range results {
+		if !result.AssertionsPassed() {
+			os.Exit(1)
+		}
+	}
+
+	return nil
+}
+
 func runValidateCommand(cmd *cobra.Command, args []string) error {
 	filename := args[0]
 
 	// Parse HCL file
-	p := parser.New()
+	p, err := newParser()
+	if err != nil {
+		return err
+	}
 	hclFile, err := p.ParseFile(filename)
 	if err != nil {
 		return fmt.Errorf("failed to parse HCL file: %w", err)
@@ -224,10 +674,91 @@ func runValidateCommand(cmd *cobra.Command, args []string) error {
 	fmt.Printf("✓ File '%s' is valid\n", filename)
 	fmt.Printf("  - %d config(s) found\n", len(hclFile.Configs))
 	fmt.Printf("  - %d request(s) found\n", len(hclFile.Requests))
+	if len(hclFile.Batches) > 0 {
+		fmt.Printf("  - %d batch(es) found\n", len(hclFile.Batches))
+	}
+	if len(hclFile.Subscriptions) > 0 {
+		fmt.Printf("  - %d subscription(s) found\n", len(hclFile.Subscriptions))
+	}
 
 	return nil
 }
 
+// referencedRequests returns the names of the requests whose results other
+// requests refer to
+func referencedRequests(hclFile *types.HCLFile) map[string]bool {
+	referenced := make(map[string]bool)
+	for _, req := range hclFile.Requests {
+		for _, name := range req.DependsOn {
+			referenced[name] = true
+		}
+	}
+	return referenced
+}
+
+// writeReports writes the reports requested with --report. It reports whether
+// any of them was written to stdout, which replaces the regular output.
+func writeReports(formatter *output.Formatter, filename string, results []*types.ExecutionResult) (bool, error) {
+	specs := make([]output.ReportSpec, 0, len(reportFlags))
+	for _, value := range reportFlags {
+		spec, err := output.ParseReportSpec(value)
+		if err != nil {
+			return false, err
+		}
+		specs = append(specs, spec)
+	}
+
+	toStdout := false
+	for _, spec := range specs {
+		if err := formatter.WriteReport(spec, filename, results); err != nil {
+			return false, fmt.Errorf("failed to write %s report: %w", spec.Format, err)
+		}
+		if spec.Path == "" {
+			toStdout = true
+		}
+	}
+
+	return toStdout, nil
+}
+
+// buildVariables collects variable values from --var-file and --var flags.
+// Files are applied in order, then individual --var flags override them.
+func buildVariables() (map[string]cty.Value, error) {
+	variables := make(map[string]cty.Value)
+
+	for _, filename := range varFileFlags {
+		values, err := parser.LoadVarFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load variable file '%s': %w", filename, err)
+		}
+		for name, val := range values {
+			variables[name] = val
+		}
+	}
+
+	for _, assignment := range varFlags {
+		name, val, err := parser.ParseVarFlag(assignment)
+		if err != nil {
+			return nil, err
+		}
+		variables[name] = val
+	}
+
+	return variables, nil
+}
+
+// newParser creates a parser with variable values from the CLI flags
+func newParser() (*parser.Parser, error) {
+	variables, err := buildVariables()
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser.New()
+	p.SetVariables(variables)
+	return p, nil
+}
+
 // filterRequests filters requests by name if specified
 func filterRequests(hclFile *types.HCLFile, requestNames []string) ([]*types.Request, error) {
 	if len(requestNames) == 0 {
@@ -239,18 +770,43 @@ func filterRequests(hclFile *types.HCLFile, requestNames []string) ([]*types.Req
 		requestMap[req.Name] = req
 	}
 
-	var filtered []*types.Request
+	// Batch names expand to the requests they list
+	var names []string
 	for _, name := range requestNames {
+		if batch := hclFile.FindBatch(name); batch != nil {
+			names = append(names, batch.Requests...)
+			continue
+		}
+		names = append(names, name)
+	}
+
+	var filtered []*types.Request
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
 		req, exists := requestMap[name]
 		if !exists {
 			return nil, fmt.Errorf("request '%s' not found in file", name)
 		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
 		filtered = append(filtered, req)
 	}
 
 	return filtered, nil
 }
 
+// selectsBatch reports whether any of the names refers to a batch block
+func selectsBatch(hclFile *types.HCLFile, requestNames []string) bool {
+	for _, name := range requestNames {
+		if hclFile.FindBatch(name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // buildCLIOverrides builds CLI overrides from flags
 func buildCLIOverrides() (*types.CLIOverrides, error) {
 	overrides := types.NewCLIOverrides()
@@ -258,6 +814,32 @@ func buildCLIOverrides() (*types.CLIOverrides, error) {
 	overrides.Config = configFlag
 	overrides.Timeout = timeoutFlag
 
+	if rateFlag != "" {
+		if _, err := ratelimit.Parse(rateFlag); err != nil {
+			return nil, fmt.Errorf("invalid --rate: %w", err)
+		}
+		overrides.RateLimit = rateFlag
+	}
+
+	if maxResponseSizeFlag != "" {
+		limit, err := bytesize.Parse(maxResponseSizeFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --max-response-size: %w", err)
+		}
+		overrides.MaxResponseSize = limit
+	}
+
+	if cacertFlag != "" || certFlag != "" || keyFlag != "" || insecureFlag {
+		overrides.TLS = &types.TLSConfig{
+			CAFile:   cacertFlag,
+			CertFile: certFlag,
+			KeyFile:  keyFlag,
+		}
+		if insecureFlag {
+			overrides.TLS.InsecureSkipVerify = &insecureFlag
+		}
+	}
+
 	// Parse header flags
 	for _, header := range headerFlags {
 		parts := strings.SplitN(header, ":", 2)
@@ -273,7 +855,7 @@ func buildCLIOverrides() (*types.CLIOverrides, error) {
 }
 
 func tuiCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "tui [file]",
 		Short: "Interactive terminal user interface",
 		Long: `Launch an interactive TUI for browsing and executing JSON-RPC requests.
@@ -286,17 +868,271 @@ Navigation:
   - Space to select/deselect requests
   - Enter or l to view details
   - r to run selected requests
+  - p to change how many requests run concurrently
+  - w to watch a subscription in a live pane
   - / to search/filter
   - ? for help
   - q to quit`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: runTUICommand,
 	}
+
+	addVariableFlags(cmd)
+	addParallelFlag(cmd)
+	addTraceFlag(cmd)
+
+	return cmd
+}
+
+func runWatchCommand(cmd *cobra.Command, args []string) error {
+	filename := args[0]
+	name := args[1]
+
+	// Parse HCL file
+	p, err := newParser()
+	if err != nil {
+		return err
+	}
+	hclFile, err := p.ParseFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to parse HCL file: %w", err)
+	}
+
+	// Validate HCL file
+	validator := parser.NewValidator()
+	if err := validator.Validate(hclFile); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	subscription := hclFile.FindSubscription(name)
+	if subscription == nil {
+		return fmt.Errorf("subscription '%s' not found in file", name)
+	}
+
+	// Build CLI overrides
+	overrides, err := buildCLIOverrides()
+	if err != nil {
+		return err
+	}
+
+	// Stop on Ctrl-C, after --duration or after --count notifications
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if durationFlag > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, durationFlag)
+		defer cancelTimeout()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	formatter := output.New()
+	received := 0
+
+	exec := executor.New()
+	err = exec.Subscribe(ctx, hclFile, subscription, overrides, func(notification *types.Notification) {
+		received++
+		formatter.FormatNotification(notification, jsonOutput)
+		if countFlag > 0 && received >= countFlag {
+			cancel()
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if !jsonOutput {
+		fmt.Fprintf(os.Stderr, "Received %d notification(s) from %s\n", received, subscription.Name)
+	}
+	return nil
+}
+
+func runBenchCommand(cmd *cobra.Command, args []string) error {
+	filename := args[0]
+	requestNames := args[1:]
+
+	opts := bench.Options{
+		Concurrency: concurrencyFlag,
+		Duration:    durationFlag,
+		Requests:    requestsFlag,
+	}
+	if opts.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1, got %d", opts.Concurrency)
+	}
+	if opts.Duration < 0 || opts.Requests < 0 {
+		return fmt.Errorf("--duration and --requests must not be negative")
+	}
+	if opts.Duration == 0 && opts.Requests == 0 {
+		opts.Duration = constants.DefaultBenchDurationSeconds * time.Second
+	}
+	if benchRateFlag != "" {
+		rate, err := ratelimit.Parse(benchRateFlag)
+		if err != nil {
+			return fmt.Errorf("invalid --rate: %w", err)
+		}
+		opts.Rate = &rate
+	}
+
+	// Parse HCL file
+	p, err := newParser()
+	if err != nil {
+		return err
+	}
+	hclFile, err := p.ParseFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to parse HCL file: %w", err)
+	}
+
+	// Validate HCL file
+	validator := parser.NewValidator()
+	if err := validator.Validate(hclFile); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	requestsToRun, err := filterRequests(hclFile, requestNames)
+	if err != nil {
+		return err
+	}
+
+	// Build CLI overrides
+	overrides, err := buildCLIOverrides()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel, err := runContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	report, err := bench.Run(ctx, executor.New(), hclFile, requestsToRun, overrides, opts)
+	if err != nil {
+		return err
+	}
+
+	output.New().FormatBenchReport(report, jsonOutput)
+
+	// Exit with error code if no request succeeded
+	if report.Succeeded == 0 {
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+func runDiffCommand(cmd *cobra.Command, args []string) error {
+	filename := args[0]
+	requestNames := args[1:]
+
+	if len(configsFlag) != 2 {
+		return fmt.Errorf("--configs needs two config names, e.g. production,staging")
+	}
+	fromConfig, toConfig := configsFlag[0], configsFlag[1]
+	if fromConfig == toConfig {
+		return fmt.Errorf("--configs must name two different configs, got '%s' twice", fromConfig)
+	}
+
+	ignore := make([]diff.Pattern, 0, len(ignoreFlags))
+	for _, path := range ignoreFlags {
+		pattern, err := diff.ParsePattern(path)
+		if err != nil {
+			return fmt.Errorf("invalid --ignore: %w", err)
+		}
+		ignore = append(ignore, pattern)
+	}
+
+	// Parse HCL file
+	p, err := newParser()
+	if err != nil {
+		return err
+	}
+	hclFile, err := p.ParseFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to parse HCL file: %w", err)
+	}
+
+	// Validate HCL file
+	validator := parser.NewValidator()
+	if err := validator.Validate(hclFile); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	for _, name := range configsFlag {
+		if _, exists := hclFile.Configs[name]; !exists {
+			return fmt.Errorf("config '%s' not found in file", name)
+		}
+	}
+
+	requestsToRun, err := filterRequests(hclFile, requestNames)
+	if err != nil {
+		return err
+	}
+
+	// Build CLI overrides
+	overrides, err := buildCLIOverrides()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel, err := runContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	// Both configs run at the same time, each with its own executor
+	results := make([][]*types.ExecutionResult, len(configsFlag))
+	errs := make([]error, len(configsFlag))
+	var wg sync.WaitGroup
+	for i, name := range configsFlag {
+		exec, err := newExecutor()
+		if err != nil {
+			return err
+		}
+
+		configOverrides := *overrides
+		configOverrides.Config = name
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = exec.ExecuteAll(ctx, hclFile, requestsToRun, &configOverrides)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("failed to execute requests with config '%s': %w", configsFlag[i], err)
+		}
+	}
+
+	diffs := diff.Results(fromConfig, toConfig, results[0], results[1], ignore)
+	output.New().FormatDiffResults(fromConfig, toConfig, diffs, jsonOutput)
+
+	// Exit with error code if any response differs
+	for _, d := range diffs {
+		if d.Status != types.DiffSame {
+			os.Exit(1)
+		}
+	}
+
+	return nil
 }
 
 func runTUICommand(cmd *cobra.Command, args []string) error {
 	var model *tui.Model
 
+	variables, err := buildVariables()
+	if err != nil {
+		return err
+	}
+
+	if parallelFlag < 1 {
+		return fmt.Errorf("--parallel must be at least 1, got %d", parallelFlag)
+	}
+
 	if len(args) == 0 {
 		// Find HCL files in current directory
 		hclFiles, err := findHCLFiles()
@@ -314,6 +1150,9 @@ func runTUICommand(cmd *cobra.Command, args []string) error {
 		// Direct file specified
 		model = tui.NewModelWithFile(args[0])
 	}
+	model.SetVariables(variables)
+	model.SetParallelism(parallelFlag)
+	model.SetTrace(traceFlag)
 
 	// Create and run Bubble Tea program
 	p := tea.NewProgram(
@@ -322,7 +1161,7 @@ func runTUICommand(cmd *cobra.Command, args []string) error {
 		tea.WithMouseCellMotion(),
 	)
 
-	_, err := p.Run()
+	_, err = p.Run()
 	return err
 }
 
@@ -0,0 +1,185 @@
+package assertion
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"jsonrpc/pkg/types"
+)
+
+// Evaluate checks an execution result against its request's expect block.
+// Requests without an expect block only assert that the call succeeded.
+func Evaluate(result *types.ExecutionResult) []types.AssertionResult {
+	if result.Error != nil {
+		return []types.AssertionResult{
+			fail("request succeeded", "request failed: %s", result.Error.Error()),
+		}
+	}
+
+	expect := result.Request.Expect
+	if expect == nil {
+		expect = &types.Expectation{}
+	}
+
+	var assertions []types.AssertionResult
+
+	// Error expectations replace the success check
+	if expect.ErrorCode != nil {
+		assertions = append(assertions, checkErrorCode(result.Response, *expect.ErrorCode))
+	} else {
+		assertions = append(assertions, checkNoRPCError(result.Response))
+	}
+
+	if expect.MaxDuration > 0 {
+		assertions = append(assertions, checkMaxDuration(result, expect))
+	}
+
+	// Result checks only apply when a result was returned
+	if expect.ResultType == "" && !expect.HasEquals && len(expect.JSONPath) == 0 {
+		return assertions
+	}
+
+	if result.Response == nil || result.Response.IsError() {
+		return append(assertions, fail("result checks", "no result to check"))
+	}
+
+	var actual any
+	if len(result.Response.Result) > 0 {
+		if err := json.Unmarshal(result.Response.Result, &actual); err != nil {
+			return append(assertions, fail("result checks", "failed to decode result: %s", err.Error()))
+		}
+	}
+
+	if expect.ResultType != "" {
+		assertions = append(assertions, checkResultType(actual, expect.ResultType))
+	}
+
+	if expect.HasEquals {
+		assertions = append(assertions, checkEquals("result equals", actual, expect.Equals))
+	}
+
+	paths := make([]string, 0, len(expect.JSONPath))
+	for path := range expect.JSONPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		name := fmt.Sprintf("jsonpath %s", path)
+		value, err := Lookup(actual, path)
+		if err != nil {
+			assertions = append(assertions, fail(name, "%s", err.Error()))
+			continue
+		}
+		assertions = append(assertions, checkEquals(name, value, expect.JSONPath[path]))
+	}
+
+	return assertions
+}
+
+// checkNoRPCError asserts that the response is not a JSON-RPC error
+func checkNoRPCError(response *types.JSONRPCResponse) types.AssertionResult {
+	const name = "no RPC error"
+	if response != nil && response.IsError() {
+		return fail(name, "got RPC error %d: %s", response.Error.Code, response.Error.Message)
+	}
+	return pass(name)
+}
+
+// checkErrorCode asserts that the response is a JSON-RPC error with the given code
+func checkErrorCode(response *types.JSONRPCResponse, code int) types.AssertionResult {
+	name := fmt.Sprintf("error_code %d", code)
+	if response == nil || !response.IsError() {
+		return fail(name, "expected RPC error %d, got success", code)
+	}
+	if response.Error.Code != code {
+		return fail(name, "expected RPC error %d, got %d: %s", code, response.Error.Code, response.Error.Message)
+	}
+	return pass(name)
+}
+
+// checkMaxDuration asserts that the request completed within the limit
+func checkMaxDuration(result *types.ExecutionResult, expect *types.Expectation) types.AssertionResult {
+	name := fmt.Sprintf("max_duration %s", expect.MaxDuration)
+	if result.Duration > expect.MaxDuration {
+		return fail(name, "took %dms, limit is %dms",
+			result.Duration.Milliseconds(), expect.MaxDuration.Milliseconds())
+	}
+	return pass(name)
+}
+
+// checkResultType asserts the JSON type of the result
+func checkResultType(actual any, want string) types.AssertionResult {
+	name := fmt.Sprintf("result_type %s", want)
+	if got := jsonType(actual); got != want {
+		return fail(name, "expected %s, got %s", want, got)
+	}
+	return pass(name)
+}
+
+// checkEquals asserts that two values are equal after JSON normalization,
+// so that HCL integers compare equal to decoded JSON numbers
+func checkEquals(name string, actual, expected any) types.AssertionResult {
+	normalized, err := normalize(expected)
+	if err != nil {
+		return fail(name, "invalid expected value: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(actual, normalized) {
+		return fail(name, "expected %s, got %s", compactJSON(normalized), compactJSON(actual))
+	}
+	return pass(name)
+}
+
+// normalize round-trips a value through JSON so it has decoded JSON types
+func normalize(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// jsonType returns the JSON type name of a decoded JSON value
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// compactJSON renders a value as compact JSON for messages
+func compactJSON(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+func pass(name string) types.AssertionResult {
+	return types.AssertionResult{Name: name, Passed: true}
+}
+
+func fail(name, format string, args ...any) types.AssertionResult {
+	return types.AssertionResult{Name: name, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,146 @@
+package assertion
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+func TestLookup(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(`{
+		"number": "0x10",
+		"transactions": [{"hash": "0xaa"}, {"hash": "0xbb"}],
+		"odd key": true
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    any
+		wantErr bool
+	}{
+		{name: "root", path: "$", want: doc},
+		{name: "field", path: "$.number", want: "0x10"},
+		{name: "bare field", path: "number", want: "0x10"},
+		{name: "array index", path: "$.transactions[1].hash", want: "0xbb"},
+		{name: "negative index", path: "$.transactions[-1].hash", want: "0xbb"},
+		{name: "quoted key", path: "$['odd key']", want: true},
+		{name: "missing field", path: "$.missing", wantErr: true},
+		{name: "index out of range", path: "$.transactions[5]", wantErr: true},
+		{name: "field of string", path: "$.number.value", wantErr: true},
+		{name: "unclosed bracket", path: "$.transactions[0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Lookup(doc, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Lookup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	errorCode := -32601
+
+	tests := []struct {
+		name       string
+		result     *types.ExecutionResult
+		wantPassed []bool
+	}{
+		{
+			name: "transport error fails",
+			result: &types.ExecutionResult{
+				Request: &types.Request{Name: "test"},
+				Error:   errors.New("connection refused"),
+			},
+			wantPassed: []bool{false},
+		},
+		{
+			name: "no expect block checks success",
+			result: &types.ExecutionResult{
+				Request:  &types.Request{Name: "test"},
+				Response: &types.JSONRPCResponse{Result: json.RawMessage(`"0x1"`)},
+			},
+			wantPassed: []bool{true},
+		},
+		{
+			name: "result checks",
+			result: &types.ExecutionResult{
+				Request: &types.Request{
+					Name: "test",
+					Expect: &types.Expectation{
+						ResultType: "object",
+						Equals:     map[string]any{"number": "0x10", "size": 2},
+						HasEquals:  true,
+						JSONPath: map[string]any{
+							"$.number": "0x10",
+							"$.size":   3,
+						},
+					},
+				},
+				Response: &types.JSONRPCResponse{Result: json.RawMessage(`{"number": "0x10", "size": 2}`)},
+			},
+			// no RPC error, result_type, equals, jsonpath $.number, jsonpath $.size
+			wantPassed: []bool{true, true, true, true, false},
+		},
+		{
+			name: "expected error code",
+			result: &types.ExecutionResult{
+				Request: &types.Request{
+					Name:   "test",
+					Expect: &types.Expectation{ErrorCode: &errorCode},
+				},
+				Response: &types.JSONRPCResponse{Error: &types.RPCError{Code: -32601, Message: "not found"}},
+			},
+			wantPassed: []bool{true},
+		},
+		{
+			name: "unexpected RPC error",
+			result: &types.ExecutionResult{
+				Request:  &types.Request{Name: "test"},
+				Response: &types.JSONRPCResponse{Error: &types.RPCError{Code: -32000, Message: "boom"}},
+			},
+			wantPassed: []bool{false},
+		},
+		{
+			name: "max duration exceeded",
+			result: &types.ExecutionResult{
+				Request: &types.Request{
+					Name:   "test",
+					Expect: &types.Expectation{MaxDuration: 100 * time.Millisecond},
+				},
+				Response: &types.JSONRPCResponse{Result: json.RawMessage(`null`)},
+				Duration: 250 * time.Millisecond,
+			},
+			wantPassed: []bool{true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertions := Evaluate(tt.result)
+			if len(assertions) != len(tt.wantPassed) {
+				t.Fatalf("Evaluate() returned %d assertions, want %d: %+v",
+					len(assertions), len(tt.wantPassed), assertions)
+			}
+			for i, assertion := range assertions {
+				if assertion.Passed != tt.wantPassed[i] {
+					t.Errorf("assertion %q passed = %v, want %v (%s)",
+						assertion.Name, assertion.Passed, tt.wantPassed[i], assertion.Message)
+				}
+			}
+		})
+	}
+}
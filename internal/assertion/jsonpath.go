@@ -0,0 +1,108 @@
+package assertion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Lookup resolves a JSONPath expression against a decoded JSON document.
+// Supported syntax is the root `$` (optional), `.field`, `['field']` or
+// `["field"]`, and `[index]` with negative indexes counting from the end.
+func Lookup(doc any, path string) (any, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := doc
+	for _, segment := range segments {
+		switch node := current.(type) {
+		case map[string]any:
+			if segment.isIndex {
+				return nil, fmt.Errorf("cannot index object with [%d] in %s", segment.index, path)
+			}
+			value, exists := node[segment.key]
+			if !exists {
+				return nil, fmt.Errorf("field '%s' not found in %s", segment.key, path)
+			}
+			current = value
+
+		case []any:
+			if !segment.isIndex {
+				return nil, fmt.Errorf("cannot access field '%s' of array in %s", segment.key, path)
+			}
+			index := segment.index
+			if index < 0 {
+				index += len(node)
+			}
+			if index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("index %d out of range (length %d) in %s", segment.index, len(node), path)
+			}
+			current = node[index]
+
+		default:
+			return nil, fmt.Errorf("cannot traverse %s value in %s", jsonType(current), path)
+		}
+	}
+
+	return current, nil
+}
+
+// pathSegment is a single field access or array index in a path
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parsePath splits a JSONPath expression into segments
+func parsePath(path string) ([]pathSegment, error) {
+	rest := strings.TrimSpace(path)
+	rest = strings.TrimPrefix(rest, "$")
+
+	var segments []pathSegment
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty field name in path %s", path)
+			}
+			segments = append(segments, pathSegment{key: rest[:end]})
+			rest = rest[end:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed bracket in path %s", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, pathSegment{key: inner[1 : len(inner)-1]})
+				continue
+			}
+
+			index, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index '%s' in path %s", inner, path)
+			}
+			segments = append(segments, pathSegment{index: index, isIndex: true})
+
+		default:
+			// Allow a bare leading field name, e.g. "number" or "block.hash"
+			if len(segments) > 0 {
+				return nil, fmt.Errorf("unexpected character '%c' in path %s", rest[0], path)
+			}
+			rest = "." + rest
+		}
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,143 @@
+// Package auth implements the authentication types of auth blocks and
+// credential helper commands.
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"jsonrpc/pkg/types"
+)
+
+// Provider adds credentials to the headers of a request
+type Provider interface {
+	// Apply sets the credential headers for one request
+	Apply(ctx context.Context, headers map[string]string) error
+}
+
+// New creates the provider for an auth block. client is used to fetch
+// OAuth2 tokens.
+func New(config *types.AuthConfig, client *http.Client) (Provider, error) {
+	switch config.Type {
+	case types.AuthBasic:
+		return &basicProvider{username: config.Username, password: config.Password}, nil
+	case types.AuthBearer:
+		return &bearerProvider{token: config.Token, tokenEnv: config.TokenEnv, tokenFile: config.TokenFile}, nil
+	case types.AuthOAuth2ClientCredentials:
+		return newOAuth2Provider(config, client), nil
+	case types.AuthJWTHS256:
+		return newJWTProvider(config.SecretFile)
+	default:
+		return nil, fmt.Errorf("unsupported auth type '%s'", config.Type)
+	}
+}
+
+// Registry hands out one provider per auth block, so that cached tokens are
+// shared by the requests of a run. It is safe for concurrent use.
+type Registry struct {
+	clients func(settings *types.TLSConfig) (*http.Client, error)
+
+	mu        sync.Mutex
+	providers map[string]Provider
+}
+
+// NewRegistry creates a registry whose providers fetch tokens with the
+// client that clients returns for the profile's tls block
+func NewRegistry(clients func(settings *types.TLSConfig) (*http.Client, error)) *Registry {
+	return &Registry{clients: clients, providers: make(map[string]Provider)}
+}
+
+// Provider returns the provider for an auth block used with a tls block,
+// creating it on first use
+func (r *Registry) Provider(config *types.AuthConfig, settings *types.TLSConfig) (Provider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := config.Key() + "\n" + settings.Key()
+	if provider, exists := r.providers[key]; exists {
+		return provider, nil
+	}
+
+	client, err := r.clients(settings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS settings: %w", err)
+	}
+	provider, err := New(config, client)
+	if err != nil {
+		return nil, err
+	}
+	r.providers[key] = provider
+	return provider, nil
+}
+
+// CommandProvider returns the provider for a credential helper, creating it
+// on first use
+func (r *Registry) CommandProvider(helper *types.CredentialHelper) (Provider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := "command\x00" + helper.Key()
+	if provider, exists := r.providers[key]; exists {
+		return provider, nil
+	}
+
+	provider, err := NewCommandProvider(helper)
+	if err != nil {
+		return nil, err
+	}
+	r.providers[key] = provider
+	return provider, nil
+}
+
+// basicProvider sends HTTP basic credentials
+type basicProvider struct {
+	username string
+	password string
+}
+
+// Apply sets a basic Authorization header
+func (p *basicProvider) Apply(_ context.Context, headers map[string]string) error {
+	credentials := base64.StdEncoding.EncodeToString([]byte(p.username + ":" + p.password))
+	headers["Authorization"] = "Basic " + credentials
+	return nil
+}
+
+// bearerProvider sends a static token from the auth block, an environment
+// variable or a file. Variables and files are read for every request, so a
+// rotated token is picked up.
+type bearerProvider struct {
+	token     string
+	tokenEnv  string
+	tokenFile string
+}
+
+// Apply sets a bearer Authorization header
+func (p *bearerProvider) Apply(_ context.Context, headers map[string]string) error {
+	token := p.token
+	switch {
+	case p.tokenEnv != "":
+		value, ok := os.LookupEnv(p.tokenEnv)
+		if !ok || strings.TrimSpace(value) == "" {
+			return fmt.Errorf("bearer token environment variable %s is not set", p.tokenEnv)
+		}
+		token = value
+	case p.tokenFile != "":
+		data, err := os.ReadFile(p.tokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read bearer token file: %w", err)
+		}
+		token = string(data)
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return fmt.Errorf("bearer token is empty")
+	}
+	headers["Authorization"] = "Bearer " + token
+	return nil
+}
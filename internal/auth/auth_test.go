@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// writeFile writes content to a file in a temporary directory
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// apply creates a provider and returns the Authorization header it sets
+func apply(t *testing.T, config *types.AuthConfig) (string, error) {
+	t.Helper()
+	provider, err := New(config, http.DefaultClient)
+	if err != nil {
+		return "", err
+	}
+	headers := make(map[string]string)
+	err = provider.Apply(context.Background(), headers)
+	return headers["Authorization"], err
+}
+
+func TestProviders(t *testing.T) {
+	t.Setenv("RPC_TOKEN", "env-token")
+
+	tests := []struct {
+		name    string
+		config  *types.AuthConfig
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "basic",
+			config: &types.AuthConfig{Type: types.AuthBasic, Username: "alice", Password: "s3cret"},
+			want:   "Basic YWxpY2U6czNjcmV0",
+		},
+		{
+			name:   "bearer token",
+			config: &types.AuthConfig{Type: types.AuthBearer, Token: "abc"},
+			want:   "Bearer abc",
+		},
+		{
+			name:   "bearer env",
+			config: &types.AuthConfig{Type: types.AuthBearer, TokenEnv: "RPC_TOKEN"},
+			want:   "Bearer env-token",
+		},
+		{
+			name:   "bearer file",
+			config: &types.AuthConfig{Type: types.AuthBearer, TokenFile: writeFile(t, "token", "file-token\n")},
+			want:   "Bearer file-token",
+		},
+		{
+			name:    "bearer env not set",
+			config:  &types.AuthConfig{Type: types.AuthBearer, TokenEnv: "RPC_TOKEN_MISSING"},
+			wantErr: "RPC_TOKEN_MISSING is not set",
+		},
+		{
+			name:    "jwt secret not hex",
+			config:  &types.AuthConfig{Type: types.AuthJWTHS256, SecretFile: writeFile(t, "jwt.hex", "not hex")},
+			wantErr: "not hex encoded",
+		},
+		{
+			name:    "unknown type",
+			config:  &types.AuthConfig{Type: "digest"},
+			wantErr: "unsupported auth type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := apply(t, tt.config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJWTProvider(t *testing.T) {
+	secretFile := writeFile(t, "jwt.hex", "0x"+strings.Repeat("ab", 32)+"\n")
+
+	provider, err := newJWTProvider(secretFile)
+	if err != nil {
+		t.Fatalf("newJWTProvider() error = %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	provider.now = func() time.Time { return now }
+
+	token := provider.sign()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token %q does not have three parts", token)
+	}
+
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || string(claims) != `{"iat":1700000000}` {
+		t.Errorf("claims = %s (%v), want iat 1700000000", claims, err)
+	}
+
+	mac := hmac.New(sha256.New, provider.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if parts[2] != base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) {
+		t.Error("signature does not verify with the shared secret")
+	}
+
+	// Every request gets a token with the current iat
+	now = now.Add(5 * time.Second)
+	if provider.sign() == token {
+		t.Error("token was not re-signed with a new iat")
+	}
+}
+
+func TestOAuth2Provider(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "cli" || secret != "s3cret" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "rpc:read rpc:write" {
+			http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+			return
+		}
+
+		n := atomic.AddInt32(&fetches, 1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "token-" + strconv.Itoa(int(n)),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer server.Close()
+
+	config := &types.AuthConfig{
+		Type:         types.AuthOAuth2ClientCredentials,
+		TokenURL:     server.URL,
+		ClientID:     "cli",
+		ClientSecret: "s3cret",
+		Scopes:       []string{"rpc:read", "rpc:write"},
+	}
+	provider := newOAuth2Provider(config, server.Client())
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	headers := make(map[string]string)
+	for i := 0; i < 3; i++ {
+		if err := provider.Apply(context.Background(), headers); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+	}
+	if headers["Authorization"] != "Bearer token-1" || atomic.LoadInt32(&fetches) != 1 {
+		t.Errorf("Authorization = %q after %d fetches, want cached token-1", headers["Authorization"], fetches)
+	}
+
+	// The token is refreshed shortly before it expires
+	now = now.Add(time.Hour - 10*time.Second)
+	if err := provider.Apply(context.Background(), headers); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if headers["Authorization"] != "Bearer token-2" {
+		t.Errorf("Authorization = %q, want refreshed token-2", headers["Authorization"])
+	}
+
+	config.ClientSecret = "wrong"
+	if _, err := apply(t, config); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("error = %v, want token request failure", err)
+	}
+}
+
+func TestOAuth2Provider_ShortLivedToken(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&fetches, 1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "token-" + strconv.Itoa(int(n)),
+			"expires_in":   20,
+		})
+	}))
+	defer server.Close()
+
+	config := &types.AuthConfig{Type: types.AuthOAuth2ClientCredentials, TokenURL: server.URL}
+	provider := newOAuth2Provider(config, server.Client())
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	// A token living shorter than the expiry margin is still reused, for
+	// half its lifetime
+	headers := make(map[string]string)
+	for _, elapsed := range []time.Duration{0, time.Second, 8 * time.Second} {
+		now = now.Add(elapsed)
+		if err := provider.Apply(context.Background(), headers); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&fetches); got != 1 {
+		t.Errorf("%d token fetches within 10s, want 1", got)
+	}
+
+	now = now.Add(time.Second)
+	if err := provider.Apply(context.Background(), headers); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if headers["Authorization"] != "Bearer token-2" {
+		t.Errorf("Authorization = %q after half the lifetime, want refreshed token-2", headers["Authorization"])
+	}
+}
+
+func TestRegistry_Provider(t *testing.T) {
+	registry := NewRegistry(func(*types.TLSConfig) (*http.Client, error) {
+		return http.DefaultClient, nil
+	})
+	config := &types.AuthConfig{Type: types.AuthBearer, Token: "abc"}
+
+	first, err := registry.Provider(config, nil)
+	if err != nil {
+		t.Fatalf("Provider() error = %v", err)
+	}
+	second, _ := registry.Provider(&types.AuthConfig{Type: types.AuthBearer, Token: "abc"}, nil)
+	if first != second {
+		t.Error("identical auth blocks should share a provider")
+	}
+	other, _ := registry.Provider(&types.AuthConfig{Type: types.AuthBearer, Token: "def"}, nil)
+	if first == other {
+		t.Error("different auth blocks should not share a provider")
+	}
+}
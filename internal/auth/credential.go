@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// commandProvider runs a credential helper and caches its output until it
+// expires, for the whole run if it has no expiry
+type commandProvider struct {
+	command  []string
+	header   string
+	cacheDir string // empty if credentials are not cached on disk
+	now      func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time // zero if the credential does not expire
+}
+
+// cachedCredential is the output of a credential helper and the format of
+// the disk cache
+type cachedCredential struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// NewCommandProvider creates the provider for a credential_command. Cached
+// credentials are stored in the user cache directory.
+func NewCommandProvider(helper *types.CredentialHelper) (Provider, error) {
+	p := &commandProvider{command: helper.Command, header: helper.Header, now: time.Now}
+	if helper.Cache {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find cache directory: %w", err)
+		}
+		p.cacheDir = filepath.Join(dir, "rpc-cli", "credentials")
+	}
+	return p, nil
+}
+
+// Apply sets the helper's header to a cached or new credential. A plain
+// token in the Authorization header is sent as a bearer token.
+func (p *commandProvider) Apply(ctx context.Context, headers map[string]string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.valid() {
+		if err := p.refresh(ctx); err != nil {
+			return err
+		}
+	}
+
+	value := p.token
+	if strings.EqualFold(p.header, "Authorization") && !strings.Contains(value, " ") {
+		value = "Bearer " + value
+	}
+	headers[p.header] = value
+	return nil
+}
+
+// valid reports whether the in-memory credential can still be used
+func (p *commandProvider) valid() bool {
+	return p.token != "" && (p.expires.IsZero() || p.now().Before(p.expires))
+}
+
+// refresh loads the credential from the disk cache or runs the command
+func (p *commandProvider) refresh(ctx context.Context) error {
+	if p.cacheDir != "" {
+		if cached, ok := p.readCache(); ok {
+			p.token, p.expires = cached.Token, cached.ExpiresAt.Add(-tokenExpiryMargin)
+			return nil
+		}
+	}
+
+	credential, err := p.run(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.token = credential.Token
+	p.expires = time.Time{}
+	if !credential.ExpiresAt.IsZero() {
+		p.expires = credential.ExpiresAt.Add(-tokenExpiryMargin)
+		if p.cacheDir != "" {
+			if err := p.writeCache(credential); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// run executes the command and parses its output
+func (p *commandProvider) run(ctx context.Context) (*cachedCredential, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.command[0], p.command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("credential command failed: %w: %s", err, message)
+		}
+		return nil, fmt.Errorf("credential command failed: %w", err)
+	}
+
+	return parseCredential(stdout.Bytes())
+}
+
+// parseCredential parses the output of a credential helper: a plain token,
+// a JSON object with token and expires_at (RFC 3339), or a kubectl
+// ExecCredential with status.token and status.expirationTimestamp
+func parseCredential(output []byte) (*cachedCredential, error) {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return nil, fmt.Errorf("credential command printed nothing")
+	}
+
+	if output[0] != '{' {
+		if bytes.ContainsAny(output, "\r\n") {
+			return nil, fmt.Errorf("credential command printed more than one line")
+		}
+		return &cachedCredential{Token: string(output)}, nil
+	}
+
+	var parsed struct {
+		Token     string `json:"token"`
+		ExpiresAt string `json:"expires_at"`
+		Status    struct {
+			Token               string `json:"token"`
+			ExpirationTimestamp string `json:"expirationTimestamp"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse credential command output: %w", err)
+	}
+
+	token, expiresAt := parsed.Token, parsed.ExpiresAt
+	if token == "" {
+		token, expiresAt = parsed.Status.Token, parsed.Status.ExpirationTimestamp
+	}
+	if token == "" {
+		return nil, fmt.Errorf("credential command output has no token")
+	}
+
+	credential := &cachedCredential{Token: token}
+	if expiresAt != "" {
+		expires, err := time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credential expiry '%s': %w", expiresAt, err)
+		}
+		credential.ExpiresAt = expires
+	}
+	return credential, nil
+}
+
+// cachePath returns the cache file of the command
+func (p *commandProvider) cachePath() string {
+	sum := sha256.Sum256([]byte(strings.Join(p.command, "\x00")))
+	return filepath.Join(p.cacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// readCache returns the cached credential if it has not expired. A missing
+// or unreadable cache is not an error; the command is run instead.
+func (p *commandProvider) readCache() (*cachedCredential, bool) {
+	data, err := os.ReadFile(p.cachePath())
+	if err != nil {
+		return nil, false
+	}
+
+	var cached cachedCredential
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Token == "" {
+		return nil, false
+	}
+	if cached.ExpiresAt.IsZero() || !p.now().Before(cached.ExpiresAt.Add(-tokenExpiryMargin)) {
+		return nil, false
+	}
+	return &cached, true
+}
+
+// writeCache stores a credential readable only by the current user
+func (p *commandProvider) writeCache(credential *cachedCredential) error {
+	if err := os.MkdirAll(p.cacheDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create credential cache: %w", err)
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credential: %w", err)
+	}
+	if err := os.WriteFile(p.cachePath(), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write credential cache: %w", err)
+	}
+	return nil
+}
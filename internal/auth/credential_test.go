@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+func TestParseCredential(t *testing.T) {
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		output      string
+		wantToken   string
+		wantExpires time.Time
+		wantErr     string
+	}{
+		{name: "plain", output: "abc123\n", wantToken: "abc123"},
+		{name: "plain with scheme", output: "Basic YWxpY2U=", wantToken: "Basic YWxpY2U="},
+		{
+			name:        "json",
+			output:      `{"token": "abc", "expires_at": "2030-01-02T03:04:05Z"}`,
+			wantToken:   "abc",
+			wantExpires: expires,
+		},
+		{name: "json without expiry", output: `{"token": "abc"}`, wantToken: "abc"},
+		{
+			name:        "exec credential",
+			output:      `{"kind": "ExecCredential", "status": {"token": "k8s", "expirationTimestamp": "2030-01-02T03:04:05Z"}}`,
+			wantToken:   "k8s",
+			wantExpires: expires,
+		},
+		{name: "empty", output: "  \n", wantErr: "printed nothing"},
+		{name: "several lines", output: "a\nb", wantErr: "more than one line"},
+		{name: "json without token", output: `{"expires_at": "2030-01-02T03:04:05Z"}`, wantErr: "no token"},
+		{name: "invalid expiry", output: `{"token": "abc", "expires_at": "tomorrow"}`, wantErr: "invalid credential expiry"},
+		{name: "invalid json", output: `{"token":`, wantErr: "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credential, err := parseCredential([]byte(tt.output))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseCredential() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCredential() error = %v", err)
+			}
+			if credential.Token != tt.wantToken || !credential.ExpiresAt.Equal(tt.wantExpires) {
+				t.Errorf("parseCredential() = %+v, want token %q expiring %v", credential, tt.wantToken, tt.wantExpires)
+			}
+		})
+	}
+}
+
+// countingCommand returns a credential helper printing output and the file
+// counting how often it ran
+func countingCommand(t *testing.T, output string) ([]string, string) {
+	t.Helper()
+	counter := filepath.Join(t.TempDir(), "count")
+	script := "echo run >> " + counter + "; printf '%s' '" + output + "'"
+	return []string{"sh", "-c", script}, counter
+}
+
+// runs returns how often a counting command ran
+func runs(t *testing.T, counter string) int {
+	t.Helper()
+	data, err := os.ReadFile(counter)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "run")
+}
+
+// commandProviderFor creates a command provider for inspection
+func commandProviderFor(t *testing.T, helper *types.CredentialHelper) *commandProvider {
+	t.Helper()
+	p, err := NewCommandProvider(helper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*commandProvider)
+}
+
+func TestCommandProvider(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		header string
+		want   string
+	}{
+		{name: "bearer", output: "abc", header: "Authorization", want: "Bearer abc"},
+		{name: "scheme kept", output: "Basic YWxpY2U=", header: "Authorization", want: "Basic YWxpY2U="},
+		{name: "custom header", output: `{"token": "key"}`, header: "X-API-Key", want: "key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, counter := countingCommand(t, tt.output)
+			provider, err := NewCommandProvider(&types.CredentialHelper{Command: command, Header: tt.header})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 3; i++ {
+				headers := make(map[string]string)
+				if err := provider.Apply(context.Background(), headers); err != nil {
+					t.Fatalf("Apply() error = %v", err)
+				}
+				if headers[tt.header] != tt.want {
+					t.Errorf("%s = %q, want %q", tt.header, headers[tt.header], tt.want)
+				}
+			}
+			if got := runs(t, counter); got != 1 {
+				t.Errorf("command ran %d times, want 1", got)
+			}
+		})
+	}
+}
+
+func TestCommandProvider_Expiry(t *testing.T) {
+	command, counter := countingCommand(t, `{"token": "abc", "expires_at": "2030-01-01T00:00:00Z"}`)
+	p := commandProviderFor(t, &types.CredentialHelper{Command: command, Header: "Authorization"})
+
+	now := time.Date(2029, 12, 31, 23, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	headers := make(map[string]string)
+	if err := p.Apply(context.Background(), headers); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Apply(context.Background(), headers); err != nil {
+		t.Fatal(err)
+	}
+	if got := runs(t, counter); got != 1 {
+		t.Fatalf("command ran %d times before expiry, want 1", got)
+	}
+
+	// Within the expiry margin the command runs again
+	now = time.Date(2029, 12, 31, 23, 59, 45, 0, time.UTC)
+	if err := p.Apply(context.Background(), headers); err != nil {
+		t.Fatal(err)
+	}
+	if got := runs(t, counter); got != 2 {
+		t.Errorf("command ran %d times after expiry, want 2", got)
+	}
+}
+
+func TestCommandProvider_DiskCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	expires := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	command, counter := countingCommand(t, `{"token": "cached", "expires_at": "`+expires+`"}`)
+	helper := &types.CredentialHelper{Command: command, Header: "Authorization", Cache: true}
+
+	// Separate providers stand in for separate runs
+	for i := 0; i < 2; i++ {
+		provider, err := NewCommandProvider(helper)
+		if err != nil {
+			t.Fatal(err)
+		}
+		headers := make(map[string]string)
+		if err := provider.Apply(context.Background(), headers); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if headers["Authorization"] != "Bearer cached" {
+			t.Errorf("Authorization = %q, want %q", headers["Authorization"], "Bearer cached")
+		}
+	}
+	if got := runs(t, counter); got != 1 {
+		t.Errorf("command ran %d times, want 1", got)
+	}
+
+	path := commandProviderFor(t, helper).cachePath()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("cache file missing: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("cache file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestCommandProvider_Failure(t *testing.T) {
+	helper := &types.CredentialHelper{
+		Command: []string{"sh", "-c", "echo 'not logged in' >&2; exit 3"},
+		Header:  "Authorization",
+	}
+	err := commandProviderFor(t, helper).Apply(context.Background(), make(map[string]string))
+	if err == nil || !strings.Contains(err.Error(), "not logged in") {
+		t.Errorf("Apply() error = %v, want the command's stderr", err)
+	}
+}
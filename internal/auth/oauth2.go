@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// tokenExpiryMargin is subtracted from a token's lifetime so that it is not
+// used right as it expires
+const tokenExpiryMargin = 30 * time.Second
+
+// oauth2Provider fetches access tokens with the OAuth2 client credentials
+// grant and caches them until they expire
+type oauth2Provider struct {
+	client       *http.Client
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	now          func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time // zero if the token does not expire
+}
+
+// newOAuth2Provider creates a provider for an oauth2_client_credentials block
+func newOAuth2Provider(config *types.AuthConfig, client *http.Client) *oauth2Provider {
+	return &oauth2Provider{
+		client:       client,
+		tokenURL:     config.TokenURL,
+		clientID:     config.ClientID,
+		clientSecret: config.ClientSecret,
+		scopes:       config.Scopes,
+		now:          time.Now,
+	}
+}
+
+// Apply sets a bearer Authorization header with a cached or new token
+func (p *oauth2Provider) Apply(ctx context.Context, headers map[string]string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token == "" || (!p.expires.IsZero() && !p.now().Before(p.expires)) {
+		if err := p.fetchToken(ctx); err != nil {
+			return err
+		}
+	}
+
+	headers["Authorization"] = "Bearer " + p.token
+	return nil
+}
+
+// fetchToken requests a new access token from the token endpoint. Client
+// credentials are sent with HTTP basic authentication (RFC 6749 2.3.1).
+func (p *oauth2Provider) fetchToken(ctx context.Context) error {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(p.scopes) > 0 {
+		form.Set("scope", strings.Join(p.scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("token request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token request failed: %s - %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return fmt.Errorf("token response has no access_token")
+	}
+
+	p.token = token.AccessToken
+	p.expires = time.Time{}
+	if token.ExpiresIn > 0 {
+		lifetime := time.Duration(token.ExpiresIn) * time.Second
+		p.expires = p.now().Add(lifetime - expiryMargin(lifetime))
+	}
+	return nil
+}
+
+// expiryMargin returns tokenExpiryMargin, or half the lifetime of tokens
+// that expire sooner, so that short-lived tokens are still reused
+func expiryMargin(lifetime time.Duration) time.Duration {
+	return min(tokenExpiryMargin, lifetime/2)
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// Signer computes HMAC signatures of request bodies for a signing block
+type Signer struct {
+	config *types.SigningConfig
+	now    func() time.Time
+	nonce  func() (string, error)
+}
+
+// NewSigner creates a signer for a signing block
+func NewSigner(config *types.SigningConfig) *Signer {
+	return &Signer{config: config, now: time.Now, nonce: randomNonce}
+}
+
+// Sign sets the timestamp, nonce and signature headers for body, which must
+// be the exact bytes sent. path is the URL path of the request.
+func (s *Signer) Sign(path string, body []byte, headers map[string]string) error {
+	if s.config.Algorithm != types.SigningHMACSHA256 {
+		return fmt.Errorf("unsupported signing algorithm '%s'", s.config.Algorithm)
+	}
+
+	secret, err := s.secret()
+	if err != nil {
+		return err
+	}
+
+	nonce, err := s.nonce()
+	if err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+
+	canonical := CanonicalString(s.config.Template, timestamp, nonce, path, body)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(canonical)
+	sum := mac.Sum(nil)
+
+	signature := hex.EncodeToString(sum)
+	if s.config.Encoding == "base64" {
+		signature = base64.StdEncoding.EncodeToString(sum)
+	}
+
+	headers[s.config.TimestampHeader] = timestamp
+	headers[s.config.NonceHeader] = nonce
+	headers[s.config.SignatureHeader] = signature
+	return nil
+}
+
+// CanonicalString expands the placeholders of a signing template. The body
+// is inserted byte for byte.
+func CanonicalString(template, timestamp, nonce, path string, body []byte) []byte {
+	replacer := strings.NewReplacer(
+		"{timestamp}", timestamp,
+		"{nonce}", nonce,
+		"{path}", path,
+		"{body}", string(body),
+	)
+	return []byte(replacer.Replace(template))
+}
+
+// secret reads the signing secret. Variables and files are read for every
+// request, so a rotated secret is picked up.
+func (s *Signer) secret() ([]byte, error) {
+	switch {
+	case s.config.SecretEnv != "":
+		value, ok := os.LookupEnv(s.config.SecretEnv)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("signing secret environment variable %s is not set", s.config.SecretEnv)
+		}
+		return []byte(value), nil
+	case s.config.SecretFile != "":
+		data, err := os.ReadFile(s.config.SecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing secret file: %w", err)
+		}
+		secret := strings.TrimSpace(string(data))
+		if secret == "" {
+			return nil, fmt.Errorf("signing secret file %s is empty", s.config.SecretFile)
+		}
+		return []byte(secret), nil
+	default:
+		return []byte(s.config.Secret), nil
+	}
+}
+
+// randomNonce returns 16 random bytes, hex encoded
+func randomNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
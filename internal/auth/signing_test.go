@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/constants"
+	"jsonrpc/pkg/types"
+)
+
+// newTestSigner creates a signer with a fixed clock and nonce
+func newTestSigner(config *types.SigningConfig) *Signer {
+	signer := NewSigner(config)
+	signer.now = func() time.Time { return time.Unix(1700000000, 0) }
+	signer.nonce = func() (string, error) { return "abc", nil }
+	return signer
+}
+
+// signingConfig returns a signing block with the parser's defaults
+func signingConfig() *types.SigningConfig {
+	return &types.SigningConfig{
+		Algorithm:       types.SigningHMACSHA256,
+		Secret:          "s3cret",
+		SignatureHeader: constants.DefaultSignatureHeader,
+		TimestampHeader: constants.DefaultTimestampHeader,
+		NonceHeader:     constants.DefaultNonceHeader,
+		Template:        constants.DefaultSigningTemplate,
+		Encoding:        "hex",
+	}
+}
+
+func TestSigner_Sign(t *testing.T) {
+	t.Setenv("RPC_SIGNING_SECRET", "s3cret")
+
+	tests := []struct {
+		name    string
+		modify  func(c *types.SigningConfig)
+		header  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			header: "X-Signature",
+			want:   "84cacaabda1b23ae51938dd06036d2789a3d683a065c2a033ecd5b806d6cfd1b",
+		},
+		{
+			name:   "base64",
+			modify: func(c *types.SigningConfig) { c.Encoding = "base64" },
+			header: "X-Signature",
+			want:   "hMrKq9obI65Rk43QYDbSeJo9aDoGXCoDPs1bgG1s/Rs=",
+		},
+		{
+			name:   "secret from environment",
+			modify: func(c *types.SigningConfig) { c.Secret, c.SecretEnv = "", "RPC_SIGNING_SECRET" },
+			header: "X-Signature",
+			want:   "84cacaabda1b23ae51938dd06036d2789a3d683a065c2a033ecd5b806d6cfd1b",
+		},
+		{
+			name: "custom template and header from file",
+			modify: func(c *types.SigningConfig) {
+				c.Secret, c.SecretFile = "", writeFile(t, "secret", "file-secret\n")
+				c.Template = "{timestamp}.{nonce}.{path}.{body}"
+				c.SignatureHeader = "X-Gateway-Signature"
+			},
+			header: "X-Gateway-Signature",
+			want:   "3ecc8f059d861f72a43655c86521577063f96a5e2ab7adbba8f613820d0814e6",
+		},
+		{
+			name:    "missing environment variable",
+			modify:  func(c *types.SigningConfig) { c.Secret, c.SecretEnv = "", "RPC_UNSET_SECRET" },
+			wantErr: "RPC_UNSET_SECRET is not set",
+		},
+		{
+			name:    "unsupported algorithm",
+			modify:  func(c *types.SigningConfig) { c.Algorithm = "hmac-md5" },
+			wantErr: "unsupported signing algorithm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := signingConfig()
+			if tt.modify != nil {
+				tt.modify(config)
+			}
+
+			headers := make(map[string]string)
+			err := newTestSigner(config).Sign("/rpc", []byte(`{"id":1}`), headers)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Sign() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+
+			if headers[tt.header] != tt.want {
+				t.Errorf("%s = %q, want %q", tt.header, headers[tt.header], tt.want)
+			}
+			if headers["X-Timestamp"] != "1700000000" || headers["X-Nonce"] != "abc" {
+				t.Errorf("timestamp and nonce headers = %q", headers)
+			}
+		})
+	}
+}
+
+func TestRandomNonce(t *testing.T) {
+	a, err := randomNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := randomNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 32 || a == b {
+		t.Errorf("randomNonce() = %q, %q, want distinct 32 character nonces", a, b)
+	}
+}
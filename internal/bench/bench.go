@@ -0,0 +1,387 @@
+// Package bench sends requests repeatedly under load and summarizes their
+// throughput, failures and latency.
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"jsonrpc/internal/executor"
+	"jsonrpc/internal/ratelimit"
+	"jsonrpc/internal/transport"
+	"jsonrpc/pkg/types"
+)
+
+// Options controls a bench run. At least one of Duration and Requests must
+// be set; the run stops at whichever is reached first.
+type Options struct {
+	Concurrency int             // requests in flight at most
+	Duration    time.Duration   // stop sending after this long; zero for no limit
+	Requests    int             // stop after sending this many; zero for no limit
+	Rate        *ratelimit.Rate // open-loop arrival rate; nil for closed-loop
+}
+
+// job is one request to send. Open-loop jobs carry the time they were due,
+// so that time spent waiting for a free worker counts towards their latency.
+type job struct {
+	req       *types.Request
+	seq       int
+	scheduled time.Time
+}
+
+// Run sends requests in turn on opts.Concurrency workers and reports the
+// outcomes. Without a rate, each worker sends its next request as soon as
+// the previous one finished (closed-loop); with a rate, requests are due at
+// fixed intervals regardless of how long earlier ones take (open-loop).
+// Requests in flight when the duration ends are waited for. Cancelling ctx
+// stops the run early; requests it cuts short are not counted.
+func Run(
+	ctx context.Context,
+	exec *executor.Executor,
+	hclFile *types.HCLFile,
+	requests []*types.Request,
+	overrides *types.CLIOverrides,
+	opts Options,
+) (*types.BenchReport, error) {
+	if err := validate(requests, opts); err != nil {
+		return nil, err
+	}
+
+	// Connections are shared by the requests of one run
+	defer func() {
+		_ = exec.Close()
+	}()
+
+	rec := newRecorder(requests)
+	jobs := make(chan job)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if j.scheduled.IsZero() {
+					j.scheduled = time.Now()
+				}
+				result, err := exec.Execute(ctx, hclFile, j.req, overrides, j.seq)
+				if err != nil {
+					result = &types.ExecutionResult{Request: j.req, Error: err}
+				}
+				rec.record(result, time.Since(j.scheduled))
+			}
+		}()
+	}
+
+	dispatch(ctx, jobs, requests, opts, start)
+	wg.Wait()
+
+	report := &types.BenchReport{
+		BenchStats:  rec.stats(),
+		Elapsed:     time.Since(start),
+		Concurrency: opts.Concurrency,
+		Interrupted: ctx.Err() != nil,
+	}
+	if opts.Rate != nil {
+		report.Rate = opts.Rate.String()
+	}
+	if len(requests) > 1 {
+		report.PerRequest = rec.perRequest()
+	}
+	return report, nil
+}
+
+// validate checks the options and that every request can be sent on its own
+func validate(requests []*types.Request, opts Options) error {
+	if len(requests) == 0 {
+		return errors.New("no requests to benchmark")
+	}
+	if opts.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", opts.Concurrency)
+	}
+	if opts.Duration <= 0 && opts.Requests <= 0 {
+		return errors.New("a duration or a number of requests is required")
+	}
+
+	for _, req := range requests {
+		if req.ParamsExpr != nil || len(req.DependsOn) > 0 {
+			return fmt.Errorf("request '%s' uses the results of other requests and cannot be benchmarked", req.Name)
+		}
+	}
+	return nil
+}
+
+// dispatch hands out jobs, cycling through requests, until the duration
+// has passed, the number of requests was sent or ctx is done. It closes
+// jobs when it returns.
+func dispatch(ctx context.Context, jobs chan<- job, requests []*types.Request, opts Options, start time.Time) {
+	defer close(jobs)
+
+	var stop <-chan time.Time
+	if opts.Duration > 0 {
+		timer := time.NewTimer(opts.Duration)
+		defer timer.Stop()
+		stop = timer.C
+	}
+	deadline := start.Add(opts.Duration)
+
+	var interval time.Duration
+	if opts.Rate != nil {
+		interval = opts.Rate.Per / time.Duration(opts.Rate.Requests)
+	}
+
+	for seq := 1; opts.Requests <= 0 || seq <= opts.Requests; seq++ {
+		j := job{req: requests[(seq-1)%len(requests)], seq: seq}
+
+		if interval > 0 {
+			j.scheduled = start.Add(time.Duration(seq-1) * interval)
+			if opts.Duration > 0 && !j.scheduled.Before(deadline) {
+				return
+			}
+
+			due := time.NewTimer(time.Until(j.scheduled))
+			select {
+			case <-due.C:
+			case <-stop:
+				due.Stop()
+				return
+			case <-ctx.Done():
+				due.Stop()
+				return
+			}
+		}
+
+		select {
+		case jobs <- j:
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// errorKey identifies a kind of failure
+type errorKey struct {
+	kind string
+	code int
+}
+
+// collector holds the outcomes of one request
+type collector struct {
+	name      string
+	total     int
+	latencies []time.Duration
+	errors    map[errorKey]*types.BenchError
+}
+
+// recorder collects the outcomes of a run. It is safe for concurrent use.
+type recorder struct {
+	mu         sync.Mutex
+	collectors []*collector
+	byName     map[string]*collector
+}
+
+// newRecorder creates a recorder for the requests of a run
+func newRecorder(requests []*types.Request) *recorder {
+	r := &recorder{byName: make(map[string]*collector, len(requests))}
+	for _, req := range requests {
+		if _, exists := r.byName[req.Name]; exists {
+			continue
+		}
+		c := &collector{name: req.Name, errors: make(map[errorKey]*types.BenchError)}
+		r.collectors = append(r.collectors, c)
+		r.byName[req.Name] = c
+	}
+	return r
+}
+
+// record adds the outcome of one request. Requests cut short by the end of
+// the run are left out.
+func (r *recorder) record(result *types.ExecutionResult, latency time.Duration) {
+	if errors.Is(result.Error, types.ErrCancelled) || errors.Is(result.Error, types.ErrNotRun) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.byName[result.Request.Name]
+	c.total++
+
+	failure, failed := classify(result)
+	if !failed {
+		c.latencies = append(c.latencies, latency)
+		return
+	}
+
+	key := errorKey{kind: failure.Kind, code: failure.Code}
+	if existing, exists := c.errors[key]; exists {
+		existing.Count++
+		return
+	}
+	failure.Count = 1
+	c.errors[key] = &failure
+}
+
+// stats summarizes every request of the run
+func (r *recorder) stats() types.BenchStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return summarize("", r.collectors...)
+}
+
+// perRequest summarizes each request of the run on its own
+func (r *recorder) perRequest() []types.BenchStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]types.BenchStats, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		stats = append(stats, summarize(c.name, c))
+	}
+	return stats
+}
+
+// classify returns the kind of failure of a result, or false if it succeeded
+func classify(result *types.ExecutionResult) (types.BenchError, bool) {
+	if result.Error == nil {
+		if result.Response != nil && result.Response.IsError() {
+			return types.BenchError{
+				Kind:    "rpc",
+				Code:    result.Response.Error.Code,
+				Message: result.Response.Error.Message,
+			}, true
+		}
+		return types.BenchError{}, false
+	}
+
+	var httpErr *transport.HTTPError
+	switch {
+	case errors.As(result.Error, &httpErr):
+		return types.BenchError{
+			Kind:    "http",
+			Code:    httpErr.StatusCode,
+			Message: http.StatusText(httpErr.StatusCode),
+		}, true
+	case errors.Is(result.Error, context.DeadlineExceeded):
+		return types.BenchError{Kind: "timeout", Message: result.Error.Error()}, true
+	default:
+		return types.BenchError{Kind: "transport", Message: result.Error.Error()}, true
+	}
+}
+
+// summarize merges the outcomes of collectors into one set of stats
+func summarize(name string, collectors ...*collector) types.BenchStats {
+	stats := types.BenchStats{Name: name}
+	var latencies []time.Duration
+	errorsByKey := make(map[errorKey]types.BenchError)
+
+	for _, c := range collectors {
+		stats.Total += c.total
+		latencies = append(latencies, c.latencies...)
+		for key, failure := range c.errors {
+			if merged, exists := errorsByKey[key]; exists {
+				merged.Count += failure.Count
+				errorsByKey[key] = merged
+				continue
+			}
+			errorsByKey[key] = *failure
+		}
+	}
+	stats.Succeeded = len(latencies)
+
+	for _, failure := range errorsByKey {
+		stats.Errors = append(stats.Errors, failure)
+	}
+	sort.Slice(stats.Errors, func(i, j int) bool {
+		a, b := stats.Errors[i], stats.Errors[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Code < b.Code
+	})
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	stats.Latency = latencySummary(latencies)
+	stats.Histogram = histogram(latencies)
+	return stats
+}
+
+// latencySummary returns the percentiles of sorted latencies
+func latencySummary(sorted []time.Duration) types.LatencySummary {
+	if len(sorted) == 0 {
+		return types.LatencySummary{}
+	}
+
+	var sum time.Duration
+	for _, latency := range sorted {
+		sum += latency
+	}
+
+	return types.LatencySummary{
+		Min:  sorted[0],
+		Mean: sum / time.Duration(len(sorted)),
+		P50:  percentile(sorted, 50),
+		P90:  percentile(sorted, 90),
+		P99:  percentile(sorted, 99),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// histogram counts sorted latencies in buckets bounded by 1, 2 and 5 times
+// powers of ten, from the bucket of the fastest latency to the slowest
+func histogram(sorted []time.Duration) []types.HistogramBucket {
+	if len(sorted) == 0 {
+		return nil
+	}
+
+	var buckets []types.HistogramBucket
+	i := 0
+	for _, bound := range bucketBounds(sorted[len(sorted)-1]) {
+		count := 0
+		for i < len(sorted) && sorted[i] <= bound {
+			count++
+			i++
+		}
+		if count == 0 && len(buckets) == 0 {
+			continue
+		}
+		buckets = append(buckets, types.HistogramBucket{UpperBound: bound, Count: count})
+	}
+	return buckets
+}
+
+// bucketBounds returns the histogram bounds from 100µs up to the first one
+// at or above slowest
+func bucketBounds(slowest time.Duration) []time.Duration {
+	var bounds []time.Duration
+	for decade := 100 * time.Microsecond; ; decade *= 10 {
+		for _, multiple := range []time.Duration{1, 2, 5} {
+			bound := multiple * decade
+			bounds = append(bounds, bound)
+			if bound >= slowest {
+				return bounds
+			}
+		}
+	}
+}
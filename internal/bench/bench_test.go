@@ -0,0 +1,244 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"jsonrpc/internal/executor"
+	"jsonrpc/internal/parser"
+	"jsonrpc/internal/ratelimit"
+	"jsonrpc/pkg/types"
+)
+
+// newBenchServer starts a JSON-RPC server that fails "broken" with a
+// JSON-RPC error, "unavailable" with HTTP 503, waits for the client to give
+// up on "hang" and answers everything else with "0x10"
+func newBenchServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			ID     int    `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		response := map[string]any{"jsonrpc": "2.0", "result": "0x10", "id": req.ID}
+		switch req.Method {
+		case "broken":
+			delete(response, "result")
+			response["error"] = map[string]any{"code": -32005, "message": "limit exceeded"}
+		case "unavailable":
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+			return
+		case "hang":
+			<-r.Context().Done()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// parseRequests parses requests calling each method against server
+func parseRequests(t *testing.T, server *httptest.Server, methods ...string) (*types.HCLFile, []*types.Request) {
+	t.Helper()
+
+	src := "config {\n  url = \"" + server.URL + "\"\n}\n"
+	for _, method := range methods {
+		src += "request \"" + method + "\" {\n  method = \"" + method + "\"\n}\n"
+	}
+
+	path := filepath.Join(t.TempDir(), "requests.hcl")
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatalf("failed to write HCL file: %v", err)
+	}
+
+	hclFile, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	return hclFile, hclFile.Requests
+}
+
+func TestRun_Requests(t *testing.T) {
+	server := newBenchServer(t)
+	hclFile, requests := parseRequests(t, server, "ok", "broken", "unavailable")
+
+	report, err := Run(context.Background(), executor.New(), hclFile, requests, types.NewCLIOverrides(),
+		Options{Concurrency: 4, Requests: 21})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.Total != 21 || report.Succeeded != 7 || report.Failed() != 14 {
+		t.Errorf("requests = %d total, %d successful, %d failed; want 21, 7, 14",
+			report.Total, report.Succeeded, report.Failed())
+	}
+	if report.Interrupted || report.Rate != "" || report.Concurrency != 4 {
+		t.Errorf("report = %+v", report)
+	}
+
+	want := []types.BenchError{
+		{Kind: "http", Code: 503, Count: 7, Message: "Service Unavailable"},
+		{Kind: "rpc", Code: -32005, Count: 7, Message: "limit exceeded"},
+	}
+	if !reflect.DeepEqual(report.Errors, want) {
+		t.Errorf("errors = %+v, want %+v", report.Errors, want)
+	}
+
+	if len(report.PerRequest) != 3 {
+		t.Fatalf("per request stats = %d, want 3", len(report.PerRequest))
+	}
+	for _, stats := range report.PerRequest {
+		if stats.Total != 7 {
+			t.Errorf("%s total = %d, want 7", stats.Name, stats.Total)
+		}
+	}
+	if ok := report.PerRequest[0]; ok.Name != "ok" || ok.Succeeded != 7 || ok.Latency.Max <= 0 {
+		t.Errorf("ok stats = %+v", ok)
+	}
+}
+
+func TestRun_Duration(t *testing.T) {
+	server := newBenchServer(t)
+	hclFile, requests := parseRequests(t, server, "ok")
+
+	report, err := Run(context.Background(), executor.New(), hclFile, requests, types.NewCLIOverrides(),
+		Options{Concurrency: 2, Duration: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.Total == 0 || report.Failed() != 0 {
+		t.Errorf("requests = %d total, %d failed; want some and no failures", report.Total, report.Failed())
+	}
+	if report.Elapsed < 100*time.Millisecond || report.Elapsed > 2*time.Second {
+		t.Errorf("elapsed = %s, want about 100ms", report.Elapsed)
+	}
+	if report.PerRequest != nil {
+		t.Errorf("per request stats = %+v, want none for a single request", report.PerRequest)
+	}
+}
+
+func TestRun_OpenLoop(t *testing.T) {
+	server := newBenchServer(t)
+	hclFile, requests := parseRequests(t, server, "ok")
+
+	rate := ratelimit.Rate{Requests: 100, Per: time.Second}
+	report, err := Run(context.Background(), executor.New(), hclFile, requests, types.NewCLIOverrides(),
+		Options{Concurrency: 2, Duration: 200 * time.Millisecond, Rate: &rate})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Requests are due every 10ms, so 20 start within 200ms
+	if report.Total < 15 || report.Total > 20 {
+		t.Errorf("total = %d, want about 20", report.Total)
+	}
+	if report.Rate != "100/1s" {
+		t.Errorf("rate = %q, want \"100/1s\"", report.Rate)
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	server := newBenchServer(t)
+	hclFile, requests := parseRequests(t, server, "hang")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	report, err := Run(ctx, executor.New(), hclFile, requests, types.NewCLIOverrides(),
+		Options{Concurrency: 2, Duration: time.Minute})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// The requests cut short are not counted
+	if !report.Interrupted || report.Total != 0 {
+		t.Errorf("report = %+v, want interrupted with no requests", report)
+	}
+}
+
+func TestRun_Invalid(t *testing.T) {
+	dependent := &types.Request{Name: "get_block", DependsOn: []string{"get_block_number"}}
+	independent := &types.Request{Name: "get_block_number"}
+
+	tests := []struct {
+		name     string
+		requests []*types.Request
+		opts     Options
+	}{
+		{name: "no requests", opts: Options{Concurrency: 1, Requests: 1}},
+		{name: "no concurrency", requests: []*types.Request{independent}, opts: Options{Requests: 1}},
+		{name: "no limit", requests: []*types.Request{independent}, opts: Options{Concurrency: 1}},
+		{name: "dependency", requests: []*types.Request{dependent}, opts: Options{Concurrency: 1, Requests: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Run(context.Background(), executor.New(), &types.HCLFile{}, tt.requests,
+				types.NewCLIOverrides(), tt.opts)
+			if err == nil {
+				t.Error("Run() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestLatencySummary(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	want := types.LatencySummary{
+		Min:  time.Millisecond,
+		Mean: 50500 * time.Microsecond,
+		P50:  50 * time.Millisecond,
+		P90:  90 * time.Millisecond,
+		P99:  99 * time.Millisecond,
+		Max:  100 * time.Millisecond,
+	}
+	if got := latencySummary(sorted); got != want {
+		t.Errorf("latencySummary() = %+v, want %+v", got, want)
+	}
+
+	if got := latencySummary(nil); got != (types.LatencySummary{}) {
+		t.Errorf("latencySummary(nil) = %+v, want zero", got)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	sorted := []time.Duration{
+		3 * time.Millisecond,
+		4 * time.Millisecond,
+		5 * time.Millisecond,
+		30 * time.Millisecond,
+		150 * time.Millisecond,
+	}
+
+	want := []types.HistogramBucket{
+		{UpperBound: 5 * time.Millisecond, Count: 3},
+		{UpperBound: 10 * time.Millisecond, Count: 0},
+		{UpperBound: 20 * time.Millisecond, Count: 0},
+		{UpperBound: 50 * time.Millisecond, Count: 1},
+		{UpperBound: 100 * time.Millisecond, Count: 0},
+		{UpperBound: 200 * time.Millisecond, Count: 1},
+	}
+	if got := histogram(sorted); !reflect.DeepEqual(got, want) {
+		t.Errorf("histogram() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+// Package bytesize parses and formats human-readable byte sizes.
+package bytesize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Units in powers of 1024, largest first
+var units = []struct {
+	suffix string
+	size   int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// Parse parses a size such as "512", "64KB", "100MB" or "1GB". Units are
+// powers of 1024 and case-insensitive; KiB, MiB and GiB are accepted too.
+func Parse(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	value = strings.Replace(value, "IB", "B", 1)
+
+	multiplier := int64(1)
+	for _, unit := range units {
+		if number, found := strings.CutSuffix(value, unit.suffix); found {
+			value, multiplier = strings.TrimSpace(number), unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid size '%s' (expected a positive number of bytes, KB, MB or GB)", s)
+	}
+	if n > (1<<63-1)/multiplier {
+		return 0, fmt.Errorf("invalid size '%s': too large", s)
+	}
+	return n * multiplier, nil
+}
+
+// Format returns n in the largest unit that divides it evenly, e.g. "100MB"
+func Format(n int64) string {
+	for _, unit := range units {
+		if n != 0 && n%unit.size == 0 {
+			return strconv.FormatInt(n/unit.size, 10) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10) + "B"
+}
@@ -0,0 +1,57 @@
+package bytesize
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "512", want: 512},
+		{value: "512B", want: 512},
+		{value: "64KB", want: 64 << 10},
+		{value: "100MB", want: 100 << 20},
+		{value: " 1 gb ", want: 1 << 30},
+		{value: "2MiB", want: 2 << 20},
+		{value: "0", wantErr: true},
+		{value: "-1MB", wantErr: true},
+		{value: "1.5MB", wantErr: true},
+		{value: "MB", wantErr: true},
+		{value: "10TB", wantErr: true},
+		{value: "9999999999GB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 100 << 20, want: "100MB"},
+		{n: 1 << 30, want: "1GB"},
+		{n: 1536, want: "1536B"},
+		{n: 1000, want: "1000B"},
+		{n: 0, want: "0B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Format(tt.n); got != tt.want {
+				t.Errorf("Format(%d) = %s, want %s", tt.n, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+// Package cassette records JSON-RPC calls to a file and replays them
+// without a network.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"jsonrpc/internal/mask"
+	"jsonrpc/pkg/types"
+)
+
+// version is the cassette file format version
+const version = 1
+
+// ErrNotRecorded is returned when replaying a call that matches no
+// recorded interaction
+var ErrNotRecorded = errors.New("no recorded response")
+
+// Match is how strictly replayed calls are matched to recorded ones
+type Match string
+
+const (
+	MatchMethod Match = "method" // same method
+	MatchParams Match = "params" // same method and params
+	MatchStrict Match = "strict" // same endpoint, method and params
+)
+
+// ParseMatch parses a --replay-match value
+func ParseMatch(s string) (Match, error) {
+	switch match := Match(strings.ToLower(strings.TrimSpace(s))); match {
+	case MatchMethod, MatchParams, MatchStrict:
+		return match, nil
+	default:
+		return "", fmt.Errorf("invalid match '%s' (expected method, params or strict)", s)
+	}
+}
+
+// Interaction is one recorded call: the JSON-RPC request as sent and the
+// response as received. Sensitive headers are masked.
+type Interaction struct {
+	Endpoint string            `json:"endpoint"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Request  json.RawMessage   `json:"request"`
+	Response json.RawMessage   `json:"response"`
+}
+
+// file is the on-disk format of a cassette
+type file struct {
+	Version      int            `json:"version"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// call is the part of a JSON-RPC request used for matching
+type call struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	ID     json.RawMessage `json:"id"`
+}
+
+// Cassette holds recorded interactions. A cassette created with New
+// records calls; one read with Load replays them. It is safe for
+// concurrent use.
+type Cassette struct {
+	mu           sync.Mutex
+	interactions []*Interaction
+	masker       *mask.SensitiveMasker
+
+	// Replay state: interactions by match key, and how many of each were used
+	match   Match
+	byKey   map[string][]*Interaction
+	replays map[string]int
+}
+
+// New creates an empty cassette to record calls in
+func New() *Cassette {
+	return &Cassette{interactions: []*Interaction{}, masker: mask.NewSensitiveMasker()}
+}
+
+// Load reads a recorded cassette to replay calls from, matched as given
+func Load(path string, match Match) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var contents file
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	if contents.Version != version {
+		return nil, fmt.Errorf("unsupported cassette version %d in %s (expected %d)", contents.Version, path, version)
+	}
+
+	c := &Cassette{
+		interactions: contents.Interactions,
+		match:        match,
+		byKey:        make(map[string][]*Interaction),
+		replays:      make(map[string]int),
+	}
+	for i, interaction := range contents.Interactions {
+		var recorded call
+		if err := json.Unmarshal(interaction.Request, &recorded); err != nil {
+			return nil, fmt.Errorf("invalid request in interaction %d of %s: %w", i+1, path, err)
+		}
+		key, err := c.key(interaction.Endpoint, recorded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request in interaction %d of %s: %w", i+1, path, err)
+		}
+		c.byKey[key] = append(c.byKey[key], interaction)
+	}
+	return c, nil
+}
+
+// Save writes the recorded interactions to path
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	contents := file{Version: version, Interactions: c.interactions}
+	data, err := json.MarshalIndent(contents, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Len returns the number of interactions in the cassette
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.interactions)
+}
+
+// Record adds the calls of a request or batch payload and the responses
+// received for them. Notifications and responses without a matching
+// request are not recorded.
+func (c *Cassette) Record(config *types.EffectiveConfig, payload, response []byte) error {
+	requests, err := splitPayload(payload)
+	if err != nil {
+		return fmt.Errorf("failed to record request: %w", err)
+	}
+	responses, err := splitPayload(response)
+	if err != nil {
+		return fmt.Errorf("failed to record response: %w", err)
+	}
+
+	responsesByID := make(map[string]json.RawMessage, len(responses))
+	for _, resp := range responses {
+		var decoded struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.Unmarshal(resp, &decoded); err == nil {
+			responsesByID[types.IDKey(decoded.ID)] = resp
+		}
+	}
+
+	headers := c.maskedHeaders(config)
+	endpoint := redactedEndpoint(config)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, req := range requests {
+		var sent call
+		if err := json.Unmarshal(req, &sent); err != nil || sent.ID == nil {
+			continue
+		}
+		resp, exists := responsesByID[types.IDKey(sent.ID)]
+		if !exists {
+			continue
+		}
+		c.interactions = append(c.interactions, &Interaction{
+			Endpoint: endpoint,
+			Headers:  headers,
+			Request:  req,
+			Response: resp,
+		})
+	}
+	return nil
+}
+
+// Replay answers a request or batch payload from the recorded
+// interactions, with the ids of the requests. Calls matching several
+// interactions get them in recorded order, then the last one again.
+func (c *Cassette) Replay(config *types.EffectiveConfig, payload []byte) ([]byte, error) {
+	requests, err := splitPayload(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay request: %w", err)
+	}
+	endpoint := redactedEndpoint(config)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	responses := make([]json.RawMessage, 0, len(requests))
+	for _, req := range requests {
+		var sent call
+		if err := json.Unmarshal(req, &sent); err != nil {
+			return nil, fmt.Errorf("failed to replay request: %w", err)
+		}
+		// Notifications get no response
+		if sent.ID == nil {
+			continue
+		}
+
+		key, err := c.key(endpoint, sent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay request: %w", err)
+		}
+		recorded := c.byKey[key]
+		if len(recorded) == 0 {
+			return nil, fmt.Errorf("%w for %s %s (matching %s)",
+				ErrNotRecorded, sent.Method, compactParams(sent.Params), c.match)
+		}
+
+		used := c.replays[key]
+		c.replays[key] = used + 1
+		interaction := recorded[min(used, len(recorded)-1)]
+
+		resp, err := withID(interaction.Response, sent.ID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recorded response for %s: %w", sent.Method, err)
+		}
+		responses = append(responses, resp)
+	}
+
+	if len(requests) == 1 && !isBatch(payload) {
+		if len(responses) == 0 {
+			return nil, nil
+		}
+		return responses[0], nil
+	}
+	return json.Marshal(responses)
+}
+
+// key returns the key a call is matched by
+func (c *Cassette) key(endpoint string, sent call) (string, error) {
+	if c.match == MatchMethod {
+		return sent.Method, nil
+	}
+
+	params, err := canonicalJSON(sent.Params)
+	if err != nil {
+		return "", err
+	}
+	if c.match == MatchStrict {
+		return endpoint + "\n" + sent.Method + "\n" + params, nil
+	}
+	return sent.Method + "\n" + params, nil
+}
+
+// maskedHeaders returns the headers sent with a request, including
+// credentials, with sensitive values masked
+func (c *Cassette) maskedHeaders(config *types.EffectiveConfig) map[string]string {
+	if len(config.Headers) == 0 && len(config.Credentials) == 0 {
+		return nil
+	}
+
+	headers := make(map[string]string, len(config.Headers)+len(config.Credentials))
+	for k, v := range config.Headers {
+		headers[k] = c.masker.MaskIfSensitive(k, v)
+	}
+	for k, v := range config.Credentials {
+		headers[k] = c.masker.MaskIfSensitive(k, v)
+	}
+	return headers
+}
+
+// redactedEndpoint returns the URL or command of a config, with any
+// password in the URL masked
+func redactedEndpoint(config *types.EffectiveConfig) string {
+	if len(config.Command) > 0 {
+		return config.Endpoint()
+	}
+	if endpoint, err := url.Parse(config.URL); err == nil {
+		return endpoint.Redacted()
+	}
+	return config.URL
+}
+
+// isBatch reports whether a payload is a JSON array
+func isBatch(payload []byte) bool {
+	trimmed := bytes.TrimSpace(payload)
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// splitPayload returns the objects of a batch payload, or the payload
+// itself if it is a single object
+func splitPayload(payload []byte) ([]json.RawMessage, error) {
+	if !isBatch(payload) {
+		if len(bytes.TrimSpace(payload)) == 0 {
+			return nil, nil
+		}
+		return []json.RawMessage{json.RawMessage(payload)}, nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(payload, &batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// withID returns a recorded response with its id replaced
+func withID(response json.RawMessage, id json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(response, &fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, errors.New("response is not an object")
+	}
+	fields["id"] = id
+	return json.Marshal(fields)
+}
+
+// canonicalJSON returns params as compact JSON with sorted object keys and
+// numbers kept as written; missing params are "null"
+func canonicalJSON(params json.RawMessage) (string, error) {
+	if len(bytes.TrimSpace(params)) == 0 {
+		return "null", nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("invalid params: %w", err)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// compactParams renders params for an error message
+func compactParams(params json.RawMessage) string {
+	canonical, err := canonicalJSON(params)
+	if err != nil {
+		return string(params)
+	}
+	if len(canonical) > 80 {
+		return canonical[:77] + "..."
+	}
+	return canonical
+}
@@ -0,0 +1,245 @@
+// Package diff compares the responses of requests executed with different
+// configs, value by value.
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"jsonrpc/pkg/types"
+)
+
+// Results pairs the results of the same requests run with two configs and
+// compares their responses. Results are matched by request name, in the
+// order of from. Failures are prefixed with the config names.
+func Results(
+	fromConfig, toConfig string,
+	from, to []*types.ExecutionResult,
+	ignore []Pattern,
+) []types.DiffResult {
+	toByName := make(map[string]*types.ExecutionResult, len(to))
+	for _, result := range to {
+		toByName[result.Request.Name] = result
+	}
+
+	diffs := make([]types.DiffResult, 0, len(from))
+	for _, fromResult := range from {
+		diffs = append(diffs, compareResults(fromConfig, toConfig, fromResult, toByName[fromResult.Request.Name], ignore))
+	}
+	return diffs
+}
+
+// compareResults compares the responses of one request
+func compareResults(
+	fromConfig, toConfig string,
+	from, to *types.ExecutionResult,
+	ignore []Pattern,
+) types.DiffResult {
+	diff := types.DiffResult{Request: from.Request.Name}
+
+	if to == nil {
+		diff.Status = types.DiffFailed
+		diff.Errors = []string{fmt.Sprintf("%s: request was not run", toConfig)}
+		return diff
+	}
+	for _, side := range []struct {
+		config string
+		result *types.ExecutionResult
+	}{{fromConfig, from}, {toConfig, to}} {
+		if side.result.Error != nil {
+			diff.Errors = append(diff.Errors, fmt.Sprintf("%s: %s", side.config, side.result.Error))
+		}
+	}
+	if len(diff.Errors) > 0 {
+		diff.Status = types.DiffFailed
+		return diff
+	}
+
+	fromDoc, err := document(from.Response)
+	if err != nil {
+		diff.Status = types.DiffFailed
+		diff.Errors = []string{fmt.Sprintf("%s: %s", fromConfig, err)}
+		return diff
+	}
+	toDoc, err := document(to.Response)
+	if err != nil {
+		diff.Status = types.DiffFailed
+		diff.Errors = []string{fmt.Sprintf("%s: %s", toConfig, err)}
+		return diff
+	}
+
+	diff.Changes = Compare(fromDoc, toDoc, ignore)
+	diff.Status = types.DiffSame
+	if len(diff.Changes) > 0 {
+		diff.Status = types.DiffDifferent
+	}
+	return diff
+}
+
+// document returns the part of a response to compare: {"result": ...} or
+// {"error": {...}}, decoded with numbers kept as written. Notifications
+// have no response and compare as null.
+func document(response *types.JSONRPCResponse) (any, error) {
+	if response == nil {
+		return nil, nil
+	}
+
+	if response.IsError() {
+		data, err := json.Marshal(response.Error)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode error: %w", err)
+		}
+		rpcErr, err := decode(data)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"error": rpcErr}, nil
+	}
+
+	result := []byte(response.Result)
+	if len(result) == 0 {
+		result = []byte("null")
+	}
+	value, err := decode(result)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"result": value}, nil
+}
+
+// decode decodes JSON keeping numbers as json.Number, so that large
+// integers are compared exactly
+func decode(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return value, nil
+}
+
+// Compare returns the values that differ between two decoded JSON
+// documents, leaving out paths matched by ignore. Objects are compared by
+// key and arrays by position.
+func Compare(from, to any, ignore []Pattern) []types.DiffChange {
+	c := comparer{ignore: ignore}
+	c.compare(nil, from, to)
+	return c.changes
+}
+
+// comparer walks two documents side by side and records their differences
+type comparer struct {
+	ignore  []Pattern
+	changes []types.DiffChange
+}
+
+// compare records the differences between from and to at path
+func (c *comparer) compare(path []segment, from, to any) {
+	if c.ignored(path) {
+		return
+	}
+
+	switch fromNode := from.(type) {
+	case map[string]any:
+		if toNode, ok := to.(map[string]any); ok {
+			c.compareObjects(path, fromNode, toNode)
+			return
+		}
+	case []any:
+		if toNode, ok := to.([]any); ok {
+			c.compareArrays(path, fromNode, toNode)
+			return
+		}
+	default:
+		if sameScalar(from, to) {
+			return
+		}
+	}
+
+	c.add(path, types.DiffChanged, from, to)
+}
+
+// compareObjects compares two objects key by key, in sorted key order
+func (c *comparer) compareObjects(path []segment, from, to map[string]any) {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, exists := from[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		child := append(path[:len(path):len(path)], segment{key: key})
+		fromValue, inFrom := from[key]
+		toValue, inTo := to[key]
+		switch {
+		case !inTo:
+			c.add(child, types.DiffRemoved, fromValue, nil)
+		case !inFrom:
+			c.add(child, types.DiffAdded, nil, toValue)
+		default:
+			c.compare(child, fromValue, toValue)
+		}
+	}
+}
+
+// compareArrays compares two arrays element by element
+func (c *comparer) compareArrays(path []segment, from, to []any) {
+	for i := 0; i < max(len(from), len(to)); i++ {
+		child := append(path[:len(path):len(path)], segment{index: i, isIndex: true})
+		switch {
+		case i >= len(to):
+			c.add(child, types.DiffRemoved, from[i], nil)
+		case i >= len(from):
+			c.add(child, types.DiffAdded, nil, to[i])
+		default:
+			c.compare(child, from[i], to[i])
+		}
+	}
+}
+
+// add records a change unless its path is ignored
+func (c *comparer) add(path []segment, kind types.DiffChangeKind, from, to any) {
+	if c.ignored(path) {
+		return
+	}
+	c.changes = append(c.changes, types.DiffChange{Path: formatPath(path), Kind: kind, From: from, To: to})
+}
+
+// ignored reports whether an ignore pattern matches path
+func (c *comparer) ignored(path []segment) bool {
+	for _, pattern := range c.ignore {
+		if pattern.matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameScalar reports whether two decoded JSON scalars are equal. Numbers
+// are compared as written, so 1 and 1.0 differ.
+func sameScalar(from, to any) bool {
+	switch fromValue := from.(type) {
+	case nil:
+		return to == nil
+	case json.Number:
+		toValue, ok := to.(json.Number)
+		return ok && fromValue == toValue
+	case string:
+		toValue, ok := to.(string)
+		return ok && fromValue == toValue
+	case bool:
+		toValue, ok := to.(bool)
+		return ok && fromValue == toValue
+	default:
+		return false
+	}
+}
@@ -0,0 +1,187 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"jsonrpc/pkg/types"
+)
+
+// mustDecode decodes a JSON document for a test
+func mustDecode(t *testing.T, data string) any {
+	t.Helper()
+	value, err := decode([]byte(data))
+	if err != nil {
+		t.Fatalf("decode(%s) error = %v", data, err)
+	}
+	return value
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		ignore []string
+		want   []types.DiffChange
+	}{
+		{name: "equal", from: `{"a":[1,{"b":true}],"c":null}`, to: `{"c":null,"a":[1,{"b":true}]}`},
+		{
+			name: "changed scalar",
+			from: `{"hash":"0xabc"}`,
+			to:   `{"hash":"0xdef"}`,
+			want: []types.DiffChange{{Path: "$.hash", Kind: types.DiffChanged, From: "0xabc", To: "0xdef"}},
+		},
+		{
+			name: "added and removed keys",
+			from: `{"a":1,"b":2}`,
+			to:   `{"b":2,"c":3}`,
+			want: []types.DiffChange{
+				{Path: "$.a", Kind: types.DiffRemoved, From: json.Number("1")},
+				{Path: "$.c", Kind: types.DiffAdded, To: json.Number("3")},
+			},
+		},
+		{
+			name: "array elements by position",
+			from: `["x","y","z"]`,
+			to:   `["x","q"]`,
+			want: []types.DiffChange{
+				{Path: "$[1]", Kind: types.DiffChanged, From: "y", To: "q"},
+				{Path: "$[2]", Kind: types.DiffRemoved, From: "z"},
+			},
+		},
+		{
+			name: "type change",
+			from: `{"v":"1"}`,
+			to:   `{"v":1}`,
+			want: []types.DiffChange{{Path: "$.v", Kind: types.DiffChanged, From: "1", To: json.Number("1")}},
+		},
+		{
+			name: "large numbers compared exactly",
+			from: `12345678901234567890`,
+			to:   `12345678901234567891`,
+			want: []types.DiffChange{{Path: "$", Kind: types.DiffChanged,
+				From: json.Number("12345678901234567890"), To: json.Number("12345678901234567891")}},
+		},
+		{
+			name: "key needing brackets",
+			from: `{"content-type":"a"}`,
+			to:   `{"content-type":"b"}`,
+			want: []types.DiffChange{{Path: "$['content-type']", Kind: types.DiffChanged, From: "a", To: "b"}},
+		},
+		{
+			name:   "ignored subtree",
+			from:   `{"block":{"timestamp":"0x1","hash":"0xa"},"n":1}`,
+			to:     `{"block":{"timestamp":"0x2","hash":"0xb"},"n":1}`,
+			ignore: []string{"$.block"},
+		},
+		{
+			name:   "ignored with wildcard",
+			from:   `{"txs":[{"hash":"0xa","index":1},{"hash":"0xb","index":2}]}`,
+			to:     `{"txs":[{"hash":"0xc","index":1},{"hash":"0xd","index":3}]}`,
+			ignore: []string{"$.txs[*].hash"},
+			want:   []types.DiffChange{{Path: "$.txs[1].index", Kind: types.DiffChanged, From: json.Number("2"), To: json.Number("3")}},
+		},
+		{
+			name:   "ignored added key",
+			from:   `{}`,
+			to:     `{"extra":true}`,
+			ignore: []string{"extra"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ignore []Pattern
+			for _, path := range tt.ignore {
+				pattern, err := ParsePattern(path)
+				if err != nil {
+					t.Fatalf("ParsePattern(%s) error = %v", path, err)
+				}
+				ignore = append(ignore, pattern)
+			}
+
+			got := Compare(mustDecode(t, tt.from), mustDecode(t, tt.to), ignore)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "$.result.timestamp", want: "$.result.timestamp"},
+		{path: "result.transactions[*].hash", want: "$.result.transactions[*].hash"},
+		{path: "$.result.*", want: "$.result[*]"},
+		{path: "$['error']['data']", want: "$.error.data"},
+		{path: "$.logs[0]", want: "$.logs[0]"},
+		{path: "$.logs[-1]", wantErr: true},
+		{path: "$.logs[", wantErr: true},
+		{path: "$..hash", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			pattern, err := ParsePattern(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePattern() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && formatPath(pattern.segments) != tt.want {
+				t.Errorf("ParsePattern() = %s, want %s", formatPath(pattern.segments), tt.want)
+			}
+		})
+	}
+}
+
+func TestResults(t *testing.T) {
+	result := func(name, response string) *types.ExecutionResult {
+		var resp types.JSONRPCResponse
+		if err := json.Unmarshal([]byte(response), &resp); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		return &types.ExecutionResult{Request: &types.Request{Name: name}, Response: &resp}
+	}
+	failed := func(name string, err error) *types.ExecutionResult {
+		return &types.ExecutionResult{Request: &types.Request{Name: name}, Error: err}
+	}
+
+	production := []*types.ExecutionResult{
+		result("same", `{"result":"0x10"}`),
+		result("different", `{"result":{"number":"0x10"}}`),
+		result("error_vs_result", `{"error":{"code":-32601,"message":"method not found"}}`),
+		result("unreachable", `{"result":"0x1"}`),
+		result("missing", `{"result":"0x1"}`),
+	}
+	staging := []*types.ExecutionResult{
+		result("different", `{"result":{"number":"0x11"}}`),
+		result("same", `{"result":"0x10"}`),
+		result("error_vs_result", `{"result":"0x1"}`),
+		failed("unreachable", errors.New("connection refused")),
+	}
+
+	got := Results("production", "staging", production, staging, nil)
+
+	want := []types.DiffResult{
+		{Request: "same", Status: types.DiffSame},
+		{Request: "different", Status: types.DiffDifferent, Changes: []types.DiffChange{
+			{Path: "$.result.number", Kind: types.DiffChanged, From: "0x10", To: "0x11"},
+		}},
+		{Request: "error_vs_result", Status: types.DiffDifferent, Changes: []types.DiffChange{
+			{Path: "$.error", Kind: types.DiffRemoved,
+				From: map[string]any{"code": json.Number("-32601"), "message": "method not found"}},
+			{Path: "$.result", Kind: types.DiffAdded, To: "0x1"},
+		}},
+		{Request: "unreachable", Status: types.DiffFailed, Errors: []string{"staging: connection refused"}},
+		{Request: "missing", Status: types.DiffFailed, Errors: []string{"staging: request was not run"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Results() =\n%+v\nwant\n%+v", got, want)
+	}
+}
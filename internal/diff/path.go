@@ -0,0 +1,134 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// identifier matches keys that can be written as .key in a path
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// segment is an object key or array index in a path
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// String returns the segment as it is written in a path
+func (s segment) String() string {
+	switch {
+	case s.wildcard:
+		return "[*]"
+	case s.isIndex:
+		return "[" + strconv.Itoa(s.index) + "]"
+	case identifier.MatchString(s.key):
+		return "." + s.key
+	default:
+		return "['" + strings.ReplaceAll(s.key, "'", "\\'") + "']"
+	}
+}
+
+// formatPath returns a path such as $.result.transactions[0].hash
+func formatPath(path []segment) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, s := range path {
+		b.WriteString(s.String())
+	}
+	return b.String()
+}
+
+// Pattern matches the paths of values to leave out of a comparison
+type Pattern struct {
+	source   string
+	segments []segment
+}
+
+// ParsePattern parses an ignore path such as $.result.timestamp or
+// $.result.transactions[*].blockHash. A path also matches everything below
+// it, and `*` or `[*]` matches any single key or index.
+func ParsePattern(path string) (Pattern, error) {
+	rest := strings.TrimSpace(path)
+	rest = strings.TrimPrefix(rest, "$")
+
+	pattern := Pattern{source: path}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return Pattern{}, fmt.Errorf("empty field name in path %s", path)
+			}
+			key := rest[:end]
+			pattern.segments = append(pattern.segments, segment{key: key, wildcard: key == "*"})
+			rest = rest[end:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return Pattern{}, fmt.Errorf("unclosed bracket in path %s", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			if inner == "*" {
+				pattern.segments = append(pattern.segments, segment{wildcard: true})
+				continue
+			}
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				pattern.segments = append(pattern.segments, segment{key: inner[1 : len(inner)-1]})
+				continue
+			}
+
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return Pattern{}, fmt.Errorf("invalid index '%s' in path %s", inner, path)
+			}
+			pattern.segments = append(pattern.segments, segment{index: index, isIndex: true})
+
+		default:
+			// Allow a bare leading field name, e.g. "result.timestamp"
+			if len(pattern.segments) > 0 {
+				return Pattern{}, fmt.Errorf("unexpected character '%c' in path %s", rest[0], path)
+			}
+			rest = "." + rest
+		}
+	}
+
+	return pattern, nil
+}
+
+// String returns the path the pattern was parsed from
+func (p Pattern) String() string {
+	return p.source
+}
+
+// matches reports whether path is the pattern's path or below it
+func (p Pattern) matches(path []segment) bool {
+	if len(path) < len(p.segments) {
+		return false
+	}
+
+	for i, want := range p.segments {
+		got := path[i]
+		switch {
+		case want.wildcard:
+			continue
+		case want.isIndex != got.isIndex:
+			return false
+		case want.isIndex && want.index != got.index:
+			return false
+		case !want.isIndex && want.key != got.key:
+			return false
+		}
+	}
+	return true
+}
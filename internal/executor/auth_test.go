@@ -0,0 +1,262 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"jsonrpc/internal/parser"
+	"jsonrpc/pkg/types"
+)
+
+func TestExecutor_ExecuteAll_Auth(t *testing.T) {
+	var mu sync.Mutex
+	var authorizations []string
+	echo := newEchoServer(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		mu.Unlock()
+		echo.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	hclFile, err := parser.New().ParseFile(writeHCL(t, `
+config {
+  url     = "`+server.URL+`"
+  headers = { Authorization = "Bearer static" }
+  auth {
+    type     = "basic"
+    username = "alice"
+    password = "s3cret"
+  }
+}
+
+request "with_config_auth" {
+  method = "eth_blockNumber"
+}
+
+request "with_request_auth" {
+  method = "eth_blockNumber"
+  auth {
+    type  = "bearer"
+    token = "request-token"
+  }
+}
+`))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	results, err := New().ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+	for _, result := range results {
+		if !result.IsSuccess() {
+			t.Fatalf("request '%s' failed: %v", result.Request.Name, result.Error)
+		}
+	}
+
+	// Credentials take precedence over a static Authorization header, and
+	// a request's auth block replaces the config's
+	want := []string{"Basic YWxpY2U6czNjcmV0", "Bearer request-token"}
+	if len(authorizations) != 2 || authorizations[0] != want[0] || authorizations[1] != want[1] {
+		t.Errorf("Authorization headers = %q, want %q", authorizations, want)
+	}
+}
+
+func TestExecutor_ExecuteAll_CredentialCommand(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	echo := newEchoServer(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get("X-API-Key")+"|"+r.Header.Get("Authorization"))
+		mu.Unlock()
+		echo.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	hclFile, err := parser.New().ParseFile(writeHCL(t, `
+config {
+  url                = "`+server.URL+`"
+  credential_command = ["echo", "helper-key"]
+  credential_header  = "X-API-Key"
+  auth {
+    type  = "bearer"
+    token = "abc"
+  }
+}
+
+request "first" {
+  method = "eth_blockNumber"
+}
+
+request "second" {
+  method = "eth_chainId"
+}
+`))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	results, err := New().ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+	for _, result := range results {
+		if !result.IsSuccess() {
+			t.Fatalf("request '%s' failed: %v", result.Request.Name, result.Error)
+		}
+	}
+
+	// The helper's header is sent alongside the auth block's
+	want := "helper-key|Bearer abc"
+	if len(keys) != 2 || keys[0] != want || keys[1] != want {
+		t.Errorf("headers = %q, want %q twice", keys, want)
+	}
+}
+
+func TestExecutor_ExecuteAll_Signing(t *testing.T) {
+	var mu sync.Mutex
+	nonces := make(map[string]bool)
+	echo := newEchoServer(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Verify the signature over the bytes received
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write([]byte(r.Header.Get("X-Timestamp") + "." + r.Header.Get("X-Nonce") + "." + r.URL.Path + "." + string(body)))
+		if r.Header.Get("X-Signature") != hex.EncodeToString(mac.Sum(nil)) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		nonces[r.Header.Get("X-Nonce")] = true
+		mu.Unlock()
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		echo.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	hclFile, err := parser.New().ParseFile(writeHCL(t, `
+config {
+  url = "`+server.URL+`/rpc"
+  signing {
+    secret   = "s3cret"
+    template = "{timestamp}.{nonce}.{path}.{body}"
+  }
+}
+
+config "socket" {
+  url = "ipc:///tmp/rpc-cli-signing-test.sock"
+}
+
+request "first" {
+  method = "eth_blockNumber"
+}
+
+request "second" {
+  method = "echo"
+  params = [{ text = "héllo", n = 1.50 }]
+}
+
+request "over_ipc" {
+  config = "socket"
+  method = "eth_blockNumber"
+}
+`))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	results, err := New().ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+	for _, result := range results[:2] {
+		if !result.IsSuccess() {
+			t.Fatalf("request '%s' failed: %v", result.Request.Name, result.Error)
+		}
+	}
+	if len(nonces) != 2 {
+		t.Errorf("got %d distinct nonces, want 2", len(nonces))
+	}
+
+	// The socket profile inherits the default signing block
+	if err := results[2].Error; err == nil || !strings.Contains(err.Error(), "signing requires an http:// or https:// URL") {
+		t.Errorf("over_ipc error = %v, want a signing error", err)
+	}
+}
+
+func TestExecutor_ExecuteAll_OAuth2WithTLS(t *testing.T) {
+	echo := newEchoServer(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"access_token":"tls-token","token_type":"Bearer","expires_in":3600}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer tls-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		echo.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	// The token endpoint is only trusted through the profile's CA file
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hclFile, err := parser.New().ParseFile(writeHCL(t, `
+config {
+  url = "`+server.URL+`"
+  tls {
+    ca_file = "`+caFile+`"
+  }
+  auth {
+    type          = "oauth2_client_credentials"
+    token_url     = "`+server.URL+`/token"
+    client_id     = "cli"
+    client_secret = "s3cret"
+  }
+}
+
+request "get_block_number" {
+  method = "eth_blockNumber"
+}
+`))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	results, err := New().ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+	if !results[0].IsSuccess() {
+		t.Errorf("request failed: %v", results[0].Error)
+	}
+}
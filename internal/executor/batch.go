@@ -0,0 +1,281 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"jsonrpc/internal/parser"
+	"jsonrpc/internal/rpcid"
+	"jsonrpc/pkg/types"
+)
+
+// batchGroup is a set of requests that share an effective URL, headers and
+// timeout and are therefore sent in one JSON-RPC batch
+type batchGroup struct {
+	config  *types.EffectiveConfig
+	entries []batchEntry
+}
+
+// batchEntry is a request in a batch together with its position and id.
+// Notifications have a nil id.
+type batchEntry struct {
+	index   int
+	request *types.Request
+	id      any
+}
+
+// executeBatched sends requests as JSON-RPC batches. Requests are grouped by
+// dependency wave, so a request is only sent after the batches containing its
+// dependencies, and within a wave by effective URL, headers and timeout.
+func (e *Executor) executeBatched(
+	ctx context.Context,
+	hclFile *types.HCLFile,
+	ordered []*types.Request,
+	overrides *types.CLIOverrides,
+) ([]*types.ExecutionResult, error) {
+	results := make([]*types.ExecutionResult, len(ordered))
+	resultsByName := make(map[string]*types.ExecutionResult, len(ordered))
+
+	for _, wave := range dependencyWaves(ordered) {
+		var groups []*batchGroup
+		groupsByKey := make(map[string]*batchGroup)
+
+		for _, i := range wave {
+			req := ordered[i]
+			if ctx.Err() != nil {
+				results[i] = notRun(ctx, req)
+				continue
+			}
+
+			resolved, err := parser.ResolveParams(req, resultsByName)
+			if err != nil {
+				results[i] = &types.ExecutionResult{Request: req, Error: err}
+				continue
+			}
+
+			config := e.configMgr.BuildForRequest(hclFile, resolved, overrides)
+			if !config.HasEndpoint() {
+				results[i] = &types.ExecutionResult{
+					Request: resolved,
+					Error:   fmt.Errorf("no URL or command configured for request '%s'", req.Name),
+				}
+				continue
+			}
+
+			var id any
+			if !resolved.Notification {
+				strategy, err := rpcid.Parse(config.IDStrategy)
+				if err != nil {
+					results[i] = &types.ExecutionResult{Request: resolved, Error: err}
+					continue
+				}
+				id = strategy.ID(i + 1)
+			}
+
+			key := batchKey(config)
+			group, exists := groupsByKey[key]
+			if !exists {
+				group = &batchGroup{config: config}
+				groupsByKey[key] = group
+				groups = append(groups, group)
+			}
+			group.entries = append(group.entries, batchEntry{index: i, request: resolved, id: id})
+		}
+
+		for _, group := range groups {
+			e.executeBatchGroup(ctx, group, results)
+		}
+
+		for _, i := range wave {
+			resultsByName[ordered[i].Name] = results[i]
+			e.finished(results[i])
+		}
+	}
+
+	return results, nil
+}
+
+// executeBatchGroup sends one batch and stores a result for every entry
+func (e *Executor) executeBatchGroup(ctx context.Context, group *batchGroup, results []*types.ExecutionResult) {
+	if ctx.Err() != nil {
+		failBatchGroup(group, results, interrupted(ctx, types.ErrNotRun))
+		return
+	}
+
+	payload := make([]*types.JSONRPCRequest, 0, len(group.entries))
+	seen := make(map[string]bool, len(group.entries))
+	for _, entry := range group.entries {
+		if entry.id != nil {
+			key := types.IDKey(entry.id)
+			if seen[key] {
+				failBatchGroup(group, results,
+					fmt.Errorf("requests in a batch share the id %s; use an id_strategy other than fixed", key))
+				return
+			}
+			seen[key] = true
+		}
+		payload = append(payload, types.NewJSONRPCRequest(entry.request.Method, entry.request.ProcessedParams, entry.id))
+	}
+
+	// Only transport and HTTP errors are retried; JSON-RPC errors of
+	// individual requests are not
+	startTime := time.Now()
+	trace := e.newTrace()
+	var responses map[string]*types.JSONRPCResponse
+	attempts, err := e.withRetry(ctx, group.config, func() (*types.RPCError, error) {
+		var err error
+		responses, err = e.sendBatch(ctx, group.config, payload, trace)
+		return nil, err
+	})
+	if err != nil && ctx.Err() != nil {
+		err = interrupted(ctx, types.ErrCancelled)
+	}
+	duration := time.Since(startTime)
+
+	// Every request of the batch shares the trace of the exchange
+	for _, entry := range group.entries {
+		result := &types.ExecutionResult{
+			Request:  entry.request,
+			Duration: duration,
+			Attempts: attempts,
+			Trace:    trace,
+		}
+		switch {
+		case err != nil:
+			result.Error = err
+		case entry.id == nil:
+			// Notifications get no response
+		case responses[types.IDKey(entry.id)] != nil:
+			result.Response = responses[types.IDKey(entry.id)]
+		default:
+			result.Error = fmt.Errorf("no response for request id %s in batch", types.IDKey(entry.id))
+		}
+		results[entry.index] = result
+	}
+}
+
+// failBatchGroup stores err as the result of every entry of a batch that
+// is not sent
+func failBatchGroup(group *batchGroup, results []*types.ExecutionResult, err error) {
+	for _, entry := range group.entries {
+		results[entry.index] = &types.ExecutionResult{Request: entry.request, Error: err}
+	}
+}
+
+// sendBatch posts a batch payload and returns the responses keyed by
+// types.IDKey of their id. A single error object in place of an array (e.g.
+// a server that rejects batches) is applied to every request in the batch.
+// A batch of notifications only gets an empty reply, which is not parsed.
+func (e *Executor) sendBatch(
+	ctx context.Context,
+	config *types.EffectiveConfig,
+	payload []*types.JSONRPCRequest,
+	trace *types.Trace,
+) (map[string]*types.JSONRPCResponse, error) {
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	respBody, err := e.roundTrip(ctx, config, reqBody, trace)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make(map[string]*types.JSONRPCResponse, len(payload))
+	if allNotifications(payload) {
+		return responses, nil
+	}
+
+	trimmed := bytes.TrimSpace(respBody)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		batch, err := decodeBatchResponse(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JSON-RPC batch response: %w", err)
+		}
+		for _, resp := range batch {
+			responses[resp.IDKey()] = resp
+		}
+		return responses, nil
+	}
+
+	single, err := decodeResponse(trimmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON-RPC batch response: %w", err)
+	}
+
+	if single.IsError() {
+		for _, req := range payload {
+			if req.IsNotification() {
+				continue
+			}
+			resp := *single
+			resp.ID = json.RawMessage(types.IDKey(req.ID))
+			responses[resp.IDKey()] = &resp
+		}
+		return responses, nil
+	}
+
+	responses[single.IDKey()] = single
+	return responses, nil
+}
+
+// allNotifications reports whether no request of a batch expects a response
+func allNotifications(payload []*types.JSONRPCRequest) bool {
+	for _, req := range payload {
+		if !req.IsNotification() {
+			return false
+		}
+	}
+	return true
+}
+
+// batchKey identifies the effective endpoint, headers, timeout, retry
+// policy, TLS settings, auth block, credential helper, signing block, id
+// strategy, response size limit and rate limit bucket of a request
+func batchKey(config *types.EffectiveConfig) string {
+	names := make([]string, 0, len(config.Headers))
+	for name := range config.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s\n%d\n%v\n", config.Endpoint(), config.Framing, config.Timeout, newRetryPolicy(config.Retry))
+	fmt.Fprintf(&b, "%s\n%s\n%s\n%s\n%s\n",
+		config.TLS.Key(), config.Auth.Key(), config.Credential.Key(), config.Signing.Key(), config.IDStrategy)
+	fmt.Fprintf(&b, "%d\n%s\n%s\n", config.MaxResponseSize, config.RateLimit, rateLimitKey(config))
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\n", strings.ToLower(name), config.Headers[name])
+	}
+	return b.String()
+}
+
+// dependencyWaves splits dependency-ordered requests into waves of indexes.
+// Every request is in a later wave than the requests it depends on.
+func dependencyWaves(ordered []*types.Request) [][]int {
+	levels := make(map[string]int, len(ordered))
+	var waves [][]int
+
+	for i, req := range ordered {
+		level := 0
+		for _, dep := range req.DependsOn {
+			if depLevel, exists := levels[dep]; exists && depLevel+1 > level {
+				level = depLevel + 1
+			}
+		}
+		levels[req.Name] = level
+
+		for len(waves) <= level {
+			waves = append(waves, nil)
+		}
+		waves[level] = append(waves[level], i)
+	}
+
+	return waves
+}
@@ -0,0 +1,232 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"jsonrpc/internal/parser"
+	"jsonrpc/pkg/types"
+)
+
+// newBatchServer starts a JSON-RPC server that answers batches in reverse
+// order, echoing params as the result and dropping calls to "drop". It counts
+// the HTTP requests it receives.
+func newBatchServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var posts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&posts, 1)
+
+		var calls []struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			ID     int             `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&calls); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"jsonrpc": "2.0",
+				"error":   map[string]any{"code": -32600, "message": "batch expected"},
+				"id":      nil,
+			})
+			return
+		}
+
+		responses := make([]map[string]any, 0, len(calls))
+		for i := len(calls) - 1; i >= 0; i-- {
+			if calls[i].Method == "drop" {
+				continue
+			}
+			result := calls[i].Params
+			if calls[i].Method == "eth_blockNumber" {
+				result = json.RawMessage(`"0x10"`)
+			}
+			responses = append(responses, map[string]any{
+				"jsonrpc": "2.0",
+				"result":  result,
+				"id":      calls[i].ID,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(responses)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &posts
+}
+
+func TestExecutor_ExecuteAll_Batch(t *testing.T) {
+	server, posts := newBatchServer(t)
+
+	path := writeHCL(t, `
+config {
+  url = "`+server.URL+`"
+}
+
+config "other" {
+  url     = "`+server.URL+`"
+  headers = { "X-Profile" = "other" }
+}
+
+request "first" {
+  method = "echo"
+  params = [1]
+}
+
+request "second" {
+  method = "echo"
+  params = [2]
+}
+
+request "dropped" {
+  method = "drop"
+}
+
+request "other_profile" {
+  method = "echo"
+  params = [3]
+  config = "other"
+}
+
+request "get_block_number" {
+  method = "eth_blockNumber"
+}
+
+request "get_block" {
+  method = "eth_getBlockByNumber"
+  params = [request.get_block_number.result]
+}
+`)
+
+	hclFile, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	exec := New()
+	exec.SetBatch(true)
+
+	results, err := exec.ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+
+	// Wave 1: default profile batch and "other" profile batch; wave 2: get_block
+	if got := atomic.LoadInt32(posts); got != 3 {
+		t.Errorf("server received %d HTTP requests, want 3", got)
+	}
+
+	want := map[string]string{
+		"first":            `[1]`,
+		"second":           `[2]`,
+		"other_profile":    `[3]`,
+		"get_block_number": `"0x10"`,
+		"get_block":        `["0x10"]`,
+	}
+	for _, result := range results {
+		if result.Request.Name == "dropped" {
+			if result.Error == nil || result.Error.Error() != "no response for request id 3 in batch" {
+				t.Errorf("dropped error = %v, want missing response", result.Error)
+			}
+			continue
+		}
+		if !result.IsSuccess() {
+			t.Fatalf("request '%s' failed: %v", result.Request.Name, result.Error)
+		}
+		if got := string(result.Response.Result); got != want[result.Request.Name] {
+			t.Errorf("%s result = %s, want %s", result.Request.Name, got, want[result.Request.Name])
+		}
+	}
+}
+
+func TestExecutor_sendBatch_SingleErrorObject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32600,"message":"batches not supported"},"id":null}`))
+	}))
+	t.Cleanup(server.Close)
+
+	config := types.NewEffectiveConfig()
+	config.URL = server.URL
+
+	responses, err := New().sendBatch(context.Background(), config, []*types.JSONRPCRequest{
+		types.NewJSONRPCRequest("a", nil, 1),
+		types.NewJSONRPCRequest("b", nil, 2),
+	}, nil)
+	if err != nil {
+		t.Fatalf("sendBatch() error = %v", err)
+	}
+
+	for _, id := range []int{1, 2} {
+		resp := responses[strconv.Itoa(id)]
+		if resp == nil || !resp.IsError() || resp.Error.Code != -32600 {
+			t.Errorf("response %d = %+v, want batch error", id, resp)
+		}
+	}
+}
+
+func TestExecutor_ExecuteAll_BatchSplitsProfileLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  string
+		wantPosts int32
+	}{
+		{name: "same settings", settings: ``, wantPosts: 1},
+		{name: "different rate_limit", settings: `rate_limit = "100/s"`, wantPosts: 2},
+		{name: "different max_response_size", settings: `max_response_size = "1MB"`, wantPosts: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, posts := newBatchServer(t)
+
+			hclFile, err := parser.New().ParseFile(writeHCL(t, `
+config {
+  url = "`+server.URL+`"
+}
+
+config "other" {
+  url = "`+server.URL+`"
+  `+tt.settings+`
+}
+
+request "first" {
+  method = "echo"
+  params = [1]
+}
+
+request "second" {
+  method = "echo"
+  params = [2]
+  config = "other"
+}
+`))
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+
+			exec := New()
+			exec.SetBatch(true)
+
+			results, err := exec.ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+			if err != nil {
+				t.Fatalf("ExecuteAll() error = %v", err)
+			}
+			for _, result := range results {
+				if !result.IsSuccess() {
+					t.Fatalf("request '%s' failed: %v", result.Request.Name, result.Error)
+				}
+			}
+			if got := atomic.LoadInt32(posts); got != tt.wantPosts {
+				t.Errorf("server received %d batches, want %d", got, tt.wantPosts)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"jsonrpc/internal/parser"
+	"jsonrpc/pkg/types"
+)
+
+// newHangingServer starts a JSON-RPC server that answers "slow" only once the
+// client gives up, and everything else right away
+func newHangingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			ID     int    `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if req.Method == "slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "result": req.Method, "id": req.ID})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestExecutor_ExecuteAll_Cancelled(t *testing.T) {
+	tests := []struct {
+		name        string
+		parallelism int
+	}{
+		{name: "sequential", parallelism: 1},
+		{name: "parallel", parallelism: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newHangingServer(t)
+
+			hclFile, err := parser.New().ParseFile(writeHCL(t, `
+config {
+  url = "`+server.URL+`"
+}
+
+request "fast" {
+  method = "fast"
+}
+
+request "slow" {
+  method = "slow"
+}
+
+request "after_slow" {
+  method = "fast"
+  params = [request.slow.result]
+}
+`))
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+
+			exec := New()
+			exec.SetParallelism(tt.parallelism)
+
+			ctx, cancel := context.WithTimeoutCause(context.Background(), 200*time.Millisecond,
+				errors.New("total timeout exceeded"))
+			defer cancel()
+
+			start := time.Now()
+			results, err := exec.ExecuteAll(ctx, hclFile, hclFile.Requests, types.NewCLIOverrides())
+			if err != nil {
+				t.Fatalf("ExecuteAll() error = %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("ExecuteAll() took %s, want it to stop at the deadline", elapsed)
+			}
+
+			if !results[0].IsSuccess() {
+				t.Errorf("fast error = %v, want success", results[0].Error)
+			}
+			if !errors.Is(results[1].Error, types.ErrCancelled) ||
+				results[1].Error.Error() != "cancelled: total timeout exceeded" {
+				t.Errorf("slow error = %v, want cancelled", results[1].Error)
+			}
+			if !errors.Is(results[2].Error, types.ErrNotRun) ||
+				results[2].Error.Error() != "not run: total timeout exceeded" {
+				t.Errorf("after_slow error = %v, want not run", results[2].Error)
+			}
+		})
+	}
+}
+
+func TestExecutor_Execute_CancelledBeforeStart(t *testing.T) {
+	hclFile, err := parser.New().ParseFile(writeHCL(t, `
+request "fast" {
+  url    = "http://127.0.0.1:1"
+  method = "fast"
+}
+`))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := New().Execute(ctx, hclFile, hclFile.Requests[0], types.NewCLIOverrides(), 1)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Error != types.ErrNotRun {
+		t.Errorf("error = %v, want %v", result.Error, types.ErrNotRun)
+	}
+}
+
+func TestExecutor_withRetry_StopsWhenCancelled(t *testing.T) {
+	config := types.NewEffectiveConfig()
+	config.Retry = &types.RetryPolicy{MaxAttempts: 5}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	exec := New()
+	attempts, err := exec.withRetry(ctx, config, func() (*types.RPCError, error) {
+		cancel()
+		return nil, errors.New("connection refused")
+	})
+
+	if attempts != 1 || err == nil {
+		t.Errorf("withRetry() = %d, %v; want 1 attempt and the attempt's error", attempts, err)
+	}
+}
@@ -0,0 +1,148 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"jsonrpc/internal/cassette"
+	"jsonrpc/internal/parser"
+	"jsonrpc/pkg/types"
+)
+
+func TestExecutor_ExecuteAll_RecordAndReplay(t *testing.T) {
+	server, _ := newBatchServer(t)
+
+	path := writeHCL(t, `
+config {
+  url     = "`+server.URL+`"
+  headers = { "Authorization" = "Bearer secret-token" }
+}
+
+request "get_block_number" {
+  method = "eth_blockNumber"
+}
+
+request "get_block" {
+  method = "eth_getBlockByNumber"
+  params = [request.get_block_number.result, true]
+}
+
+request "echo" {
+  method = "echo"
+  params = { b = 2, a = 1 }
+}
+`)
+
+	hclFile, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	// Record the calls sent as batches
+	tape := cassette.New()
+	recorder := New()
+	recorder.SetBatch(true)
+	recorder.SetRecorder(tape)
+
+	recorded, err := recorder.ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+	for _, result := range recorded {
+		if !result.IsSuccess() {
+			t.Fatalf("request '%s' failed while recording: %v", result.Request.Name, result.Error)
+		}
+	}
+	if tape.Len() != 3 {
+		t.Fatalf("cassette has %d interactions, want 3", tape.Len())
+	}
+
+	cassettePath := filepath.Join(t.TempDir(), "cassette.json")
+	if err := tape.Save(cassettePath); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	data, err := os.ReadFile(cassettePath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "secret-token") {
+		t.Errorf("cassette contains the unmasked Authorization header:\n%s", data)
+	}
+
+	// Replay them one by one with the server gone
+	server.Close()
+
+	replay, err := cassette.Load(cassettePath, cassette.MatchParams)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	exec := New()
+	exec.SetTrace(true)
+	exec.SetReplay(replay)
+
+	results, err := exec.ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+
+	want := map[string]string{
+		"get_block_number": `"0x10"`,
+		"get_block":        `["0x10",true]`,
+		"echo":             `{"a":1,"b":2}`,
+	}
+	for _, result := range results {
+		if !result.IsSuccess() {
+			t.Errorf("request '%s' failed on replay: %v", result.Request.Name, result.Error)
+			continue
+		}
+		if got := string(result.Response.Result); got != want[result.Request.Name] {
+			t.Errorf("%s result = %s, want %s", result.Request.Name, got, want[result.Request.Name])
+		}
+		if result.Trace == nil || result.Trace.Response == nil {
+			t.Errorf("%s has no trace of the replayed response", result.Request.Name)
+		}
+	}
+}
+
+func TestExecutor_Execute_ReplayNotRecorded(t *testing.T) {
+	path := writeHCL(t, `
+config {
+  url = "http://127.0.0.1:1"
+  retry {
+    max_attempts = 3
+  }
+}
+
+request "missing" {
+  method = "eth_chainId"
+}
+`)
+
+	hclFile, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	cassettePath := filepath.Join(t.TempDir(), "empty.json")
+	if err := cassette.New().Save(cassettePath); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	replay, err := cassette.Load(cassettePath, cassette.MatchMethod)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	exec := New()
+	exec.SetReplay(replay)
+
+	result, _ := exec.Execute(context.Background(), hclFile, hclFile.Requests[0], types.NewCLIOverrides(), 1)
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "no recorded response for eth_chainId") {
+		t.Fatalf("Execute() error = %v, want no recorded response", result.Error)
+	}
+	if result.Attempts != 1 {
+		t.Errorf("Execute() made %d attempts, want 1", result.Attempts)
+	}
+}
@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"fmt"
+
+	"jsonrpc/pkg/types"
+)
+
+// orderByDependencies returns the requests in an order where every request
+// comes after the requests it depends on. Dependencies that were not selected
+// are pulled in from the HCL file. The original order is kept wherever the
+// dependencies allow it.
+func orderByDependencies(hclFile *types.HCLFile, requests []*types.Request) ([]*types.Request, error) {
+	byName := make(map[string]*types.Request, len(hclFile.Requests))
+	for _, req := range hclFile.Requests {
+		byName[req.Name] = req
+	}
+
+	ordered := make([]*types.Request, 0, len(requests))
+	placed := make(map[string]bool, len(requests))
+	visiting := make(map[string]bool)
+
+	var place func(req *types.Request) error
+	place = func(req *types.Request) error {
+		if placed[req.Name] {
+			return nil
+		}
+		if visiting[req.Name] {
+			return fmt.Errorf("request '%s' is part of a dependency cycle", req.Name)
+		}
+
+		visiting[req.Name] = true
+		for _, name := range req.DependsOn {
+			dep, exists := byName[name]
+			if !exists {
+				return fmt.Errorf("request '%s' references non-existent request '%s'", req.Name, name)
+			}
+			if err := place(dep); err != nil {
+				return err
+			}
+		}
+		visiting[req.Name] = false
+
+		placed[req.Name] = true
+		ordered = append(ordered, req)
+		return nil
+	}
+
+	for _, req := range requests {
+		if err := place(req); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
@@ -1,61 +1,169 @@
 package executor
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 	"time"
 
+	"jsonrpc/internal/auth"
+	"jsonrpc/internal/cassette"
+	"jsonrpc/internal/parser"
+	"jsonrpc/internal/ratelimit"
+	"jsonrpc/internal/rpcid"
+	"jsonrpc/internal/transport"
 	"jsonrpc/pkg/config"
-	"jsonrpc/pkg/constants"
 	"jsonrpc/pkg/types"
 )
 
 // Executor handles JSON-RPC request execution
 type Executor struct {
-	client    *http.Client
-	configMgr *config.Manager
+	transports  *transport.Pool
+	configMgr   *config.Manager
+	parallelism int
+	batch       bool
+	trace       bool
+	limiters    *ratelimit.Registry
+	auth        *auth.Registry
+
+	// recorder stores the calls sent; replay answers them instead of the
+	// network. At most one of them is set.
+	recorder *cassette.Cassette
+	replay   *cassette.Cassette
+
+	// onResult is called with each result of ExecuteAll as soon as it is
+	// final; nil when not set
+	onResult func(*types.ExecutionResult)
+
+	// sleep waits between retry attempts and for rate limits, or until ctx
+	// is done; replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
 }
 
 // New creates a new Executor instance
 func New() *Executor {
+	transports := transport.NewPool(&http.Client{})
 	return &Executor{
-		client:    &http.Client{},
-		configMgr: config.NewManager(),
+		transports:  transports,
+		configMgr:   config.NewManager(),
+		parallelism: 1,
+		limiters:    ratelimit.NewRegistry(),
+		auth:        auth.NewRegistry(transports.Client),
+		sleep:       sleepContext,
 	}
 }
 
-// Execute executes a single JSON-RPC request
+// SetParallelism sets how many requests ExecuteAll runs at the same time.
+// Values below 1 are treated as 1 (sequential execution).
+func (e *Executor) SetParallelism(n int) {
+	if n < 1 {
+		n = 1
+	}
+	e.parallelism = n
+}
+
+// Parallelism returns how many requests ExecuteAll runs at the same time
+func (e *Executor) Parallelism() int {
+	return e.parallelism
+}
+
+// Close closes the connections kept open for connection-oriented
+// transports such as WebSocket
+func (e *Executor) Close() error {
+	return e.transports.Close()
+}
+
+// SetBatch enables sending the requests of ExecuteAll as JSON-RPC batches
+func (e *Executor) SetBatch(enabled bool) {
+	e.batch = enabled
+}
+
+// SetTrace enables recording the wire-level trace of each result
+func (e *Executor) SetTrace(enabled bool) {
+	e.trace = enabled
+}
+
+// SetRecorder records each call sent and the response received in c
+func (e *Executor) SetRecorder(c *cassette.Cassette) {
+	e.recorder = c
+}
+
+// SetReplay answers calls from the responses recorded in c, without
+// sending anything
+func (e *Executor) SetReplay(c *cassette.Cassette) {
+	e.replay = c
+}
+
+// SetOnResult makes ExecuteAll call fn with each result as soon as its
+// request has finished, rather than only returning all results at the end.
+// With --parallel, fn is called from several goroutines at once.
+func (e *Executor) SetOnResult(fn func(*types.ExecutionResult)) {
+	e.onResult = fn
+}
+
+// Execute executes a single JSON-RPC request. Cancelling ctx aborts the
+// request, which then fails with types.ErrCancelled, or types.ErrNotRun if
+// ctx was done before it started.
 func (e *Executor) Execute(
+	ctx context.Context,
 	hclFile *types.HCLFile,
 	req *types.Request,
 	overrides *types.CLIOverrides,
 	requestID int,
 ) (*types.ExecutionResult, error) {
+	if ctx.Err() != nil {
+		return notRun(ctx, req), nil
+	}
+
 	startTime := time.Now()
 
 	// Build effective configuration using the new configuration manager
 	config := e.configMgr.BuildForRequest(hclFile, req, overrides)
 
 	// Validate URL
-	if config.URL == "" {
+	if !config.HasEndpoint() {
 		return &types.ExecutionResult{
 			Request:  req,
 			Duration: time.Since(startTime),
-			Error:    fmt.Errorf("no URL configured for request '%s'", req.Name),
+			Error:    fmt.Errorf("no URL or command configured for request '%s'", req.Name),
 		}, nil
 	}
 
-	// Create and execute JSON-RPC request
-	response, err := e.executeJSONRPC(config, req, requestID)
+	// Notifications are sent without an id. Other ids are chosen once, so
+	// that retries of a request carry the same id (except for uuid).
+	var id any
+	if !req.Notification {
+		strategy, err := rpcid.Parse(config.IDStrategy)
+		if err != nil {
+			return &types.ExecutionResult{Request: req, Duration: time.Since(startTime), Error: err}, nil
+		}
+		id = strategy.ID(requestID)
+	}
+
+	// Create and execute JSON-RPC request, retrying as configured
+	trace := e.newTrace()
+	var response *types.JSONRPCResponse
+	attempts, err := e.withRetry(ctx, config, func() (*types.RPCError, error) {
+		resp, err := e.executeJSONRPC(ctx, config, req, id, trace)
+		if err != nil || resp == nil {
+			return nil, err
+		}
+		response = resp
+		return resp.Error, nil
+	})
+	if err != nil && ctx.Err() != nil {
+		err = interrupted(ctx, types.ErrCancelled)
+	}
 	if err != nil {
 		return &types.ExecutionResult{
 			Request:  req,
 			Duration: time.Since(startTime),
 			Error:    err,
+			Attempts: attempts,
+			Trace:    trace,
 		}, nil
 	}
 
@@ -63,42 +171,118 @@ func (e *Executor) Execute(
 		Request:  req,
 		Response: response,
 		Duration: time.Since(startTime),
+		Attempts: attempts,
+		Trace:    trace,
 	}, nil
 }
 
-// ExecuteAll executes multiple requests
+// ExecuteAll executes multiple requests. Requests are ordered so that each
+// one runs after the requests whose results its params reference. With a
+// parallelism above 1, independent requests run concurrently; results are
+// always returned in execution order. Cancelling ctx stops the run: requests
+// in flight fail with types.ErrCancelled and the remaining ones with
+// types.ErrNotRun, so every request still has a result.
 func (e *Executor) ExecuteAll(
+	ctx context.Context,
 	hclFile *types.HCLFile,
 	requests []*types.Request,
 	overrides *types.CLIOverrides,
 ) ([]*types.ExecutionResult, error) {
-	results := make([]*types.ExecutionResult, 0, len(requests))
+	ordered, err := orderByDependencies(hclFile, requests)
+	if err != nil {
+		return nil, err
+	}
+
+	// Connections are shared by the requests of one run
+	defer func() {
+		_ = e.Close()
+	}()
+
+	if e.batch {
+		return e.executeBatched(ctx, hclFile, ordered, overrides)
+	}
+	if e.parallelism > 1 {
+		return e.executeParallel(ctx, hclFile, ordered, overrides)
+	}
+
+	results := make([]*types.ExecutionResult, 0, len(ordered))
+	resultsByName := make(map[string]*types.ExecutionResult, len(ordered))
 
-	for i, req := range requests {
-		result, err := e.Execute(hclFile, req, overrides, i+1)
+	for i, req := range ordered {
+		result, err := e.executeResolved(ctx, hclFile, req, overrides, i+1, resultsByName)
 		if err != nil {
 			return nil, err
 		}
 		results = append(results, result)
+		resultsByName[req.Name] = result
+		e.finished(result)
 	}
 
 	return results, nil
 }
 
+// finished passes a final result of ExecuteAll to the SetOnResult callback
+func (e *Executor) finished(result *types.ExecutionResult) {
+	if e.onResult != nil {
+		e.onResult(result)
+	}
+}
+
+// executeResolved resolves a request's params from the results of its
+// dependencies and executes it
+func (e *Executor) executeResolved(
+	ctx context.Context,
+	hclFile *types.HCLFile,
+	req *types.Request,
+	overrides *types.CLIOverrides,
+	requestID int,
+	dependencyResults map[string]*types.ExecutionResult,
+) (*types.ExecutionResult, error) {
+	// Dependencies of a cancelled run have no results to resolve from
+	if ctx.Err() != nil {
+		return notRun(ctx, req), nil
+	}
+
+	resolved, err := parser.ResolveParams(req, dependencyResults)
+	if err != nil {
+		return &types.ExecutionResult{Request: req, Error: err}, nil
+	}
+
+	return e.Execute(ctx, hclFile, resolved, overrides, requestID)
+}
+
+// notRun returns the result of a request skipped because ctx is done
+func notRun(ctx context.Context, req *types.Request) *types.ExecutionResult {
+	return &types.ExecutionResult{Request: req, Error: interrupted(ctx, types.ErrNotRun)}
+}
+
+// interrupted wraps types.ErrCancelled or types.ErrNotRun with the reason
+// ctx was cancelled, such as an exceeded total timeout. A plain cancel (e.g.
+// Ctrl-C) adds nothing.
+func interrupted(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, context.Canceled) {
+		return fmt.Errorf("%w: %w", err, cause)
+	}
+	return err
+}
+
 // GetConfigName returns the effective configuration name for a request
 // This maintains backward compatibility for the output formatter
 func (e *Executor) GetConfigName(req *types.Request, overrides *types.CLIOverrides) string {
 	return config.GetConfigName(req, overrides)
 }
 
-// executeJSONRPC executes a JSON-RPC request and returns the response
+// executeJSONRPC executes a JSON-RPC request and returns the response. A
+// nil id sends a notification, which returns a nil response.
 func (e *Executor) executeJSONRPC(
+	ctx context.Context,
 	config *types.EffectiveConfig,
 	req *types.Request,
-	requestID int,
+	id any,
+	trace *types.Trace,
 ) (*types.JSONRPCResponse, error) {
 	// Create JSON-RPC request
-	rpcReq := types.NewJSONRPCRequest(req.Method, req.ProcessedParams, requestID)
+	rpcReq := types.NewJSONRPCRequest(req.Method, req.ProcessedParams, id)
 
 	// Marshal to JSON
 	reqBody, err := json.Marshal(rpcReq)
@@ -106,48 +290,229 @@ func (e *Executor) executeJSONRPC(
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Create HTTP request
-	httpReq, err := http.NewRequest("POST", config.URL, bytes.NewReader(reqBody))
+	respBody, err := e.roundTrip(ctx, config, reqBody, trace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Servers do not answer notifications; anything sent anyway is ignored
+	if rpcReq.IsNotification() {
+		return nil, nil
+	}
+
+	// Parse JSON-RPC response
+	rpcResp, err := decodeResponse(respBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to parse JSON-RPC response: %w", err)
+	}
+	if err := checkResponseID(rpcResp, id); err != nil {
+		return nil, err
+	}
+
+	return rpcResp, nil
+}
+
+// checkResponseID reports a response that does not carry the id of its
+// request. Error responses may have a null id when the server could not
+// read the request.
+func checkResponseID(resp *types.JSONRPCResponse, id any) error {
+	got, want := resp.IDKey(), types.IDKey(id)
+	if got == want || (got == "null" && resp.IsError()) {
+		return nil
+	}
+	return fmt.Errorf("response id %s does not match request id %s", got, want)
+}
+
+// roundTrip sends a payload over the transport selected by the URL scheme,
+// bounded by the configured timeout and ctx. Waiting for the rate limit does
+// not count towards the timeout. A non-nil trace is reset and records this
+// attempt.
+func (e *Executor) roundTrip(
+	ctx context.Context,
+	config *types.EffectiveConfig,
+	payload []byte,
+	trace *types.Trace,
+) ([]byte, error) {
+	if e.replay != nil {
+		return e.replayRoundTrip(config, payload, trace)
 	}
 
-	// Add headers
-	httpReq.Header.Set("Content-Type", constants.HeaderContentType)
-	for k, v := range config.Headers {
-		httpReq.Header.Set(k, v)
+	if err := e.waitForRateLimit(ctx, config); err != nil {
+		return nil, err
 	}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Timeout)*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.Timeout)*time.Second)
 	defer cancel()
-	httpReq = httpReq.WithContext(ctx)
 
-	// Execute request
-	httpResp, err := e.client.Do(httpReq)
+	config, err := e.authorize(ctx, config)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, err
+	}
+	config, err = sign(config, payload)
+	if err != nil {
+		return nil, err
 	}
-	defer func() {
-		_ = httpResp.Body.Close()
-	}()
 
-	// Read response body
-	respBody, err := io.ReadAll(httpResp.Body)
+	if trace != nil {
+		*trace = types.Trace{Request: types.TraceRequest{URL: config.Endpoint(), Body: string(payload)}}
+		ctx = transport.WithTrace(ctx, trace)
+		start := time.Now()
+		defer func() {
+			trace.Timings.Total = time.Since(start)
+		}()
+	}
+
+	t, err := e.transports.Get(ctx, config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
+	respBody, err := t.RoundTrip(ctx, config, payload)
 
-	// Check HTTP status
-	if httpResp.StatusCode >= constants.MinClientErrorStatus {
-		return nil, fmt.Errorf("HTTP error: %d %s - %s", httpResp.StatusCode, httpResp.Status, string(respBody))
+	// Transports other than HTTP only provide the response payload
+	if trace != nil && trace.Response == nil && respBody != nil {
+		trace.Response = &types.TraceResponse{Body: respBody}
 	}
 
-	// Parse JSON-RPC response
-	var rpcResp types.JSONRPCResponse
-	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON-RPC response: %w", err)
+	if err == nil && e.recorder != nil {
+		if recordErr := e.recorder.Record(config, payload, respBody); recordErr != nil {
+			return nil, recordErr
+		}
+	}
+	return respBody, err
+}
+
+// replayRoundTrip answers a payload from the replay cassette, skipping
+// rate limits, authentication and the transport
+func (e *Executor) replayRoundTrip(
+	config *types.EffectiveConfig,
+	payload []byte,
+	trace *types.Trace,
+) ([]byte, error) {
+	respBody, err := e.replay.Replay(config, payload)
+	if trace != nil {
+		*trace = types.Trace{Request: types.TraceRequest{URL: config.Endpoint(), Body: string(payload)}}
+		if respBody != nil {
+			trace.Response = &types.TraceResponse{Body: respBody}
+		}
+	}
+	return respBody, err
+}
+
+// newTrace returns a trace to record attempts in, or nil if tracing is off
+func (e *Executor) newTrace() *types.Trace {
+	if !e.trace {
+		return nil
+	}
+	return &types.Trace{}
+}
+
+// sign returns a copy of config whose credentials include the signature
+// headers of its signing block, computed over the exact payload. Each
+// attempt is signed again with a fresh timestamp and nonce.
+func sign(config *types.EffectiveConfig, payload []byte) (*types.EffectiveConfig, error) {
+	if config.Signing == nil {
+		return config, nil
+	}
+
+	endpoint, err := url.Parse(config.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("signing requires an http:// or https:// URL, got '%s'", config.Endpoint())
+	}
+
+	path := endpoint.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	credentials := make(map[string]string, len(config.Credentials)+3)
+	for name, value := range config.Credentials {
+		credentials[name] = value
+	}
+	if err := auth.NewSigner(config.Signing).Sign(path, payload, credentials); err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	signed := *config
+	signed.Credentials = credentials
+	return &signed, nil
+}
+
+// waitForRateLimit blocks until the token bucket of the request's profile
+// allows another request. Every profile with a rate limit has its own
+// bucket, shared by all requests resolving to it; a --rate limit has a
+// single bucket shared by all requests.
+func (e *Executor) waitForRateLimit(ctx context.Context, config *types.EffectiveConfig) error {
+	if config.RateLimit == "" {
+		return nil
+	}
+
+	rate, err := ratelimit.Parse(config.RateLimit)
+	if err != nil {
+		return err
+	}
+
+	if delay := e.limiters.Limiter(rateLimitKey(config), rate).Reserve(); delay > 0 {
+		return e.sleep(ctx, delay)
+	}
+	return nil
+}
+
+// rateLimitKey returns the token bucket of a request, or an empty string
+// when it has no rate limit
+func rateLimitKey(config *types.EffectiveConfig) string {
+	switch {
+	case config.RateLimit == "":
+		return ""
+	case config.RateLimitGlobal:
+		return "global"
+	default:
+		return "profile:" + config.Profile
+	}
+}
+
+// sleepContext waits for d, or returns ctx's error if it is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// authorize returns a copy of config carrying the credential headers of its
+// auth block and credential helper. Providers are shared, so tokens are
+// cached across requests.
+func (e *Executor) authorize(ctx context.Context, config *types.EffectiveConfig) (*types.EffectiveConfig, error) {
+	if config.Auth == nil && config.Credential == nil {
+		return config, nil
+	}
+
+	credentials := make(map[string]string)
+	if config.Auth != nil {
+		provider, err := e.auth.Provider(config.Auth, config.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up %s auth: %w", config.Auth.Type, err)
+		}
+		if err := provider.Apply(ctx, credentials); err != nil {
+			return nil, fmt.Errorf("%s auth failed: %w", config.Auth.Type, err)
+		}
+	}
+
+	if config.Credential != nil {
+		provider, err := e.auth.CommandProvider(config.Credential)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up credential command: %w", err)
+		}
+		if err := provider.Apply(ctx, credentials); err != nil {
+			return nil, err
+		}
 	}
 
-	return &rpcResp, nil
+	authorized := *config
+	authorized.Credentials = credentials
+	return &authorized, nil
 }
@@ -0,0 +1,199 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"jsonrpc/internal/parser"
+	"jsonrpc/pkg/types"
+)
+
+// newEchoServer starts a JSON-RPC server that returns the request params as
+// the result, or "0x10" for eth_blockNumber
+func newEchoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			ID     int             `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result := req.Params
+		if req.Method == "eth_blockNumber" {
+			result = json.RawMessage(`"0x10"`)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"result":  result,
+			"id":      req.ID,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestExecutor_ExecuteAll_RequestChaining(t *testing.T) {
+	server := newEchoServer(t)
+
+	path := writeHCL(t, `
+config {
+  url = "`+server.URL+`"
+}
+
+request "get_block" {
+  method = "eth_getBlockByNumber"
+  params = [request.get_block_number.result, true]
+}
+
+request "get_block_number" {
+  method = "eth_blockNumber"
+  params = []
+}
+`)
+
+	hclFile, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	// Only the dependent request is selected; its dependency is pulled in
+	results, err := New().ExecuteAll(context.Background(), hclFile, hclFile.Requests[:1], types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("ExecuteAll() returned %d results, want 2", len(results))
+	}
+	if results[0].Request.Name != "get_block_number" || results[1].Request.Name != "get_block" {
+		t.Fatalf("results order = [%s %s], want [get_block_number get_block]",
+			results[0].Request.Name, results[1].Request.Name)
+	}
+
+	for _, result := range results {
+		if !result.IsSuccess() {
+			t.Fatalf("request '%s' failed: %v", result.Request.Name, result.Error)
+		}
+	}
+
+	if got := string(results[1].Response.Result); got != `["0x10",true]` {
+		t.Errorf("get_block result = %s, want [\"0x10\",true]", got)
+	}
+}
+
+func TestExecutor_ExecuteAll_FailedDependency(t *testing.T) {
+	path := writeHCL(t, `
+request "get_block_number" {
+  method = "eth_blockNumber"
+}
+
+request "get_block" {
+  method = "eth_getBlockByNumber"
+  params = [request.get_block_number.result]
+}
+`)
+
+	hclFile, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	results, err := New().ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+
+	// No URL is configured, so the first request fails and the second is skipped
+	if results[1].Error == nil || results[1].Error.Error() != "dependency 'get_block_number' failed" {
+		t.Errorf("dependent request error = %v, want failed dependency", results[1].Error)
+	}
+}
+
+func TestExecutor_ExecuteAll_OnResult(t *testing.T) {
+	server, _ := newBatchServer(t)
+
+	path := writeHCL(t, `
+config {
+  url = "`+server.URL+`"
+}
+
+request "get_block_number" {
+  method = "eth_blockNumber"
+}
+
+request "get_block" {
+  method = "eth_getBlockByNumber"
+  params = [request.get_block_number.result]
+}
+
+request "echo" {
+  method = "echo"
+  params = [1]
+}
+`)
+
+	hclFile, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		parallelism int
+		batch       bool
+	}{
+		{name: "sequential", parallelism: 1},
+		{name: "parallel", parallelism: 3},
+		{name: "batch", parallelism: 1, batch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				finished []string
+			)
+
+			exec := New()
+			exec.SetParallelism(tt.parallelism)
+			exec.SetBatch(tt.batch)
+			exec.SetOnResult(func(result *types.ExecutionResult) {
+				mu.Lock()
+				defer mu.Unlock()
+				// A result is final when passed on; a dependent only runs after it
+				if result.Request.Name == "get_block" && !slices.Contains(finished, "get_block_number") {
+					t.Error("get_block finished before the request it depends on")
+				}
+				finished = append(finished, result.Request.Name)
+			})
+
+			results, err := exec.ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+			if err != nil {
+				t.Fatalf("ExecuteAll() error = %v", err)
+			}
+
+			if len(finished) != len(results) {
+				t.Fatalf("OnResult called %d times, want %d: %v", len(finished), len(results), finished)
+			}
+			for _, result := range results {
+				if !slices.Contains(finished, result.Request.Name) {
+					t.Errorf("OnResult not called for %s", result.Request.Name)
+				}
+			}
+		})
+	}
+}
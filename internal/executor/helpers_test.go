@@ -1,6 +1,8 @@
 package executor
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"jsonrpc/pkg/types"
@@ -99,3 +101,13 @@ func TestCountParams(t *testing.T) {
 		})
 	}
 }
+
+// writeHCL writes HCL source to a temporary file and returns its path
+func writeHCL(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "requests.hcl")
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatalf("failed to write HCL file: %v", err)
+	}
+	return path
+}
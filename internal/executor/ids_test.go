@@ -0,0 +1,285 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"jsonrpc/internal/parser"
+	"jsonrpc/pkg/types"
+)
+
+// newIDServer starts a JSON-RPC server that echoes the raw id of each call,
+// or replyID if set, and answers notifications with nothing. It records the
+// ids it received, with "-" for notifications.
+func newIDServer(t *testing.T, replyID string) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var seen []string
+
+	answer := func(raw json.RawMessage) map[string]any {
+		var call map[string]json.RawMessage
+		_ = json.Unmarshal(raw, &call)
+		id, ok := call["id"]
+
+		mu.Lock()
+		defer mu.Unlock()
+		if !ok {
+			seen = append(seen, "-")
+			return nil
+		}
+		seen = append(seen, string(id))
+		if replyID != "" {
+			id = json.RawMessage(replyID)
+		}
+		return map[string]any{"jsonrpc": "2.0", "result": call["method"], "id": id}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(r.Body)
+
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body.Bytes(), &batch); err != nil {
+			if response := answer(body.Bytes()); response != nil {
+				_ = json.NewEncoder(w).Encode(response)
+			} else {
+				w.WriteHeader(http.StatusNoContent)
+			}
+			return
+		}
+
+		var responses []map[string]any
+		for _, raw := range batch {
+			if response := answer(raw); response != nil {
+				responses = append(responses, response)
+			}
+		}
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(responses)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), seen...)
+	}
+}
+
+func TestExecutor_ExecuteAll_IDStrategy(t *testing.T) {
+	server, seen := newIDServer(t, "")
+
+	path := writeHCL(t, `
+config {
+  url         = "`+server.URL+`"
+  id_strategy = "prefix:call-"
+}
+
+request "first" {
+  method = "first"
+}
+
+request "ping" {
+  method       = "ping"
+  notification = true
+}
+
+request "fixed" {
+  method      = "fixed"
+  id_strategy = "fixed:42"
+}
+`)
+
+	hclFile, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	results, err := New().ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+
+	for _, result := range results {
+		if !result.IsSuccess() {
+			t.Fatalf("request '%s' failed: %v", result.Request.Name, result.Error)
+		}
+	}
+	if got := results[0].Response.IDKey(); got != `"call-1"` {
+		t.Errorf("first id = %s, want \"call-1\"", got)
+	}
+	if results[1].Response != nil {
+		t.Errorf("notification response = %+v, want nil", results[1].Response)
+	}
+	if got := results[2].Response.IDKey(); got != "42" {
+		t.Errorf("fixed id = %s, want 42", got)
+	}
+
+	want := []string{`"call-1"`, "-", "42"}
+	got := seen()
+	if len(got) != len(want) {
+		t.Fatalf("server saw ids %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("server saw ids %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestExecutor_ExecuteAll_MismatchedID(t *testing.T) {
+	server, _ := newIDServer(t, `"other"`)
+
+	path := writeHCL(t, `
+config {
+  url = "`+server.URL+`"
+}
+
+request "first" {
+  method = "first"
+}
+`)
+
+	hclFile, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	results, err := New().ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+
+	want := `response id "other" does not match request id 1`
+	if results[0].Error == nil || results[0].Error.Error() != want {
+		t.Errorf("error = %v, want %q", results[0].Error, want)
+	}
+}
+
+func TestExecutor_ExecuteAll_BatchNotifications(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want map[string]string // request name -> result, "" for notifications
+	}{
+		{
+			name: "mixed",
+			src: `
+request "ping" {
+  method       = "ping"
+  notification = true
+}
+
+request "first" {
+  method = "first"
+}
+`,
+			want: map[string]string{"ping": "", "first": `"first"`},
+		},
+		{
+			name: "notifications only",
+			src: `
+request "ping" {
+  method       = "ping"
+  notification = true
+}
+
+request "pong" {
+  method       = "pong"
+  notification = true
+}
+`,
+			want: map[string]string{"ping": "", "pong": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newIDServer(t, "")
+
+			hclFile, err := parser.New().ParseFile(writeHCL(t, `
+config {
+  url = "`+server.URL+`"
+}
+`+tt.src))
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+
+			exec := New()
+			exec.SetBatch(true)
+
+			results, err := exec.ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+			if err != nil {
+				t.Fatalf("ExecuteAll() error = %v", err)
+			}
+
+			for _, result := range results {
+				if !result.IsSuccess() {
+					t.Fatalf("request '%s' failed: %v", result.Request.Name, result.Error)
+				}
+				want := tt.want[result.Request.Name]
+				if want == "" {
+					if result.Response != nil {
+						t.Errorf("%s response = %+v, want nil", result.Request.Name, result.Response)
+					}
+					continue
+				}
+				if result.Response == nil || string(result.Response.Result) != want {
+					t.Errorf("%s response = %+v, want result %s", result.Request.Name, result.Response, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExecutor_ExecuteAll_BatchFixedID(t *testing.T) {
+	server, seen := newIDServer(t, "")
+
+	path := writeHCL(t, `
+config {
+  url         = "`+server.URL+`"
+  id_strategy = "fixed:1"
+}
+
+request "first" {
+  method = "first"
+}
+
+request "second" {
+  method = "second"
+}
+`)
+
+	hclFile, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	exec := New()
+	exec.SetBatch(true)
+
+	results, err := exec.ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+
+	for _, result := range results {
+		if result.Error == nil {
+			t.Errorf("request '%s' error = nil, want shared id error", result.Request.Name)
+		}
+	}
+	if got := seen(); len(got) != 0 {
+		t.Errorf("server saw ids %v, want no batch sent", got)
+	}
+}
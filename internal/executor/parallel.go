@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"context"
+	"sync"
+
+	"jsonrpc/pkg/types"
+)
+
+// executeParallel runs requests on a bounded worker pool. Each request starts
+// once the requests it depends on have finished and a worker slot is free.
+// Requests are numbered by their position, so JSON-RPC ids stay unique.
+// Once ctx is done, requests waiting for a slot are not run.
+func (e *Executor) executeParallel(
+	ctx context.Context,
+	hclFile *types.HCLFile,
+	ordered []*types.Request,
+	overrides *types.CLIOverrides,
+) ([]*types.ExecutionResult, error) {
+	results := make([]*types.ExecutionResult, len(ordered))
+	done := make(map[string]chan struct{}, len(ordered))
+	for _, req := range ordered {
+		done[req.Name] = make(chan struct{})
+	}
+
+	var (
+		mu            sync.Mutex
+		firstErr      error
+		resultsByName = make(map[string]*types.ExecutionResult, len(ordered))
+		slots         = make(chan struct{}, e.parallelism)
+		wg            sync.WaitGroup
+	)
+
+	for i, req := range ordered {
+		wg.Add(1)
+		go func(i int, req *types.Request) {
+			defer wg.Done()
+			defer close(done[req.Name])
+
+			// Dependencies come earlier in the order, so waiting cannot deadlock
+			dependencyResults := make(map[string]*types.ExecutionResult, len(req.DependsOn))
+			for _, name := range req.DependsOn {
+				<-done[name]
+				mu.Lock()
+				dependencyResults[name] = resultsByName[name]
+				mu.Unlock()
+			}
+
+			var result *types.ExecutionResult
+			var err error
+			select {
+			case slots <- struct{}{}:
+				result, err = e.executeResolved(ctx, hclFile, req, overrides, i+1, dependencyResults)
+				<-slots
+			case <-ctx.Done():
+				result = notRun(ctx, req)
+			}
+
+			if err != nil {
+				result = &types.ExecutionResult{Request: req, Error: err}
+			}
+			e.finished(result)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			results[i] = result
+			resultsByName[req.Name] = result
+		}(i, req)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
@@ -0,0 +1,184 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"jsonrpc/internal/parser"
+	"jsonrpc/pkg/types"
+)
+
+// newSlowServer starts a JSON-RPC server that echoes params after a delay and
+// records the highest number of requests it handled at the same time
+func newSlowServer(t *testing.T, delay time.Duration) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+
+		var req struct {
+			Params json.RawMessage `json:"params"`
+			ID     int             `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		time.Sleep(delay)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"result":  req.Params,
+			"id":      req.ID,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &maxInFlight
+}
+
+func TestExecutor_ExecuteAll_Parallel(t *testing.T) {
+	server, maxInFlight := newSlowServer(t, 50*time.Millisecond)
+
+	var src strings.Builder
+	fmt.Fprintf(&src, "config {\n  url = %q\n}\n", server.URL)
+	for i := 0; i < 12; i++ {
+		fmt.Fprintf(&src, "request \"call_%02d\" {\n  method = \"echo\"\n  params = [%d]\n}\n", i, i)
+	}
+
+	hclFile, err := parser.New().ParseFile(writeHCL(t, src.String()))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	exec := New()
+	exec.SetParallelism(4)
+
+	results, err := exec.ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+
+	if got := atomic.LoadInt32(maxInFlight); got < 2 || got > 4 {
+		t.Errorf("max concurrent requests = %d, want between 2 and 4", got)
+	}
+
+	ids := make(map[string]bool, len(results))
+	for i, result := range results {
+		if !result.IsSuccess() {
+			t.Fatalf("request '%s' failed: %v", result.Request.Name, result.Error)
+		}
+		if want := fmt.Sprintf("call_%02d", i); result.Request.Name != want {
+			t.Errorf("results[%d] = %s, want %s", i, result.Request.Name, want)
+		}
+		if want := fmt.Sprintf("[%d]", i); string(result.Response.Result) != want {
+			t.Errorf("results[%d] result = %s, want %s", i, result.Response.Result, want)
+		}
+
+		id := result.Response.IDKey()
+		if ids[id] {
+			t.Errorf("duplicate JSON-RPC id %s", id)
+		}
+		ids[id] = true
+	}
+}
+
+func TestExecutor_ExecuteAll_ParallelDependencies(t *testing.T) {
+	server := newEchoServer(t)
+
+	path := writeHCL(t, `
+config {
+  url = "`+server.URL+`"
+}
+
+request "get_block" {
+  method = "eth_getBlockByNumber"
+  params = [request.get_block_number.result, true]
+}
+
+request "get_block_number" {
+  method = "eth_blockNumber"
+}
+
+request "get_balance" {
+  method = "eth_getBalance"
+  params = ["0xabc", request.get_block_number.result]
+}
+`)
+
+	hclFile, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	exec := New()
+	exec.SetParallelism(8)
+
+	results, err := exec.ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+
+	want := map[string]string{
+		"get_block_number": `"0x10"`,
+		"get_block":        `["0x10",true]`,
+		"get_balance":      `["0xabc","0x10"]`,
+	}
+	for _, result := range results {
+		if !result.IsSuccess() {
+			t.Fatalf("request '%s' failed: %v", result.Request.Name, result.Error)
+		}
+		if got := string(result.Response.Result); got != want[result.Request.Name] {
+			t.Errorf("%s result = %s, want %s", result.Request.Name, got, want[result.Request.Name])
+		}
+	}
+}
+
+func TestManager_ConcurrentBuilds(t *testing.T) {
+	hclFile := &types.HCLFile{
+		Configs: map[string]*types.Config{
+			"default": {URL: "http://default", Headers: map[string]string{"X-Profile": "default"}},
+			"other":   {URL: "http://other", Headers: map[string]string{"X-Profile": "other"}},
+		},
+	}
+
+	exec := New()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			req := &types.Request{Name: "test"}
+			want := "http://default"
+			if i%2 == 0 {
+				req.Config = "other"
+				want = "http://other"
+			}
+
+			cfg := exec.configMgr.BuildForRequest(hclFile, req, nil)
+			if cfg.URL != want {
+				t.Errorf("BuildForRequest() URL = %s, want %s", cfg.URL, want)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"jsonrpc/internal/parser"
+	"jsonrpc/pkg/types"
+)
+
+func TestExecutor_ExecuteAll_RateLimit(t *testing.T) {
+	server := newEchoServer(t)
+
+	src := `
+config {
+  url        = "` + server.URL + `"
+  rate_limit = "2/s"
+}
+
+config "archive" {
+  url        = "` + server.URL + `"
+  rate_limit = "2/s"
+}
+
+request "a1" { method = "eth_blockNumber" }
+request "a2" { method = "eth_blockNumber" }
+request "a3" { method = "eth_blockNumber" }
+request "a4" { method = "eth_blockNumber" }
+request "a5" { method = "eth_blockNumber" }
+request "b1" {
+  config = "archive"
+  method = "eth_blockNumber"
+}
+request "b2" {
+  config = "archive"
+  method = "eth_blockNumber"
+}
+`
+
+	tests := []struct {
+		name        string
+		parallelism int
+		rate        string
+		wantDelays  int
+		wantLongest time.Duration
+	}{
+		// The default profile allows a burst of 2 and then one request per
+		// 500ms; the archive profile has its own bucket
+		{name: "sequential", parallelism: 1, wantDelays: 3, wantLongest: 1500 * time.Millisecond},
+		{name: "parallel", parallelism: 4, wantDelays: 3, wantLongest: 1500 * time.Millisecond},
+		// --rate is one bucket for the run, not one per profile
+		{name: "cli override", parallelism: 4, rate: "1/s", wantDelays: 6, wantLongest: 6 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hclFile, err := parser.New().ParseFile(writeHCL(t, src))
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+
+			var mu sync.Mutex
+			var delays []time.Duration
+			exec := New()
+			exec.SetParallelism(tt.parallelism)
+			exec.sleep = func(_ context.Context, d time.Duration) error {
+				mu.Lock()
+				defer mu.Unlock()
+				delays = append(delays, d)
+				return nil
+			}
+
+			overrides := types.NewCLIOverrides()
+			overrides.RateLimit = tt.rate
+
+			results, err := exec.ExecuteAll(context.Background(), hclFile, hclFile.Requests, overrides)
+			if err != nil {
+				t.Fatalf("ExecuteAll() error = %v", err)
+			}
+			for _, result := range results {
+				if !result.IsSuccess() {
+					t.Fatalf("request '%s' failed: %v", result.Request.Name, result.Error)
+				}
+			}
+
+			// Delays are reservations in a real-time bucket, so allow for the
+			// time spent sending requests
+			var longest time.Duration
+			for _, d := range delays {
+				longest = max(longest, d)
+			}
+			if len(delays) != tt.wantDelays {
+				t.Errorf("%d requests waited (%v), want %d", len(delays), delays, tt.wantDelays)
+			}
+			if longest > tt.wantLongest || longest < tt.wantLongest-200*time.Millisecond {
+				t.Errorf("longest delay = %v, want about %v", longest, tt.wantLongest)
+			}
+		})
+	}
+}
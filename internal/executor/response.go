@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"encoding/json"
+
+	"jsonrpc/pkg/types"
+)
+
+// borrowedJSON is raw JSON that refers to the data it was decoded from
+// instead of a copy of it, unlike json.RawMessage
+type borrowedJSON []byte
+
+// UnmarshalJSON implements json.Unmarshaler. encoding/json passes a slice
+// of the data being decoded; response payloads are never reused, so it
+// stays valid.
+func (b *borrowedJSON) UnmarshalJSON(data []byte) error {
+	*b = data
+	return nil
+}
+
+// wireResponse is a JSON-RPC response as decoded from a payload
+type wireResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  borrowedJSON    `json:"result"`
+	Error   *types.RPCError `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// response returns the decoded response
+func (w *wireResponse) response() *types.JSONRPCResponse {
+	return &types.JSONRPCResponse{
+		JSONRPC: w.JSONRPC,
+		Result:  json.RawMessage(w.Result),
+		Error:   w.Error,
+		ID:      w.ID,
+	}
+}
+
+// decodeResponse decodes a JSON-RPC response. The result shares the
+// payload's buffer, so a large result is held in memory once.
+func decodeResponse(data []byte) (*types.JSONRPCResponse, error) {
+	var wire wireResponse
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return nil, err
+	}
+	return wire.response(), nil
+}
+
+// decodeBatchResponse decodes the response array of a batch, leaving out
+// null elements. Results share the payload's buffer, see decodeResponse.
+func decodeBatchResponse(data []byte) ([]*types.JSONRPCResponse, error) {
+	var wires []*wireResponse
+	if err := json.Unmarshal(data, &wires); err != nil {
+		return nil, err
+	}
+
+	responses := make([]*types.JSONRPCResponse, 0, len(wires))
+	for _, wire := range wires {
+		if wire != nil {
+			responses = append(responses, wire.response())
+		}
+	}
+	return responses, nil
+}
@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantResult string
+		wantID     string
+		wantError  bool
+	}{
+		{name: "result", data: `{"jsonrpc":"2.0","result":{"n":1},"id":1}`, wantResult: `{"n":1}`, wantID: "1"},
+		{name: "null result", data: `{"jsonrpc":"2.0","result":null,"id":"a"}`, wantResult: "null", wantID: `"a"`},
+		{name: "error", data: `{"jsonrpc":"2.0","error":{"code":-32601,"message":"no"},"id":null}`, wantID: "null", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := decodeResponse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("decodeResponse() error = %v", err)
+			}
+			if string(resp.Result) != tt.wantResult {
+				t.Errorf("Result = %s, want %s", resp.Result, tt.wantResult)
+			}
+			if resp.IDKey() != tt.wantID {
+				t.Errorf("IDKey() = %s, want %s", resp.IDKey(), tt.wantID)
+			}
+			if resp.IsError() != tt.wantError {
+				t.Errorf("IsError() = %v, want %v", resp.IsError(), tt.wantError)
+			}
+		})
+	}
+
+	if _, err := decodeResponse([]byte(`{"result":`)); err == nil {
+		t.Error("decodeResponse() of invalid JSON error = nil, want an error")
+	}
+}
+
+func TestDecodeResponse_SharesPayload(t *testing.T) {
+	data := []byte(`{"jsonrpc":"2.0","result":"0x10","id":1}`)
+
+	resp, err := decodeResponse(data)
+	if err != nil {
+		t.Fatalf("decodeResponse() error = %v", err)
+	}
+
+	// The result is a slice of the payload, not a copy of it
+	start := bytes.Index(data, []byte(`"0x10"`))
+	if len(resp.Result) == 0 || &resp.Result[0] != &data[start] {
+		t.Error("decodeResponse() copied the result out of the payload")
+	}
+}
+
+func TestDecodeBatchResponse(t *testing.T) {
+	responses, err := decodeBatchResponse([]byte(`[{"jsonrpc":"2.0","result":1,"id":2},null,{"jsonrpc":"2.0","result":2,"id":1}]`))
+	if err != nil {
+		t.Fatalf("decodeBatchResponse() error = %v", err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("decodeBatchResponse() returned %d responses, want 2 (null left out)", len(responses))
+	}
+	if responses[0].IDKey() != "2" || string(responses[0].Result) != "1" {
+		t.Errorf("responses[0] = %+v, want result 1 for id 2", responses[0])
+	}
+}
@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"time"
+
+	"jsonrpc/internal/transport"
+	"jsonrpc/pkg/constants"
+	"jsonrpc/pkg/types"
+)
+
+// defaultRetryOnStatus lists the HTTP statuses retried when a retry block
+// does not set retry_on_status
+var defaultRetryOnStatus = []int{429, 500, 502, 503, 504}
+
+// retryPolicy is a retry block with defaults applied to unset settings
+type retryPolicy struct {
+	maxAttempts     int
+	backoffBase     time.Duration
+	backoffMax      time.Duration
+	jitter          bool
+	retryOnStatus   []int
+	retryOnCodes    []int
+	honorRetryAfter bool
+}
+
+// newRetryPolicy applies defaults to a merged retry block. Without a retry
+// block requests are sent once.
+func newRetryPolicy(retry *types.RetryPolicy) retryPolicy {
+	if retry == nil {
+		return retryPolicy{maxAttempts: 1}
+	}
+
+	policy := retryPolicy{
+		maxAttempts:     constants.DefaultRetryMaxAttempts,
+		backoffBase:     constants.DefaultRetryBackoffBaseMs * time.Millisecond,
+		backoffMax:      constants.DefaultRetryBackoffMaxMs * time.Millisecond,
+		jitter:          true,
+		retryOnStatus:   defaultRetryOnStatus,
+		retryOnCodes:    retry.RetryOnCodes,
+		honorRetryAfter: true,
+	}
+	if retry.MaxAttempts > 0 {
+		policy.maxAttempts = retry.MaxAttempts
+	}
+	if retry.BackoffBase > 0 {
+		policy.backoffBase = retry.BackoffBase
+	}
+	if retry.BackoffMax > 0 {
+		policy.backoffMax = retry.BackoffMax
+	}
+	if retry.Jitter != nil {
+		policy.jitter = *retry.Jitter
+	}
+	if retry.RetryOnStatus != nil {
+		policy.retryOnStatus = retry.RetryOnStatus
+	}
+	if retry.HonorRetryAfter != nil {
+		policy.honorRetryAfter = *retry.HonorRetryAfter
+	}
+
+	return policy
+}
+
+// shouldRetry reports whether a failed attempt is retried. HTTP errors are
+// retried for the configured statuses, JSON-RPC errors for the configured
+// codes, and other errors only if they are transient. Errors that would
+// happen again, such as invalid responses, auth or signing failures and
+// responses over the size limit, fail at once.
+func (p retryPolicy) shouldRetry(rpcErr *types.RPCError, err error) bool {
+	var httpErr *transport.HTTPError
+	switch {
+	case errors.As(err, &httpErr):
+		return slices.Contains(p.retryOnStatus, httpErr.StatusCode)
+	case err != nil:
+		return transient(err)
+	case rpcErr != nil:
+		return slices.Contains(p.retryOnCodes, rpcErr.Code)
+	default:
+		return false
+	}
+}
+
+// transient reports whether an error is a connection failure, a connection
+// closed mid-response or a timeout of the attempt
+func transient(err error) bool {
+	var opErr *net.OpError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return true
+	case errors.As(err, &opErr):
+		return true
+	case errors.As(err, &netErr):
+		// *url.Error is a net.Error whatever it wraps, e.g. a TLS
+		// certificate error, so only its timeouts count
+		return netErr.Timeout()
+	default:
+		return false
+	}
+}
+
+// delay returns how long to wait after the given failed attempt: an
+// exponential backoff capped at backoff_max, with "equal jitter" (a random
+// delay between half and all of it), or the server's Retry-After delay,
+// also capped at backoff_max
+func (p retryPolicy) delay(attempt int, err error) time.Duration {
+	var httpErr *transport.HTTPError
+	if p.honorRetryAfter && errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+		return min(httpErr.RetryAfter, p.backoffMax)
+	}
+
+	backoff := p.backoffBase
+	for i := 1; i < attempt && backoff < p.backoffMax; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, p.backoffMax)
+
+	if p.jitter && backoff > 1 {
+		half := backoff / 2
+		backoff = half + rand.N(backoff-half+1)
+	}
+	return backoff
+}
+
+// withRetry calls send until it succeeds, fails in a way the policy does
+// not retry, or the attempts are used up, and returns the number of
+// attempts made. send returns the JSON-RPC error of the response, if any,
+// and the error of the attempt. Nothing is retried once ctx is done.
+func (e *Executor) withRetry(
+	ctx context.Context,
+	config *types.EffectiveConfig,
+	send func() (*types.RPCError, error),
+) (int, error) {
+	policy := newRetryPolicy(config.Retry)
+
+	for attempt := 1; ; attempt++ {
+		rpcErr, err := send()
+		if attempt >= policy.maxAttempts || ctx.Err() != nil || !policy.shouldRetry(rpcErr, err) {
+			return attempt, err
+		}
+		if sleepErr := e.sleep(ctx, policy.delay(attempt, err)); sleepErr != nil {
+			return attempt, err
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package executor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"jsonrpc/internal/cassette"
+	"jsonrpc/internal/parser"
+	"jsonrpc/internal/transport"
+	"jsonrpc/pkg/types"
+)
+
+// failure describes how the flaky server answers one call: with an HTTP
+// status (and optional Retry-After header) or with a JSON-RPC error code
+type failure struct {
+	status     int
+	retryAfter string
+	rpcCode    int
+}
+
+// newFlakyServer starts a JSON-RPC server that answers the first calls with
+// the given failures and every later call with the result "0x10"
+func newFlakyServer(t *testing.T, failures []failure) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID int `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		call := int(atomic.AddInt32(&calls, 1))
+		response := map[string]any{"jsonrpc": "2.0", "result": "0x10", "id": req.ID}
+		if call <= len(failures) {
+			f := failures[call-1]
+			if f.status != 0 {
+				if f.retryAfter != "" {
+					w.Header().Set("Retry-After", f.retryAfter)
+				}
+				http.Error(w, http.StatusText(f.status), f.status)
+				return
+			}
+			delete(response, "result")
+			response["error"] = map[string]any{"code": f.rpcCode, "message": "failure"}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func TestExecutor_Execute_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		configRetry  string
+		requestRetry string
+		failures     []failure
+		wantAttempts int
+		wantSuccess  bool
+		wantDelays   []time.Duration
+	}{
+		{
+			name:         "no retry block",
+			failures:     []failure{{status: 503}},
+			wantAttempts: 1,
+		},
+		{
+			name:         "retries server errors",
+			configRetry:  `max_attempts = 3`,
+			failures:     []failure{{status: 503}, {status: 502}},
+			wantAttempts: 3,
+			wantSuccess:  true,
+		},
+		{
+			name:         "gives up after max_attempts",
+			configRetry:  `max_attempts = 2`,
+			failures:     []failure{{status: 503}, {status: 503}, {status: 503}},
+			wantAttempts: 2,
+		},
+		{
+			name:         "status not retried",
+			configRetry:  `max_attempts = 3`,
+			failures:     []failure{{status: 400}},
+			wantAttempts: 1,
+		},
+		{
+			name:         "retry_on_codes",
+			configRetry:  `retry_on_codes = [-32005]`,
+			failures:     []failure{{rpcCode: -32005}},
+			wantAttempts: 2,
+			wantSuccess:  true,
+		},
+		{
+			name:         "rpc error not listed",
+			configRetry:  `retry_on_codes = [-32005]`,
+			failures:     []failure{{rpcCode: -32601}},
+			wantAttempts: 1,
+		},
+		{
+			name:         "request overrides config",
+			configRetry:  `max_attempts = 1`,
+			requestRetry: `max_attempts = 3`,
+			failures:     []failure{{status: 429}, {status: 429}},
+			wantAttempts: 3,
+			wantSuccess:  true,
+		},
+		{
+			name:         "exponential backoff",
+			configRetry:  "max_attempts = 4\n backoff_base = \"100ms\"\n backoff_max = \"300ms\"\n jitter = false",
+			failures:     []failure{{status: 503}, {status: 503}, {status: 503}},
+			wantAttempts: 4,
+			wantSuccess:  true,
+			wantDelays:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond},
+		},
+		{
+			name:         "honors Retry-After",
+			configRetry:  `jitter = false`,
+			failures:     []failure{{status: 429, retryAfter: "7"}},
+			wantAttempts: 2,
+			wantSuccess:  true,
+			wantDelays:   []time.Duration{7 * time.Second},
+		},
+		{
+			name:         "ignores Retry-After",
+			configRetry:  "honor_retry_after = false\n jitter = false",
+			failures:     []failure{{status: 429, retryAfter: "7"}},
+			wantAttempts: 2,
+			wantSuccess:  true,
+			wantDelays:   []time.Duration{500 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := newFlakyServer(t, tt.failures)
+
+			src := "config {\n  url = \"" + server.URL + "\"\n"
+			if tt.configRetry != "" {
+				src += "  retry {\n " + tt.configRetry + "\n  }\n"
+			}
+			src += "}\n\nrequest \"get_block_number\" {\n  method = \"eth_blockNumber\"\n"
+			if tt.requestRetry != "" {
+				src += "  retry {\n " + tt.requestRetry + "\n  }\n"
+			}
+			src += "}\n"
+
+			hclFile, err := parser.New().ParseFile(writeHCL(t, src))
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+
+			var delays []time.Duration
+			exec := New()
+			exec.sleep = func(_ context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+
+			result, err := exec.Execute(context.Background(), hclFile, hclFile.Requests[0], types.NewCLIOverrides(), 1)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if result.Attempts != tt.wantAttempts || int(atomic.LoadInt32(calls)) != tt.wantAttempts {
+				t.Errorf("attempts = %d (%d calls), want %d", result.Attempts, atomic.LoadInt32(calls), tt.wantAttempts)
+			}
+			if result.IsSuccess() != tt.wantSuccess {
+				t.Errorf("IsSuccess() = %v, want %v (error: %v)", result.IsSuccess(), tt.wantSuccess, result.Error)
+			}
+			if tt.wantDelays != nil && !slices.Equal(delays, tt.wantDelays) {
+				t.Errorf("delays = %v, want %v", delays, tt.wantDelays)
+			}
+		})
+	}
+}
+
+func TestExecutor_ExecuteAll_BatchRetry(t *testing.T) {
+	server, calls := newFlakyServer(t, []failure{{status: 503}})
+
+	hclFile, err := parser.New().ParseFile(writeHCL(t, `
+config {
+  url = "`+server.URL+`"
+  retry {
+    max_attempts = 2
+  }
+}
+
+request "a" {
+  method = "eth_blockNumber"
+}
+`))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	exec := New()
+	exec.sleep = func(context.Context, time.Duration) error { return nil }
+	exec.SetBatch(true)
+
+	results, err := exec.ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+	// The server answers the batch with a single response object
+	if results[0].Attempts != 2 || atomic.LoadInt32(calls) != 2 {
+		t.Errorf("attempts = %d (%d calls), want 2", results[0].Attempts, atomic.LoadInt32(calls))
+	}
+}
+
+func TestExecutor_Execute_MaxResponseSizeNotRetried(t *testing.T) {
+	server, calls := newFlakyServer(t, nil)
+
+	hclFile, err := parser.New().ParseFile(writeHCL(t, `
+config {
+  url               = "`+server.URL+`"
+  max_response_size = "16B"
+  retry {
+    max_attempts = 3
+  }
+}
+
+request "get_block_number" {
+  method = "eth_blockNumber"
+}
+`))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	exec := New()
+	exec.sleep = func(context.Context, time.Duration) error { return nil }
+
+	result, err := exec.Execute(context.Background(), hclFile, hclFile.Requests[0], types.NewCLIOverrides(), 1)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !errors.Is(result.Error, transport.ErrResponseTooLarge) {
+		t.Errorf("error = %v, want %v", result.Error, transport.ErrResponseTooLarge)
+	}
+	if result.Attempts != 1 || atomic.LoadInt32(calls) != 1 {
+		t.Errorf("attempts = %d (%d calls), want 1", result.Attempts, atomic.LoadInt32(calls))
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	jitter := true
+	policy := newRetryPolicy(&types.RetryPolicy{
+		BackoffBase: 100 * time.Millisecond,
+		BackoffMax:  time.Second,
+		Jitter:      &jitter,
+	})
+
+	for i := 0; i < 100; i++ {
+		delay := policy.delay(3, nil)
+		if delay < 200*time.Millisecond || delay > 400*time.Millisecond {
+			t.Fatalf("delay(3) = %v, want between 200ms and 400ms", delay)
+		}
+	}
+
+	if delay := policy.delay(10, nil); delay > time.Second {
+		t.Errorf("delay(10) = %v, want at most backoff_max", delay)
+	}
+
+	httpErr := &transport.HTTPError{StatusCode: 429, RetryAfter: 500 * time.Millisecond}
+	if delay := policy.delay(1, httpErr); delay != 500*time.Millisecond {
+		t.Errorf("delay with Retry-After = %v, want 500ms", delay)
+	}
+
+	// A server cannot stall the run beyond backoff_max
+	httpErr = &transport.HTTPError{StatusCode: 429, RetryAfter: time.Hour}
+	if delay := policy.delay(1, httpErr); delay != time.Second {
+		t.Errorf("delay with Retry-After of an hour = %v, want backoff_max of 1s", delay)
+	}
+}
+
+func TestNewRetryPolicy_Defaults(t *testing.T) {
+	if policy := newRetryPolicy(nil); policy.maxAttempts != 1 {
+		t.Errorf("maxAttempts without retry block = %d, want 1", policy.maxAttempts)
+	}
+
+	policy := newRetryPolicy(&types.RetryPolicy{})
+	if policy.maxAttempts != 3 || !policy.jitter || !policy.honorRetryAfter {
+		t.Errorf("defaults = %+v", policy)
+	}
+	if !policy.shouldRetry(nil, &transport.HTTPError{StatusCode: 429}) {
+		t.Error("429 should be retried by default")
+	}
+	if policy.shouldRetry(&types.RPCError{Code: -32000}, nil) {
+		t.Error("JSON-RPC errors should not be retried by default")
+	}
+	if policy.backoffMax != 10*time.Second {
+		t.Errorf("backoffMax = %v, want 10s", policy.backoffMax)
+	}
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	policy := newRetryPolicy(&types.RetryPolicy{RetryOnCodes: []int{-32005}})
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	tests := []struct {
+		name   string
+		rpcErr *types.RPCError
+		err    error
+		want   bool
+	}{
+		{name: "retried status", err: &transport.HTTPError{StatusCode: 503}, want: true},
+		{name: "other status", err: &transport.HTTPError{StatusCode: 400}},
+		{name: "retried code", rpcErr: &types.RPCError{Code: -32005}, want: true},
+		{name: "other code", rpcErr: &types.RPCError{Code: -32601}},
+		{name: "connection refused", err: fmt.Errorf("HTTP request failed: %w",
+			&url.Error{Op: "Post", URL: "http://localhost", Err: refused}), want: true},
+		{name: "attempt timeout", err: fmt.Errorf("HTTP request failed: %w",
+			&url.Error{Op: "Post", URL: "http://localhost", Err: context.DeadlineExceeded}), want: true},
+		{name: "connection closed mid-response", err: fmt.Errorf("failed to read response: %w", io.ErrUnexpectedEOF),
+			want: true},
+		{name: "TLS certificate error", err: &url.Error{Op: "Post", URL: "https://localhost",
+			Err: &tls.CertificateVerificationError{Err: errors.New("unknown authority")}}},
+		{name: "invalid response", err: fmt.Errorf("failed to parse JSON-RPC response: %w", &json.SyntaxError{})},
+		{name: "id mismatch", err: errors.New("response id 2 does not match request id 1")},
+		{name: "auth failure", err: errors.New("failed to get OAuth2 token: invalid_client")},
+		{name: "response too large", err: fmt.Errorf("%w of 1KB", transport.ErrResponseTooLarge)},
+		{name: "not in cassette", err: fmt.Errorf("%w for eth_chainId null", cassette.ErrNotRecorded)},
+		{name: "id in use", err: fmt.Errorf("%w: 1", transport.ErrIDInUse)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.shouldRetry(tt.rpcErr, tt.err); got != tt.want {
+				t.Errorf("shouldRetry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
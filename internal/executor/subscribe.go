@@ -0,0 +1,190 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"jsonrpc/internal/transport"
+	"jsonrpc/pkg/types"
+)
+
+// Subscribe sends a subscription's subscribe call and passes every
+// notification for it to onNotification until ctx is done or the connection
+// closes. The subscription is cancelled with its unsubscribe method before
+// Subscribe returns. Cancelling ctx is the normal way to stop watching and
+// does not return an error.
+func (e *Executor) Subscribe(
+	ctx context.Context,
+	hclFile *types.HCLFile,
+	subscription *types.Subscription,
+	overrides *types.CLIOverrides,
+	onNotification func(*types.Notification),
+) error {
+	defer func() {
+		_ = e.Close()
+	}()
+
+	config := e.configMgr.BuildForRequest(hclFile, subscription.Request, overrides)
+	if !config.HasEndpoint() {
+		return fmt.Errorf("no URL or command configured for subscription '%s'", subscription.Name)
+	}
+	timeout := time.Duration(config.Timeout) * time.Second
+
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	config, err := e.authorize(dialCtx, config)
+	if err != nil {
+		cancel()
+		return err
+	}
+	t, err := e.transports.Get(dialCtx, config)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	streamer, ok := t.(transport.Streamer)
+	if !ok {
+		return fmt.Errorf("subscription '%s' needs a persistent connection "+
+			"(ws://, wss://, ipc://, unix:// or a command), got %s", subscription.Name, config.Endpoint())
+	}
+	notifications := streamer.Notifications()
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	request := subscription.Request
+	subscriptionID, err := call(callCtx, streamer, config, request.Method, request.ProcessedParams, 1)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+	if len(subscriptionID) == 0 || string(subscriptionID) == "null" {
+		return fmt.Errorf("failed to subscribe: %s returned no subscription id", subscription.Request.Method)
+	}
+
+	sequence := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return unsubscribe(streamer, config, subscription, subscriptionID, notifications)
+
+		case data, open := <-notifications:
+			if !open {
+				return fmt.Errorf("connection closed while watching '%s'", subscription.Name)
+			}
+			if ctx.Err() != nil {
+				return unsubscribe(streamer, config, subscription, subscriptionID, notifications)
+			}
+
+			var message struct {
+				Params struct {
+					Subscription json.RawMessage `json:"subscription"`
+					Result       json.RawMessage `json:"result"`
+				} `json:"params"`
+			}
+			if err := json.Unmarshal(data, &message); err != nil {
+				continue
+			}
+			if compactJSON(message.Params.Subscription) != compactJSON(subscriptionID) {
+				continue
+			}
+
+			sequence++
+			onNotification(&types.Notification{
+				Subscription:   subscription.Name,
+				SubscriptionID: displayID(subscriptionID),
+				Sequence:       sequence,
+				Received:       time.Now(),
+				Result:         message.Params.Result,
+			})
+		}
+	}
+}
+
+// unsubscribe cancels a subscription. Notifications that are still arriving
+// are discarded meanwhile so that the connection keeps reading.
+func unsubscribe(
+	streamer transport.Streamer,
+	config *types.EffectiveConfig,
+	subscription *types.Subscription,
+	subscriptionID json.RawMessage,
+	notifications <-chan []byte,
+) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case _, open := <-notifications:
+				if !open {
+					return
+				}
+			}
+		}
+	}()
+
+	var id any
+	if err := json.Unmarshal(subscriptionID, &id); err != nil {
+		return fmt.Errorf("invalid subscription id %s: %w", subscriptionID, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Timeout)*time.Second)
+	defer cancel()
+
+	if _, err := call(ctx, streamer, config, subscription.Unsubscribe, []any{id}, 2); err != nil {
+		return fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+	return nil
+}
+
+// call sends a single JSON-RPC call and returns its result, turning RPC
+// errors into errors
+func call(
+	ctx context.Context,
+	t transport.Transport,
+	config *types.EffectiveConfig,
+	method string,
+	params any,
+	requestID int,
+) (json.RawMessage, error) {
+	payload, err := json.Marshal(types.NewJSONRPCRequest(method, params, requestID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	respBody, err := t.RoundTrip(ctx, config, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp types.JSONRPCResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON-RPC response: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("RPC error %d: %s", resp.Error.Code, resp.Error.Message)
+	}
+
+	return resp.Result, nil
+}
+
+// compactJSON normalizes a JSON value for comparison
+func compactJSON(data json.RawMessage) string {
+	var b bytes.Buffer
+	if err := json.Compact(&b, data); err != nil {
+		return string(data)
+	}
+	return b.String()
+}
+
+// displayID renders a subscription id, without quotes for string ids
+func displayID(data json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return s
+	}
+	return compactJSON(data)
+}
@@ -0,0 +1,180 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1" // #nosec G505 - required by the WebSocket handshake
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"jsonrpc/internal/parser"
+	"jsonrpc/pkg/types"
+)
+
+// newSubscriptionServer starts a WebSocket JSON-RPC server. eth_subscribe
+// returns "0xsub" followed by notifications for "0xsub" interleaved with
+// notifications for another subscription; eth_unsubscribe sets unsubscribed.
+func newSubscriptionServer(t *testing.T, unsubscribed *int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11")) // #nosec G401
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+		_ = rw.Flush()
+
+		send := func(message string) {
+			frame := []byte{0x81}
+			if len(message) < 126 {
+				frame = append(frame, byte(len(message)))
+			} else {
+				frame = append(frame, 126)
+				frame = binary.BigEndian.AppendUint16(frame, uint16(len(message)))
+			}
+			_, _ = conn.Write(append(frame, message...))
+		}
+
+		reader := bufio.NewReader(rw)
+		for {
+			payload, opcode, err := readClientFrame(reader)
+			if err != nil || opcode == 0x8 {
+				return
+			}
+
+			var call struct {
+				Method string `json:"method"`
+				ID     int    `json:"id"`
+			}
+			if err := json.Unmarshal(payload, &call); err != nil {
+				continue
+			}
+
+			switch call.Method {
+			case "eth_subscribe":
+				send(`{"jsonrpc":"2.0","result":"0xsub","id":` + strconv.Itoa(call.ID) + `}`)
+				for i := 1; i <= 5; i++ {
+					send(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xother","result":0}}`)
+					send(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xsub","result":{"number":` +
+						strconv.Itoa(i) + `}}}`)
+				}
+			case "eth_unsubscribe":
+				atomic.StoreInt32(unsubscribed, 1)
+				send(`{"jsonrpc":"2.0","result":true,"id":` + strconv.Itoa(call.ID) + `}`)
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// readClientFrame reads a masked client frame
+func readClientFrame(r *bufio.Reader) ([]byte, byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, 0, err
+	}
+
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return nil, 0, err
+		}
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+
+	var key [4]byte
+	if _, err := io.ReadFull(r, key[:]); err != nil {
+		return nil, 0, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, 0, err
+	}
+	for i := range payload {
+		payload[i] ^= key[i%4]
+	}
+
+	return payload, header[0] & 0x0F, nil
+}
+
+func TestExecutor_Subscribe(t *testing.T) {
+	var unsubscribed int32
+	server := newSubscriptionServer(t, &unsubscribed)
+
+	path := writeHCL(t, `
+subscribe "new_heads" {
+  method = "eth_subscribe"
+  params = ["newHeads"]
+  url    = "ws`+strings.TrimPrefix(server.URL, "http")+`"
+}
+`)
+
+	hclFile, err := parser.New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var notifications []*types.Notification
+	err = New().Subscribe(ctx, hclFile, hclFile.Subscriptions[0], types.NewCLIOverrides(),
+		func(notification *types.Notification) {
+			notifications = append(notifications, notification)
+			if len(notifications) == 3 {
+				cancel()
+			}
+		})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	if len(notifications) != 3 {
+		t.Fatalf("received %d notifications, want 3", len(notifications))
+	}
+	for i, notification := range notifications {
+		if notification.SubscriptionID != "0xsub" || notification.Sequence != i+1 {
+			t.Errorf("notification %d = %+v", i, notification)
+		}
+		if want := `{"number":` + strconv.Itoa(i+1) + `}`; string(notification.Result) != want {
+			t.Errorf("notification %d result = %s, want %s", i, notification.Result, want)
+		}
+	}
+
+	if atomic.LoadInt32(&unsubscribed) != 1 {
+		t.Error("eth_unsubscribe was not sent")
+	}
+}
+
+func TestExecutor_Subscribe_RequiresPersistentConnection(t *testing.T) {
+	hclFile := types.NewHCLFile()
+	subscription := &types.Subscription{
+		Name:        "new_heads",
+		Request:     &types.Request{Name: "new_heads", Method: "eth_subscribe", URL: "http://127.0.0.1:1"},
+		Unsubscribe: "eth_unsubscribe",
+	}
+
+	err := New().Subscribe(context.Background(), hclFile, subscription, nil, func(*types.Notification) {})
+	if err == nil || !strings.Contains(err.Error(), "needs a persistent connection") {
+		t.Errorf("Subscribe() error = %v, want persistent connection error", err)
+	}
+}
@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"jsonrpc/internal/parser"
+	"jsonrpc/pkg/types"
+)
+
+func TestExecutor_ExecuteAll_Trace(t *testing.T) {
+	echo := newEchoServer(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Fail") != "" {
+			w.Header().Set("X-Reason", "maintenance")
+			http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		echo.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	hclFile, err := parser.New().ParseFile(writeHCL(t, `
+config {
+  url     = "`+server.URL+`"
+  headers = { Authorization = "Bearer abc" }
+}
+
+request "ok" {
+  method = "eth_blockNumber"
+}
+
+request "unavailable" {
+  method  = "eth_blockNumber"
+  headers = { X-Fail = "1" }
+}
+`))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	exec := New()
+	exec.SetTrace(true)
+	results, err := exec.ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+
+	trace := results[0].Trace
+	if trace == nil {
+		t.Fatal("ok has no trace")
+	}
+	if trace.Request.Method != "POST" || trace.Request.URL != server.URL {
+		t.Errorf("request line = %s %s", trace.Request.Method, trace.Request.URL)
+	}
+	if trace.Request.Headers["Authorization"] != "Bearer abc" || trace.Request.Headers["Content-Type"] == "" {
+		t.Errorf("request headers = %v", trace.Request.Headers)
+	}
+	if !strings.Contains(trace.Request.Body, `"method":"eth_blockNumber"`) {
+		t.Errorf("request body = %s", trace.Request.Body)
+	}
+	if trace.Response == nil || trace.Response.StatusCode != http.StatusOK || !strings.Contains(string(trace.Response.Body), `"0x10"`) {
+		t.Fatalf("response = %+v", trace.Response)
+	}
+	if trace.Timings.TimeToFirstByte <= 0 || trace.Timings.Total < trace.Timings.TimeToFirstByte {
+		t.Errorf("timings = %+v", trace.Timings)
+	}
+
+	// HTTP errors keep the raw response
+	trace = results[1].Trace
+	if results[1].Error == nil || trace == nil || trace.Response == nil {
+		t.Fatalf("unavailable: error = %v, trace = %+v", results[1].Error, trace)
+	}
+	if trace.Response.StatusCode != http.StatusServiceUnavailable || trace.Response.Headers["X-Reason"] != "maintenance" {
+		t.Errorf("unavailable response = %+v", trace.Response)
+	}
+}
+
+func TestExecutor_ExecuteAll_NoTraceByDefault(t *testing.T) {
+	server := newEchoServer(t)
+
+	hclFile, err := parser.New().ParseFile(writeHCL(t, `
+request "ok" {
+  url    = "`+server.URL+`"
+  method = "eth_blockNumber"
+}
+`))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	results, err := New().ExecuteAll(context.Background(), hclFile, hclFile.Requests, types.NewCLIOverrides())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+	if results[0].Trace != nil {
+		t.Errorf("Trace = %+v, want nil", results[0].Trace)
+	}
+}
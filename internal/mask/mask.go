@@ -1,4 +1,5 @@
-package output
+// Package mask hides the values of sensitive headers such as credentials.
+package mask
 
 import "strings"
 
@@ -20,6 +21,7 @@ func NewSensitiveMasker() *SensitiveMasker {
 			"secret",
 			"password",
 			"bearer",
+			"cookie",
 		},
 	}
 }
@@ -1,4 +1,4 @@
-package output
+package mask
 
 import (
 	"testing"
@@ -37,6 +37,11 @@ func TestSensitiveMasker_IsSensitive(t *testing.T) {
 			headerName: "X-Password",
 			want:       true,
 		},
+		{
+			name:       "set-cookie header",
+			headerName: "Set-Cookie",
+			want:       true,
+		},
 		{
 			name:       "secret header",
 			headerName: "X-Secret",
@@ -0,0 +1,169 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"jsonrpc/pkg/constants"
+	"jsonrpc/pkg/types"
+)
+
+// histogramWidth is the length of the longest histogram bar
+const histogramWidth = 40
+
+// FormatBenchReport prints the throughput, failures and latency of a bench run
+func (f *Formatter) FormatBenchReport(report *types.BenchReport, jsonOutput bool) {
+	if jsonOutput {
+		f.formatBenchReportJSON(report)
+		return
+	}
+
+	mode := "closed-loop"
+	if report.Rate != "" {
+		mode = "open-loop at " + report.Rate
+	}
+	fmt.Printf("Concurrency: %d, %s\n", report.Concurrency, mode)
+	interrupted := ""
+	if report.Interrupted {
+		interrupted = " (interrupted)"
+	}
+	fmt.Printf("Duration:    %.2fs%s\n", report.Elapsed.Seconds(), interrupted)
+	fmt.Printf("Requests:    %d total, %d successful, %d failed\n",
+		report.Total, report.Succeeded, report.Failed())
+	fmt.Printf("Throughput:  %.1f req/s\n", report.Throughput(report.Elapsed))
+
+	if report.Succeeded > 0 {
+		latency := report.Latency
+		fmt.Printf("\nLatency (successful requests):\n")
+		fmt.Printf("  min %s  mean %s  p50 %s  p90 %s  p99 %s  max %s\n",
+			formatMilliseconds(latency.Min), formatMilliseconds(latency.Mean),
+			formatMilliseconds(latency.P50), formatMilliseconds(latency.P90),
+			formatMilliseconds(latency.P99), formatMilliseconds(latency.Max))
+
+		fmt.Printf("\nHistogram:\n")
+		printHistogram(report.Histogram)
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Printf("\nErrors:\n")
+		for _, failure := range report.Errors {
+			fmt.Printf("  %-16s %8d  %s\n", benchErrorLabel(failure), failure.Count,
+				truncate(failure.Message, constants.BoxContentWidth-28))
+		}
+	}
+
+	if len(report.PerRequest) > 0 {
+		fmt.Printf("\n%-25s %9s %7s %10s %10s %10s %10s\n",
+			"REQUEST", "REQUESTS", "ERRORS", "P50", "P90", "P99", "MAX")
+		fmt.Println(strings.Repeat("-", 87))
+		for _, stats := range report.PerRequest {
+			fmt.Printf("%-25s %9d %7d %10s %10s %10s %10s\n",
+				truncate(stats.Name, constants.MaxNameLength), stats.Total, stats.Failed(),
+				formatMilliseconds(stats.Latency.P50), formatMilliseconds(stats.Latency.P90),
+				formatMilliseconds(stats.Latency.P99), formatMilliseconds(stats.Latency.Max))
+		}
+	}
+}
+
+// printHistogram prints one bar per histogram bucket, scaled to the largest
+func printHistogram(buckets []types.HistogramBucket) {
+	largest := 0
+	for _, bucket := range buckets {
+		largest = max(largest, bucket.Count)
+	}
+
+	for _, bucket := range buckets {
+		width := 0
+		if largest > 0 {
+			width = bucket.Count * histogramWidth / largest
+		}
+		if width == 0 && bucket.Count > 0 {
+			width = 1
+		}
+		fmt.Printf("  <= %9s  %-*s %d\n",
+			formatMilliseconds(bucket.UpperBound), histogramWidth, strings.Repeat("■", width), bucket.Count)
+	}
+}
+
+// benchErrorLabel returns the kind and code of a failure, e.g. "HTTP 429"
+func benchErrorLabel(failure types.BenchError) string {
+	switch failure.Kind {
+	case "http":
+		return fmt.Sprintf("HTTP %d", failure.Code)
+	case "rpc":
+		return fmt.Sprintf("RPC %d", failure.Code)
+	default:
+		return failure.Kind
+	}
+}
+
+// formatBenchReportJSON prints a bench report in JSON format, with
+// durations in fractional milliseconds
+func (f *Formatter) formatBenchReportJSON(report *types.BenchReport) {
+	output := benchStatsJSON(report.BenchStats, report.Elapsed)
+	output["duration_ms"] = milliseconds(report.Elapsed)
+	output["concurrency"] = report.Concurrency
+	if report.Rate != "" {
+		output["rate"] = report.Rate
+	}
+	if report.Interrupted {
+		output["interrupted"] = true
+	}
+	if len(report.PerRequest) > 0 {
+		perRequest := make([]map[string]any, 0, len(report.PerRequest))
+		for _, stats := range report.PerRequest {
+			perRequest = append(perRequest, benchStatsJSON(stats, report.Elapsed))
+		}
+		output["per_request"] = perRequest
+	}
+
+	jsonBytes, _ := json.MarshalIndent(output, "", "  ")
+	fmt.Println(string(jsonBytes))
+}
+
+// benchStatsJSON returns the JSON fields of a set of bench stats
+func benchStatsJSON(stats types.BenchStats, elapsed time.Duration) map[string]any {
+	failures := make([]map[string]any, 0, len(stats.Errors))
+	for _, failure := range stats.Errors {
+		entry := map[string]any{
+			"kind":    failure.Kind,
+			"count":   failure.Count,
+			"message": failure.Message,
+		}
+		if failure.Kind == "http" || failure.Kind == "rpc" {
+			entry["code"] = failure.Code
+		}
+		failures = append(failures, entry)
+	}
+
+	histogram := make([]map[string]any, 0, len(stats.Histogram))
+	for _, bucket := range stats.Histogram {
+		histogram = append(histogram, map[string]any{
+			"le_ms": milliseconds(bucket.UpperBound),
+			"count": bucket.Count,
+		})
+	}
+
+	output := map[string]any{
+		"requests":   stats.Total,
+		"successful": stats.Succeeded,
+		"failed":     stats.Failed(),
+		"throughput": stats.Throughput(elapsed),
+		"errors":     failures,
+		"latency_ms": map[string]any{
+			"min":  milliseconds(stats.Latency.Min),
+			"mean": milliseconds(stats.Latency.Mean),
+			"p50":  milliseconds(stats.Latency.P50),
+			"p90":  milliseconds(stats.Latency.P90),
+			"p99":  milliseconds(stats.Latency.P99),
+			"max":  milliseconds(stats.Latency.Max),
+		},
+		"histogram": histogram,
+	}
+	if stats.Name != "" {
+		output["request"] = stats.Name
+	}
+	return output
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"jsonrpc/pkg/constants"
+	"jsonrpc/pkg/types"
+)
+
+// diffSymbols mark each kind of change in text output
+var diffSymbols = map[types.DiffChangeKind]string{
+	types.DiffAdded:   "+",
+	types.DiffRemoved: "-",
+	types.DiffChanged: "~",
+}
+
+// FormatDiffResults prints the comparison of each request's responses
+// under two configs
+func (f *Formatter) FormatDiffResults(fromConfig, toConfig string, diffs []types.DiffResult, jsonOutput bool) {
+	if jsonOutput {
+		f.formatDiffResultsJSON(fromConfig, toConfig, diffs)
+		return
+	}
+
+	fmt.Printf("Comparing %s (-) with %s (+)\n\n", fromConfig, toConfig)
+
+	same, different, failed := 0, 0, 0
+	for _, diff := range diffs {
+		switch diff.Status {
+		case types.DiffSame:
+			same++
+			fmt.Printf("✓ %s\n", diff.Request)
+
+		case types.DiffDifferent:
+			different++
+			fmt.Printf("✗ %s: %d difference(s)\n", diff.Request, len(diff.Changes))
+			for _, change := range diff.Changes {
+				fmt.Printf("    %s %s\n", diffSymbols[change.Kind], changeLabel(change))
+			}
+
+		case types.DiffFailed:
+			failed++
+			fmt.Printf("✗ %s: failed\n", diff.Request)
+			for _, message := range diff.Errors {
+				fmt.Printf("    %s\n", message)
+			}
+		}
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Printf("Summary: %d requests, %d same, %d different, %d failed\n",
+		len(diffs), same, different, failed)
+}
+
+// changeLabel returns the path and values of a change
+func changeLabel(change types.DiffChange) string {
+	switch change.Kind {
+	case types.DiffAdded:
+		return fmt.Sprintf("%s: %s", change.Path, diffValue(change.To))
+	case types.DiffRemoved:
+		return fmt.Sprintf("%s: %s", change.Path, diffValue(change.From))
+	default:
+		return fmt.Sprintf("%s: %s → %s", change.Path, diffValue(change.From), diffValue(change.To))
+	}
+}
+
+// diffValue renders a value as compact JSON, truncated to fit a line
+func diffValue(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return truncate(string(data), constants.BoxContentWidth-16)
+}
+
+// formatDiffResultsJSON formats diff results in JSON format
+func (f *Formatter) formatDiffResultsJSON(fromConfig, toConfig string, diffs []types.DiffResult) {
+	requests := make([]map[string]any, 0, len(diffs))
+	for _, diff := range diffs {
+		diffMap := map[string]any{
+			"request": diff.Request,
+			"status":  diff.Status,
+		}
+		if len(diff.Changes) > 0 {
+			changes := make([]map[string]any, 0, len(diff.Changes))
+			for _, change := range diff.Changes {
+				changeMap := map[string]any{
+					"path": change.Path,
+					"kind": change.Kind,
+				}
+				if change.Kind != types.DiffAdded {
+					changeMap["from"] = change.From
+				}
+				if change.Kind != types.DiffRemoved {
+					changeMap["to"] = change.To
+				}
+				changes = append(changes, changeMap)
+			}
+			diffMap["changes"] = changes
+		}
+		if len(diff.Errors) > 0 {
+			diffMap["errors"] = diff.Errors
+		}
+		requests = append(requests, diffMap)
+	}
+
+	output := map[string]any{
+		"from":     fromConfig,
+		"to":       toConfig,
+		"requests": requests,
+	}
+
+	jsonBytes, _ := json.MarshalIndent(output, "", "  ")
+	fmt.Println(string(jsonBytes))
+}
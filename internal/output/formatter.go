@@ -1,10 +1,16 @@
 package output
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"sync"
 
+	"jsonrpc/internal/mask"
 	"jsonrpc/pkg/config"
 	"jsonrpc/pkg/constants"
 	"jsonrpc/pkg/types"
@@ -12,13 +18,17 @@ import (
 
 // Formatter handles output formatting
 type Formatter struct {
-	masker *SensitiveMasker
+	masker *mask.SensitiveMasker
+
+	outputDir     string
+	resultFiles   map[*types.ExecutionResult]resultFile
+	resultFilesMu sync.Mutex
 }
 
 // New creates a new Formatter instance
 func New() *Formatter {
 	return &Formatter{
-		masker: NewSensitiveMasker(),
+		masker: mask.NewSensitiveMasker(),
 	}
 }
 
@@ -44,6 +54,31 @@ func (f *Formatter) FormatRequestList(
 			paramCount,
 		)
 	}
+
+	if len(hclFile.Batches) > 0 {
+		fmt.Println()
+		fmt.Printf("%-25s %s\n", "BATCH", "REQUESTS")
+		fmt.Println(strings.Repeat("-", 85))
+		for _, batch := range hclFile.Batches {
+			fmt.Printf("%-25s %s\n",
+				truncate(batch.Name, constants.MaxNameLength),
+				strings.Join(batch.Requests, ", "),
+			)
+		}
+	}
+
+	if len(hclFile.Subscriptions) > 0 {
+		fmt.Println()
+		fmt.Printf("%-25s %-30s %s\n", "SUBSCRIPTION", "METHOD", "UNSUBSCRIBE")
+		fmt.Println(strings.Repeat("-", 85))
+		for _, subscription := range hclFile.Subscriptions {
+			fmt.Printf("%-25s %-30s %s\n",
+				truncate(subscription.Name, constants.MaxNameLength),
+				truncate(subscription.Request.Method, constants.MaxMethodLength),
+				subscription.Unsubscribe,
+			)
+		}
+	}
 }
 
 // FormatRequestDetailed formats requests in detailed boxed format
@@ -80,8 +115,12 @@ func (f *Formatter) formatSingleRequestDetailed(
 	// Method
 	fmt.Printf("│ Method:  %-67s │\n", req.Method)
 
-	// URL
-	fmt.Printf("│ URL:     %-67s │\n", truncate(config.URL, constants.BoxContentWidth-9))
+	// Endpoint
+	if len(config.Command) > 0 {
+		fmt.Printf("│ Command: %-67s │\n", truncate(config.Endpoint(), constants.BoxContentWidth-9))
+	} else {
+		fmt.Printf("│ URL:     %-67s │\n", truncate(config.URL, constants.BoxContentWidth-9))
+	}
 
 	// Config
 	fmt.Printf("│ Config:  %-67s │\n", configName)
@@ -99,8 +138,15 @@ func (f *Formatter) formatSingleRequestDetailed(
 		}
 	}
 
+	// Dependencies (if any)
+	if len(req.DependsOn) > 0 {
+		fmt.Printf("│ Depends: %-67s │\n", truncate(strings.Join(req.DependsOn, ", "), constants.BoxContentWidth-9))
+	}
+
 	// Params
-	if req.ProcessedParams != nil {
+	if req.ParamsExpr != nil {
+		fmt.Printf("│ Params:  %-67s │\n", "(resolved from dependency results at run time)")
+	} else if req.ProcessedParams != nil {
 		fmt.Printf("│ Params:%-69s │\n", "")
 		paramsJSON, _ := json.MarshalIndent(req.ProcessedParams, "  ", "  ")
 		paramsLines := strings.Split(string(paramsJSON), "\n")
@@ -119,7 +165,7 @@ func (f *Formatter) formatSingleRequestDetailed(
 func (f *Formatter) FormatRequestJSON(requests []*types.Request) error {
 	output := make([]map[string]any, 0, len(requests))
 	for _, req := range requests {
-		output = append(output, map[string]any{
+		reqMap := map[string]any{
 			"name":    req.Name,
 			"method":  req.Method,
 			"params":  req.ProcessedParams,
@@ -127,7 +173,11 @@ func (f *Formatter) FormatRequestJSON(requests []*types.Request) error {
 			"headers": req.Headers,
 			"timeout": req.Timeout,
 			"config":  req.Config,
-		})
+		}
+		if len(req.DependsOn) > 0 {
+			reqMap["depends_on"] = req.DependsOn
+		}
+		output = append(output, reqMap)
 	}
 
 	jsonBytes, err := json.MarshalIndent(output, "", "  ")
@@ -149,66 +199,128 @@ func (f *Formatter) FormatExecutionResults(results []*types.ExecutionResult, jso
 	totalCount := len(results)
 	successCount := 0
 	failedCount := 0
+	cancelledCount := 0
+	notRunCount := 0
 
 	for i, result := range results {
 		fmt.Printf("\n[%d/%d] Executing: %s\n", i+1, totalCount, result.Request.Name)
 
+		if errors.Is(result.Error, types.ErrNotRun) {
+			fmt.Printf("  - Not run\n")
+			notRunCount++
+			continue
+		}
+
+		if errors.Is(result.Error, types.ErrCancelled) {
+			fmt.Printf("  ✗ Cancelled\n")
+			fmt.Printf("  Duration: %s\n", durationLabel(result))
+			fmt.Printf("  Error: %s\n", result.Error.Error())
+			f.printTrace(result)
+			cancelledCount++
+			continue
+		}
+
 		if result.Error != nil {
 			fmt.Printf("  ✗ Failed\n")
-			fmt.Printf("  Duration: %dms\n", result.Duration.Milliseconds())
+			fmt.Printf("  Duration: %s\n", durationLabel(result))
 			fmt.Printf("  Error: %s\n", result.Error.Error())
+			f.printTrace(result)
 			failedCount++
 			continue
 		}
 
+		if result.Response == nil {
+			fmt.Printf("  ✓ Sent (notification)\n")
+			fmt.Printf("  Duration: %s\n", durationLabel(result))
+			f.printTrace(result)
+			successCount++
+			continue
+		}
+
 		if result.Response.IsError() {
 			fmt.Printf("  ✗ RPC Error\n")
-			fmt.Printf("  Duration: %dms\n", result.Duration.Milliseconds())
+			fmt.Printf("  Duration: %s\n", durationLabel(result))
 			fmt.Printf("  Error Code: %d\n", result.Response.Error.Code)
 			fmt.Printf("  Error Message: %s\n", result.Response.Error.Message)
 			if result.Response.Error.Data != nil {
 				dataJSON, _ := json.MarshalIndent(result.Response.Error.Data, "  ", "  ")
 				fmt.Printf("  Error Data:\n  %s\n", string(dataJSON))
 			}
+			f.printTrace(result)
 			failedCount++
 			continue
 		}
 
 		fmt.Printf("  ✓ Success\n")
-		fmt.Printf("  Duration: %dms\n", result.Duration.Milliseconds())
-		fmt.Printf("  Result:\n")
-
-		// Pretty print result
-		var resultObj any
-		if err := json.Unmarshal(result.Response.Result, &resultObj); err == nil {
-			resultJSON, _ := json.MarshalIndent(resultObj, "  ", "  ")
-			fmt.Printf("  %s\n", string(resultJSON))
+		fmt.Printf("  Duration: %s\n", durationLabel(result))
+		if file, written := f.writtenResultFile(result); written {
+			fmt.Printf("  Result: written to %s (%d bytes)\n", file.path, file.size)
 		} else {
-			fmt.Printf("  %s\n", string(result.Response.Result))
+			fmt.Printf("  Result:\n")
+			printJSON(result.Response.Result)
 		}
+		f.printTrace(result)
 
 		successCount++
 	}
 
 	// Summary
 	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Printf("Summary: %d total, %d successful, %d failed\n", totalCount, successCount, failedCount)
+	fmt.Printf("Summary: %d total, %d successful, %d failed%s\n",
+		totalCount, successCount, failedCount, interruptedSummary(cancelledCount, notRunCount))
+}
+
+// interruptedSummary returns the counts of cancelled and not run requests
+// for a summary line, or "" if the run was not cancelled
+func interruptedSummary(cancelled, notRun int) string {
+	var summary string
+	if cancelled > 0 {
+		summary += fmt.Sprintf(", %d cancelled", cancelled)
+	}
+	if notRun > 0 {
+		summary += fmt.Sprintf(", %d not run", notRun)
+	}
+	return summary
 }
 
 // formatExecutionResultsJSON formats execution results in JSON format
 func (f *Formatter) formatExecutionResultsJSON(results []*types.ExecutionResult) {
-	output := make([]map[string]any, 0, len(results))
+	_ = f.writeExecutionResultsJSON(os.Stdout, results)
+}
 
-	for _, result := range results {
+// writeExecutionResultsJSON writes execution results as an indented JSON
+// array, one result at a time. Results are streamed as received instead of
+// being marshaled into a copy of the whole output.
+func (f *Formatter) writeExecutionResultsJSON(w io.Writer, results []*types.ExecutionResult) error {
+	out := bufio.NewWriter(w)
+	out.WriteString("[")
+
+	for i, result := range results {
+		var raw []byte
 		resultMap := map[string]any{
 			"request":  result.Request.Name,
 			"method":   result.Request.Method,
 			"duration": result.Duration.Milliseconds(),
 		}
+		if result.Attempts > 0 {
+			resultMap["attempts"] = result.Attempts
+		}
+		if result.Trace != nil {
+			resultMap["trace"] = f.traceJSON(result.Trace)
+		}
 
 		if result.Error != nil {
 			resultMap["success"] = false
 			resultMap["error"] = result.Error.Error()
+			if errors.Is(result.Error, types.ErrCancelled) {
+				resultMap["cancelled"] = true
+			}
+			if errors.Is(result.Error, types.ErrNotRun) {
+				resultMap["not_run"] = true
+			}
+		} else if result.Response == nil {
+			resultMap["success"] = true
+			resultMap["notification"] = true
 		} else if result.Response.IsError() {
 			resultMap["success"] = false
 			resultMap["rpc_error"] = map[string]any{
@@ -218,14 +330,94 @@ func (f *Formatter) formatExecutionResultsJSON(results []*types.ExecutionResult)
 			}
 		} else {
 			resultMap["success"] = true
-			var resultObj any
-			if err := json.Unmarshal(result.Response.Result, &resultObj); err == nil {
-				resultMap["result"] = resultObj
+			if file, written := f.writtenResultFile(result); written {
+				resultMap["result_file"] = file.path
+			} else if json.Valid(result.Response.Result) {
+				raw = result.Response.Result
 			} else {
 				resultMap["result"] = string(result.Response.Result)
 			}
 		}
 
+		if i > 0 {
+			out.WriteString(",")
+		}
+		out.WriteString("\n  ")
+		if err := writeObject(out, resultMap, "result", raw, "  "); err != nil {
+			return err
+		}
+	}
+
+	if len(results) > 0 {
+		out.WriteString("\n")
+	}
+	out.WriteString("]\n")
+	return out.Flush()
+}
+
+// FormatTestResults formats the assertion results of a test run
+func (f *Formatter) FormatTestResults(results []*types.ExecutionResult, jsonOutput bool) {
+	if jsonOutput {
+		f.formatTestResultsJSON(results)
+		return
+	}
+
+	passedRequests := 0
+	passedAssertions := 0
+	failedAssertions := 0
+	notRunRequests := 0
+
+	for _, result := range results {
+		if errors.Is(result.Error, types.ErrNotRun) {
+			notRunRequests++
+			fmt.Printf("- %s (not run)\n", result.Request.Name)
+			continue
+		}
+
+		if result.AssertionsPassed() {
+			passedRequests++
+			fmt.Printf("✓ %s (%s)\n", result.Request.Name, durationLabel(result))
+		} else {
+			fmt.Printf("✗ %s (%s)\n", result.Request.Name, durationLabel(result))
+		}
+
+		for _, assertion := range result.Assertions {
+			if assertion.Passed {
+				passedAssertions++
+				fmt.Printf("    ✓ %s\n", assertion.Name)
+				continue
+			}
+			failedAssertions++
+			fmt.Printf("    ✗ %s: %s\n", assertion.Name, assertion.Message)
+		}
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Printf("Requests: %d total, %d passed, %d failed%s\n",
+		len(results), passedRequests, len(results)-passedRequests-notRunRequests,
+		interruptedSummary(0, notRunRequests))
+	fmt.Printf("Assertions: %d total, %d passed, %d failed\n",
+		passedAssertions+failedAssertions, passedAssertions, failedAssertions)
+}
+
+// formatTestResultsJSON formats test results in JSON format
+func (f *Formatter) formatTestResultsJSON(results []*types.ExecutionResult) {
+	output := make([]map[string]any, 0, len(results))
+
+	for _, result := range results {
+		resultMap := map[string]any{
+			"request":    result.Request.Name,
+			"method":     result.Request.Method,
+			"duration":   result.Duration.Milliseconds(),
+			"passed":     result.AssertionsPassed(),
+			"assertions": result.Assertions,
+		}
+		if result.Attempts > 0 {
+			resultMap["attempts"] = result.Attempts
+		}
+		if errors.Is(result.Error, types.ErrNotRun) {
+			resultMap["not_run"] = true
+		}
 		output = append(output, resultMap)
 	}
 
@@ -233,6 +425,30 @@ func (f *Formatter) formatExecutionResultsJSON(results []*types.ExecutionResult)
 	fmt.Println(string(jsonBytes))
 }
 
+// FormatNotification prints a subscription notification, as one JSON
+// object per line (NDJSON) or as a timestamped pretty-printed result
+func (f *Formatter) FormatNotification(notification *types.Notification, jsonOutput bool) {
+	if jsonOutput {
+		jsonBytes, err := json.Marshal(notification)
+		if err != nil {
+			return
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	fmt.Printf("[%s] %s #%d\n",
+		notification.Received.Format("15:04:05.000"), notification.Subscription, notification.Sequence)
+	printJSON(notification.Result)
+}
+
+// printJSON prints a result indented by two spaces
+func printJSON(data []byte) {
+	fmt.Print("  ")
+	_ = writeJSON(os.Stdout, data, "  ")
+	fmt.Println()
+}
+
 // CountParams returns the number of parameters in a request
 func CountParams(params any) int {
 	if params == nil {
@@ -249,6 +465,15 @@ func CountParams(params any) int {
 	}
 }
 
+// durationLabel returns the duration of a result, with the number of
+// attempts when the request was retried
+func durationLabel(result *types.ExecutionResult) string {
+	if result.Attempts > 1 {
+		return fmt.Sprintf("%dms, %d attempts", result.Duration.Milliseconds(), result.Attempts)
+	}
+	return fmt.Sprintf("%dms", result.Duration.Milliseconds())
+}
+
 // truncate truncates a string to a maximum length
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -0,0 +1,61 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+func TestFormatter_WriteExecutionResultsJSON(t *testing.T) {
+	results := append(reportResults(),
+		&types.ExecutionResult{
+			Request:  &types.Request{Name: "get_block", Method: "eth_getBlockByNumber"},
+			Response: &types.JSONRPCResponse{Result: json.RawMessage(`{"number": "0x10", "txs": [], "a": [1, {"b": null}]}`)},
+			Duration: 30 * time.Millisecond,
+			Attempts: 2,
+		},
+		&types.ExecutionResult{
+			Request:  &types.Request{Name: "ping", Method: "ping"},
+			Duration: time.Millisecond,
+		},
+	)
+
+	tests := []struct {
+		name    string
+		results []*types.ExecutionResult
+	}{
+		{name: "results", results: results},
+		{name: "no results", results: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bytes.Buffer
+			if err := New().writeExecutionResultsJSON(&got, tt.results); err != nil {
+				t.Fatalf("writeExecutionResultsJSON() error = %v", err)
+			}
+
+			// The streamed output matches marshaling the whole array at once
+			var want []map[string]any
+			if err := json.Unmarshal(got.Bytes(), &want); err != nil {
+				t.Fatalf("output is not valid JSON: %v\n%s", err, got.String())
+			}
+			if len(want) != len(tt.results) {
+				t.Fatalf("output has %d results, want %d", len(want), len(tt.results))
+			}
+			// Results keep their key order
+			for i := range want {
+				if _, ok := want[i]["result"]; ok {
+					want[i]["result"] = tt.results[i].Response.Result
+				}
+			}
+			wantBytes, _ := json.MarshalIndent(want, "", "  ")
+			if got.String() != string(wantBytes)+"\n" {
+				t.Errorf("output =\n%s\nwant\n%s", got.String(), wantBytes)
+			}
+		})
+	}
+}
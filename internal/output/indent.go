@@ -0,0 +1,133 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// writeJSON writes data indented by two spaces, with prefix at the start of
+// every line but the first. Invalid JSON is written as is.
+func writeJSON(w io.Writer, data []byte, prefix string) error {
+	if !json.Valid(data) {
+		_, err := w.Write(data)
+		return err
+	}
+	return writeIndented(w, data, prefix, "  ")
+}
+
+// writeIndented writes the valid JSON value data to w formatted like
+// json.Indent, streaming it through a small buffer instead of building an
+// indented copy. Key order and numbers are kept exactly as received.
+func writeIndented(w io.Writer, data []byte, prefix, indent string) error {
+	out := bufio.NewWriter(w)
+	depth := 0
+	inString, escaped := false, false
+	// opened is set after a bracket, until its first element or its end
+	opened := false
+
+	newline := func() {
+		out.WriteByte('\n')
+		out.WriteString(prefix)
+		for i := 0; i < depth; i++ {
+			out.WriteString(indent)
+		}
+	}
+
+	for _, c := range data {
+		if inString {
+			out.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		}
+
+		if opened && c != '}' && c != ']' {
+			opened = false
+			depth++
+			newline()
+		}
+
+		switch c {
+		case '"':
+			inString = true
+			out.WriteByte(c)
+		case '{', '[':
+			out.WriteByte(c)
+			opened = true
+		case '}', ']':
+			// Empty objects and arrays stay on one line
+			if opened {
+				opened = false
+			} else {
+				depth--
+				newline()
+			}
+			out.WriteByte(c)
+		case ',':
+			out.WriteByte(c)
+			newline()
+		case ':':
+			out.WriteString(": ")
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	return out.Flush()
+}
+
+// writeObject writes fields as a JSON object indented like json.MarshalIndent
+// with prefix and two spaces, keys sorted. A non-nil raw is written as the
+// value of rawKey with writeIndented, without being decoded or copied.
+func writeObject(w io.Writer, fields map[string]any, rawKey string, raw []byte, prefix string) error {
+	keys := make([]string, 0, len(fields)+1)
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	if raw != nil {
+		keys = append(keys, rawKey)
+	}
+	sort.Strings(keys)
+
+	out := bufio.NewWriter(w)
+	out.WriteString("{")
+	for i, key := range keys {
+		if i > 0 {
+			out.WriteString(",")
+		}
+		name, _ := json.Marshal(key)
+		out.WriteString("\n" + prefix + "  ")
+		out.Write(name)
+		out.WriteString(": ")
+
+		if raw != nil && key == rawKey {
+			if err := writeIndented(out, raw, prefix+"  ", "  "); err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := json.MarshalIndent(fields[key], prefix+"  ", "  ")
+		if err != nil {
+			return err
+		}
+		out.Write(value)
+	}
+	if len(keys) > 0 {
+		out.WriteString("\n" + prefix)
+	}
+	out.WriteString("}")
+	return out.Flush()
+}
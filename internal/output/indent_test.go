@@ -0,0 +1,50 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteIndented(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "scalar", data: `"0x10"`},
+		{name: "number precision", data: `12345678901234567890.000000000001`},
+		{name: "key order", data: `{"z":1,"a":2,"m":3}`},
+		{name: "nested", data: `{"block":{"number":"0x1","txs":[{"hash":"0xa"},{"hash":"0xb"}]},"ok":true}`},
+		{name: "empty containers", data: `{"a":{},"b":[],"c":[{}]}`},
+		{name: "whitespace", data: " {\n  \"a\" : [ 1 ,\t2 ]\r\n}"},
+		{name: "strings with delimiters", data: `{"s":"a,b:{c}[d]","e":"quote \" and \\","u":"\u00e9<>"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want bytes.Buffer
+			if err := json.Indent(&want, []byte(tt.data), "  ", "\t"); err != nil {
+				t.Fatalf("json.Indent() error = %v", err)
+			}
+
+			var got bytes.Buffer
+			if err := writeIndented(&got, []byte(tt.data), "  ", "\t"); err != nil {
+				t.Fatalf("writeIndented() error = %v", err)
+			}
+
+			if got.String() != want.String() {
+				t.Errorf("writeIndented() =\n%s\nwant\n%s", got.String(), want.String())
+			}
+		})
+	}
+}
+
+func TestWriteJSON_Invalid(t *testing.T) {
+	var got bytes.Buffer
+	if err := writeJSON(&got, []byte("not json {"), "  "); err != nil {
+		t.Fatalf("writeJSON() error = %v", err)
+	}
+	if got.String() != "not json {" {
+		t.Errorf("writeJSON() = %q, want the data unchanged", got.String())
+	}
+}
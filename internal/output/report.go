@@ -0,0 +1,270 @@
+package output
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// ReportFormat represents a machine-readable report format
+type ReportFormat string
+
+const (
+	ReportJUnit ReportFormat = "junit"
+	ReportTAP   ReportFormat = "tap"
+)
+
+// ReportSpec describes a report to write: its format and destination.
+// An empty path means standard output.
+type ReportSpec struct {
+	Format ReportFormat
+	Path   string
+}
+
+// ParseReportSpec parses a `format[=path]` report flag value
+func ParseReportSpec(value string) (ReportSpec, error) {
+	format, path, _ := strings.Cut(value, "=")
+	spec := ReportSpec{
+		Format: ReportFormat(strings.ToLower(strings.TrimSpace(format))),
+		Path:   strings.TrimSpace(path),
+	}
+
+	switch spec.Format {
+	case ReportJUnit, ReportTAP:
+		return spec, nil
+	default:
+		return ReportSpec{}, fmt.Errorf("unsupported report format: %s (expected junit or tap)", format)
+	}
+}
+
+// WriteReport writes execution results in the format and to the destination
+// described by spec
+func (f *Formatter) WriteReport(spec ReportSpec, suiteName string, results []*types.ExecutionResult) error {
+	var w io.Writer = os.Stdout
+	if spec.Path != "" {
+		file, err := os.Create(filepath.Clean(spec.Path))
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		w = file
+	}
+
+	switch spec.Format {
+	case ReportJUnit:
+		return WriteJUnit(w, suiteName, results)
+	case ReportTAP:
+		return WriteTAP(w, results)
+	default:
+		return fmt.Errorf("unsupported report format: %s", spec.Format)
+	}
+}
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr,omitempty"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the test cases of one HCL file
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr,omitempty"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single executed request
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+// junitSkipped marks a request that did not run because the run was cancelled
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitProblem is a failure (RPC error, failed assertion) or an error (transport)
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes execution results as a JUnit XML report. Each request is
+// a test case; transport errors and cancelled requests are reported as
+// errors, RPC errors or failed assertions as failures, and requests that
+// did not run as skipped.
+func WriteJUnit(w io.Writer, suiteName string, results []*types.ExecutionResult) error {
+	suite := junitTestSuite{
+		Name:      suiteName,
+		Tests:     len(results),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Cases:     make([]junitTestCase, 0, len(results)),
+	}
+
+	var total time.Duration
+	for _, result := range results {
+		total += result.Duration
+
+		testCase := junitTestCase{
+			Name:      result.Request.Name,
+			ClassName: result.Request.Method,
+			Time:      formatSeconds(result.Duration),
+		}
+
+		kind, message, body := describeFailure(result)
+		switch kind {
+		case "":
+		case "not_run":
+			testCase.Skipped = &junitSkipped{Message: message}
+			suite.Skipped++
+		case "transport_error", "cancelled":
+			testCase.Error = &junitProblem{Message: message, Type: kind, Body: body}
+			suite.Errors++
+		default:
+			testCase.Failure = &junitProblem{Message: message, Type: kind, Body: body}
+			suite.Failures++
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Time = formatSeconds(total)
+
+	report := junitTestSuites{
+		Name:     "rpc-cli",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteTAP writes execution results in the Test Anything Protocol (version
+// 13). Requests that did not run are reported with a SKIP directive.
+func WriteTAP(w io.Writer, results []*types.ExecutionResult) error {
+	var b strings.Builder
+
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", len(results))
+
+	for i, result := range results {
+		kind, message, body := describeFailure(result)
+		if kind == "not_run" {
+			fmt.Fprintf(&b, "ok %d - %s # SKIP %s\n", i+1, result.Request.Name, message)
+			continue
+		}
+
+		status := "ok"
+		if kind != "" {
+			status = "not ok"
+		}
+		fmt.Fprintf(&b, "%s %d - %s\n", status, i+1, result.Request.Name)
+
+		b.WriteString("  ---\n")
+		fmt.Fprintf(&b, "  method: %s\n", strconv.Quote(result.Request.Method))
+		fmt.Fprintf(&b, "  duration_ms: %d\n", result.Duration.Milliseconds())
+		if kind != "" {
+			fmt.Fprintf(&b, "  type: %s\n", kind)
+			fmt.Fprintf(&b, "  message: %s\n", strconv.Quote(message))
+			if body != "" && body != message {
+				b.WriteString("  details: |\n")
+				for _, line := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+					fmt.Fprintf(&b, "    %s\n", line)
+				}
+			}
+		}
+		b.WriteString("  ...\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// describeFailure classifies a failed result and returns its type, a short
+// message and a detailed body. The type is empty for successful results.
+// When assertions were evaluated (test command) they decide the outcome, so
+// an expected RPC error code counts as a pass.
+func describeFailure(result *types.ExecutionResult) (kind, message, body string) {
+	switch {
+	case errors.Is(result.Error, types.ErrNotRun):
+		return "not_run", result.Error.Error(), result.Error.Error()
+	case errors.Is(result.Error, types.ErrCancelled):
+		return "cancelled", result.Error.Error(), result.Error.Error()
+	}
+
+	if result.Error != nil {
+		return "transport_error", result.Error.Error(), result.Error.Error()
+	}
+
+	if len(result.Assertions) > 0 {
+		if result.AssertionsPassed() {
+			return "", "", ""
+		}
+
+		var lines []string
+		for _, assertion := range result.Assertions {
+			if !assertion.Passed {
+				lines = append(lines, fmt.Sprintf("%s: %s", assertion.Name, assertion.Message))
+			}
+		}
+		return "assertion_failure", fmt.Sprintf("%d assertion(s) failed", len(lines)), strings.Join(lines, "\n")
+	}
+
+	if result.Response != nil && result.Response.IsError() {
+		rpcErr := result.Response.Error
+		message = fmt.Sprintf("RPC error %d: %s", rpcErr.Code, rpcErr.Message)
+		body = message
+		if rpcErr.Data != nil {
+			body += fmt.Sprintf("\ndata: %v", rpcErr.Data)
+		}
+		return "rpc_error", message, body
+	}
+
+	return "", "", ""
+}
+
+// formatSeconds formats a duration in seconds with millisecond precision
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
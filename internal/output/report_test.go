@@ -0,0 +1,181 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// reportResults returns one successful, one RPC error and one transport error result
+func reportResults() []*types.ExecutionResult {
+	return []*types.ExecutionResult{
+		{
+			Request:  &types.Request{Name: "get_block_number", Method: "eth_blockNumber"},
+			Response: &types.JSONRPCResponse{Result: json.RawMessage(`"0x10"`)},
+			Duration: 120 * time.Millisecond,
+		},
+		{
+			Request:  &types.Request{Name: "unknown", Method: "does_not_exist"},
+			Response: &types.JSONRPCResponse{Error: &types.RPCError{Code: -32601, Message: "method not found"}},
+			Duration: 80 * time.Millisecond,
+		},
+		{
+			Request:  &types.Request{Name: "offline", Method: "eth_chainId"},
+			Error:    errors.New("HTTP request failed: connection refused"),
+			Duration: 5 * time.Millisecond,
+		},
+	}
+}
+
+func TestParseReportSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    ReportSpec
+		wantErr bool
+	}{
+		{name: "junit with path", value: "junit=report.xml", want: ReportSpec{Format: ReportJUnit, Path: "report.xml"}},
+		{name: "tap to stdout", value: "tap", want: ReportSpec{Format: ReportTAP}},
+		{name: "case insensitive", value: "JUnit=out.xml", want: ReportSpec{Format: ReportJUnit, Path: "out.xml"}},
+		{name: "unknown format", value: "html=out.html", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseReportSpec(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReportSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseReportSpec() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, "requests.hcl", reportResults()); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("report is not valid XML: %v", err)
+	}
+
+	if report.Tests != 3 || report.Failures != 1 || report.Errors != 1 {
+		t.Errorf("totals = %d tests, %d failures, %d errors, want 3, 1, 1",
+			report.Tests, report.Failures, report.Errors)
+	}
+
+	cases := report.Suites[0].Cases
+	if cases[0].Failure != nil || cases[0].Error != nil {
+		t.Error("successful request should have no failure")
+	}
+	if cases[0].Time != "0.120" {
+		t.Errorf("time = %s, want 0.120", cases[0].Time)
+	}
+	if cases[1].Failure == nil || cases[1].Failure.Message != "RPC error -32601: method not found" {
+		t.Errorf("RPC error failure = %+v", cases[1].Failure)
+	}
+	if cases[2].Error == nil || cases[2].Error.Type != "transport_error" {
+		t.Errorf("transport error = %+v", cases[2].Error)
+	}
+}
+
+func TestWriteTAP(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTAP(&buf, reportResults()); err != nil {
+		t.Fatalf("WriteTAP() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"TAP version 13\n1..3\n",
+		"ok 1 - get_block_number\n",
+		"not ok 2 - unknown\n",
+		`message: "RPC error -32601: method not found"`,
+		"not ok 3 - offline\n",
+		"type: transport_error",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("TAP output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDescribeFailure_ExpectedErrorCode(t *testing.T) {
+	result := reportResults()[1]
+	result.Assertions = []types.AssertionResult{{Name: "error_code -32601", Passed: true}}
+
+	if kind, _, _ := describeFailure(result); kind != "" {
+		t.Errorf("describeFailure() kind = %s, want pass for expected error code", kind)
+	}
+}
+
+// cancelledResults returns the results of a run cancelled while its second
+// request was in flight
+func cancelledResults() []*types.ExecutionResult {
+	return []*types.ExecutionResult{
+		reportResults()[0],
+		{
+			Request:  &types.Request{Name: "get_logs", Method: "eth_getLogs"},
+			Error:    types.ErrCancelled,
+			Duration: 2 * time.Second,
+		},
+		{
+			Request: &types.Request{Name: "get_block", Method: "eth_getBlockByNumber"},
+			Error:   types.ErrNotRun,
+		},
+	}
+}
+
+func TestWriteJUnit_Cancelled(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, "requests.hcl", cancelledResults()); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("report is not valid XML: %v", err)
+	}
+
+	if report.Tests != 3 || report.Errors != 1 || report.Skipped != 1 {
+		t.Errorf("totals = %d tests, %d errors, %d skipped, want 3, 1, 1",
+			report.Tests, report.Errors, report.Skipped)
+	}
+
+	cases := report.Suites[0].Cases
+	if cases[1].Error == nil || cases[1].Error.Type != "cancelled" {
+		t.Errorf("cancelled error = %+v", cases[1].Error)
+	}
+	if cases[2].Skipped == nil || cases[2].Skipped.Message != "not run" {
+		t.Errorf("not run skipped = %+v", cases[2].Skipped)
+	}
+}
+
+func TestWriteTAP_Cancelled(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTAP(&buf, cancelledResults()); err != nil {
+		t.Fatalf("WriteTAP() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"not ok 2 - get_logs\n",
+		"type: cancelled",
+		"ok 3 - get_block # SKIP not run\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("TAP output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"jsonrpc/pkg/types"
+)
+
+// resultFile is a result written to the output directory
+type resultFile struct {
+	path string
+	size int64
+}
+
+// SetOutputDir makes WriteResultFile write results to dir, and the text and
+// JSON output refer to those files instead of including the results
+func (f *Formatter) SetOutputDir(dir string) {
+	f.outputDir = dir
+}
+
+// WriteResultFile writes the result of a successful request to
+// REQUEST_NAME.json in the output directory and reports whether it did. A
+// result that cannot be written fails its request. It can be called for
+// several results at once, as their requests finish.
+func (f *Formatter) WriteResultFile(result *types.ExecutionResult) bool {
+	if f.outputDir == "" || !result.IsSuccess() || result.Response == nil {
+		return false
+	}
+
+	name, err := resultFileName(result.Request.Name)
+	if err != nil {
+		result.Error = err
+		return false
+	}
+	path := filepath.Join(f.outputDir, name)
+	size, err := writeResultFile(path, result.Response.Result)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to write result: %w", err)
+		return false
+	}
+
+	f.resultFilesMu.Lock()
+	defer f.resultFilesMu.Unlock()
+	if f.resultFiles == nil {
+		f.resultFiles = make(map[*types.ExecutionResult]resultFile)
+	}
+	f.resultFiles[result] = resultFile{path: path, size: size}
+	return true
+}
+
+// writtenResultFile returns the file a result was written to, if any
+func (f *Formatter) writtenResultFile(result *types.ExecutionResult) (resultFile, bool) {
+	f.resultFilesMu.Lock()
+	defer f.resultFilesMu.Unlock()
+	file, written := f.resultFiles[result]
+	return file, written
+}
+
+// resultFileName returns the file name of a request's result. Names that
+// are not a single local path element, such as "../x" or "a/b", would be
+// written outside the output directory and are rejected.
+func resultFileName(request string) (string, error) {
+	name := request + ".json"
+	if !filepath.IsLocal(name) || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("request name '%s' cannot be used as a file name in the output directory", request)
+	}
+	return name, nil
+}
+
+// writeResultFile writes an indented result to path and returns its size
+func writeResultFile(path string, result []byte) (int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := writeJSON(file, result, ""); err != nil {
+		file.Close()
+		return 0, err
+	}
+	if _, err := file.WriteString("\n"); err != nil {
+		file.Close()
+		return 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return 0, err
+	}
+	return info.Size(), file.Close()
+}
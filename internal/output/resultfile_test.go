@@ -0,0 +1,118 @@
+package output
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"jsonrpc/pkg/types"
+)
+
+func TestFormatter_WriteResultFile(t *testing.T) {
+	dir := t.TempDir()
+	results := reportResults()
+
+	formatter := New()
+	formatter.SetOutputDir(dir)
+	for _, result := range results {
+		written := formatter.WriteResultFile(result)
+		if written != (result == results[0]) {
+			t.Errorf("WriteResultFile(%s) = %v", result.Request.Name, written)
+		}
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "get_block_number.json"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != "\"0x10\"\n" {
+		t.Errorf("get_block_number.json = %q, want %q", got, "\"0x10\"\n")
+	}
+
+	// Failed requests have no result to write
+	for _, name := range []string{"unknown", "offline"} {
+		if _, err := os.Stat(filepath.Join(dir, name+".json")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s.json exists, want no file for a failed request", name)
+		}
+	}
+
+	if file, _ := formatter.writtenResultFile(results[0]); file.size != int64(len(got)) {
+		t.Errorf("recorded size = %d, want %d", file.size, len(got))
+	}
+}
+
+func TestFormatter_WriteResultFile_Error(t *testing.T) {
+	results := reportResults()
+
+	formatter := New()
+	formatter.SetOutputDir(filepath.Join(t.TempDir(), "missing"))
+	if formatter.WriteResultFile(results[0]) {
+		t.Error("WriteResultFile() = true for a result that could not be written")
+	}
+
+	if results[0].IsSuccess() {
+		t.Fatal("result that could not be written is still successful")
+	}
+	if _, written := formatter.writtenResultFile(results[0]); written {
+		t.Error("result that could not be written is recorded as written")
+	}
+}
+
+func TestFormatter_WriteResultFile_NoOutputDir(t *testing.T) {
+	results := []*types.ExecutionResult{reportResults()[0]}
+
+	formatter := New()
+	if formatter.WriteResultFile(results[0]) || !results[0].IsSuccess() {
+		t.Error("WriteResultFile() without an output directory changed the results")
+	}
+}
+
+func TestResultFileName(t *testing.T) {
+	tests := []struct {
+		request string
+		want    string
+		wantErr bool
+	}{
+		{request: "get_block", want: "get_block.json"},
+		{request: "eth.call-1", want: "eth.call-1.json"},
+		{request: "../../x", wantErr: true},
+		{request: "a/b", wantErr: true},
+		{request: `a\b`, wantErr: true},
+		{request: "/etc/passwd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.request, func(t *testing.T) {
+			got, err := resultFileName(tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resultFileName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resultFileName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatter_WriteResultFile_UnsafeName(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "results")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	result := reportResults()[0]
+	result.Request.Name = "../escaped"
+
+	formatter := New()
+	formatter.SetOutputDir(dir)
+	formatter.WriteResultFile(result)
+
+	if result.IsSuccess() {
+		t.Error("result with an unsafe name is still successful")
+	}
+	if _, err := os.Stat(filepath.Join(parent, "escaped.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("result was written outside the output directory")
+	}
+}
@@ -0,0 +1,150 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"jsonrpc/internal/mask"
+	"jsonrpc/pkg/types"
+)
+
+// TraceLines renders a trace like curl -v: outgoing lines start with "> ",
+// incoming lines with "< ", followed by the timings. Sensitive headers are
+// masked.
+func TraceLines(trace *types.Trace) []string {
+	masker := mask.NewSensitiveMasker()
+	var lines []string
+
+	if trace.Request.Method != "" {
+		lines = append(lines, fmt.Sprintf("> %s %s", trace.Request.Method, trace.Request.URL))
+	} else {
+		lines = append(lines, "> "+trace.Request.URL)
+	}
+	lines = append(lines, headerLines(">", trace.Request.Headers, masker)...)
+	lines = append(lines, bodyLines(">", trace.Request.Body)...)
+
+	if response := trace.Response; response != nil {
+		if response.Status != "" {
+			lines = append(lines, "< "+response.Status)
+		}
+		lines = append(lines, headerLines("<", response.Headers, masker)...)
+		lines = append(lines, bodyLines("<", string(response.Body))...)
+	} else {
+		lines = append(lines, "< (no response)")
+	}
+
+	return append(lines, "Timings: "+timingsLabel(trace.Timings))
+}
+
+// printTrace prints the trace of a result, if it has one
+func (f *Formatter) printTrace(result *types.ExecutionResult) {
+	if result.Trace == nil {
+		return
+	}
+	fmt.Printf("  Trace:\n")
+	for _, line := range TraceLines(result.Trace) {
+		fmt.Printf("    %s\n", line)
+	}
+}
+
+// traceJSON returns the JSON output of a trace with sensitive headers masked
+func (f *Formatter) traceJSON(trace *types.Trace) map[string]any {
+	request := map[string]any{
+		"url":  trace.Request.URL,
+		"body": trace.Request.Body,
+	}
+	if trace.Request.Method != "" {
+		request["method"] = trace.Request.Method
+		request["headers"] = f.maskHeaders(trace.Request.Headers)
+	}
+
+	timings := trace.Timings
+	output := map[string]any{
+		"request": request,
+		"timings": map[string]any{
+			"dns_ms":            milliseconds(timings.DNS),
+			"connect_ms":        milliseconds(timings.Connect),
+			"tls_ms":            milliseconds(timings.TLS),
+			"ttfb_ms":           milliseconds(timings.TimeToFirstByte),
+			"total_ms":          milliseconds(timings.Total),
+			"reused_connection": timings.ReusedConn,
+		},
+	}
+
+	if response := trace.Response; response != nil {
+		responseMap := map[string]any{"body": string(response.Body)}
+		if response.Status != "" {
+			responseMap["status"] = response.Status
+			responseMap["status_code"] = response.StatusCode
+			responseMap["headers"] = f.maskHeaders(response.Headers)
+		}
+		output["response"] = responseMap
+	}
+
+	return output
+}
+
+// maskHeaders returns a copy of headers with sensitive values masked
+func (f *Formatter) maskHeaders(headers map[string]string) map[string]string {
+	masked := make(map[string]string, len(headers))
+	for name, value := range headers {
+		masked[name] = f.masker.MaskIfSensitive(name, value)
+	}
+	return masked
+}
+
+// headerLines renders headers sorted by name, masking sensitive values
+func headerLines(prefix string, headers map[string]string, masker *mask.SensitiveMasker) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("%s %s: %s", prefix, name, masker.MaskIfSensitive(name, headers[name])))
+	}
+	return lines
+}
+
+// bodyLines renders a body after a blank separator line
+func bodyLines(prefix, body string) []string {
+	lines := []string{prefix}
+	if body == "" {
+		return lines
+	}
+	for _, line := range strings.Split(strings.TrimRight(body, "\r\n"), "\n") {
+		lines = append(lines, prefix+" "+line)
+	}
+	return lines
+}
+
+// timingsLabel renders the phases of a trace. Only the total is known for
+// transports other than HTTP.
+func timingsLabel(timings types.TraceTimings) string {
+	total := fmt.Sprintf("total %s", formatMilliseconds(timings.Total))
+	if timings.TimeToFirstByte == 0 {
+		return total
+	}
+
+	label := fmt.Sprintf("dns %s, connect %s, tls %s, ttfb %s, %s",
+		formatMilliseconds(timings.DNS), formatMilliseconds(timings.Connect),
+		formatMilliseconds(timings.TLS), formatMilliseconds(timings.TimeToFirstByte), total)
+	if timings.ReusedConn {
+		label += " (reused connection)"
+	}
+	return label
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// formatMilliseconds formats a duration in milliseconds with one decimal
+func formatMilliseconds(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", milliseconds(d))
+}
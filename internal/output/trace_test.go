@@ -0,0 +1,99 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// httpTrace returns the trace of a traced HTTP request
+func httpTrace() *types.Trace {
+	return &types.Trace{
+		Request: types.TraceRequest{
+			Method:  "POST",
+			URL:     "https://node.example.com/rpc",
+			Headers: map[string]string{"Content-Type": "application/json", "Authorization": "Bearer secret-token"},
+			Body:    `{"jsonrpc":"2.0","method":"eth_chainId","id":1}`,
+		},
+		Response: &types.TraceResponse{
+			Status:     "200 OK",
+			StatusCode: 200,
+			Headers:    map[string]string{"Set-Cookie": "session=abcdef"},
+			Body:       []byte("{\"jsonrpc\":\"2.0\",\"result\":\"0x1\",\"id\":1}\n"),
+		},
+		Timings: types.TraceTimings{
+			DNS:             1500 * time.Microsecond,
+			Connect:         2 * time.Millisecond,
+			TimeToFirstByte: 10 * time.Millisecond,
+			Total:           12 * time.Millisecond,
+		},
+	}
+}
+
+func TestTraceLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		trace *types.Trace
+		want  []string
+	}{
+		{
+			name:  "http",
+			trace: httpTrace(),
+			want: []string{
+				"> POST https://node.example.com/rpc",
+				"> Authorization: Bear****",
+				"> Content-Type: application/json",
+				">",
+				`> {"jsonrpc":"2.0","method":"eth_chainId","id":1}`,
+				"< 200 OK",
+				"< Set-Cookie: sess****",
+				"<",
+				`< {"jsonrpc":"2.0","result":"0x1","id":1}`,
+				"Timings: dns 1.5ms, connect 2.0ms, tls 0.0ms, ttfb 10.0ms, total 12.0ms",
+			},
+		},
+		{
+			name: "stdio without response",
+			trace: &types.Trace{
+				Request: types.TraceRequest{URL: "geth --ipc", Body: `{"id":1}`},
+				Timings: types.TraceTimings{Total: 3 * time.Millisecond},
+			},
+			want: []string{
+				"> geth --ipc",
+				">",
+				`> {"id":1}`,
+				"< (no response)",
+				"Timings: total 3.0ms",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TraceLines(tt.trace); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TraceLines() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatter_TraceJSON(t *testing.T) {
+	output := New().traceJSON(httpTrace())
+
+	request := output["request"].(map[string]any)
+	if headers := request["headers"].(map[string]string); headers["Authorization"] != "Bear****" {
+		t.Errorf("request Authorization = %q, want it masked", headers["Authorization"])
+	}
+
+	response := output["response"].(map[string]any)
+	if response["status_code"] != 200 || response["body"] != string(httpTrace().Response.Body) {
+		t.Errorf("response = %v", response)
+	}
+
+	timings := output["timings"].(map[string]any)
+	if timings["dns_ms"] != 1.5 || timings["total_ms"] != 12.0 {
+		t.Errorf("timings = %v", timings)
+	}
+}
@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// buildEvalContext builds the evaluation context shared by all config and
+// request attributes: the function library plus `env`, `var` and `local` values
+func (p *Parser) buildEvalContext(blocks hcl.Blocks) (*hcl.EvalContext, error) {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"env": envObject(),
+		},
+		Functions: Functions(),
+	}
+
+	variables, err := p.evaluateVariables(blocks, ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Variables["var"] = cty.ObjectVal(variables)
+
+	locals, err := p.evaluateLocals(blocks, ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Variables["local"] = cty.ObjectVal(locals)
+
+	return ctx, nil
+}
+
+// evaluateVariables resolves the value of every variable block. Values set
+// with SetVariables take precedence over declared defaults.
+func (p *Parser) evaluateVariables(blocks hcl.Blocks, ctx *hcl.EvalContext) (map[string]cty.Value, error) {
+	variables := make(map[string]cty.Value)
+
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "default"},
+			{Name: "description"},
+		},
+	}
+
+	for _, block := range blocks {
+		if block.Type != "variable" {
+			continue
+		}
+
+		if len(block.Labels) == 0 {
+			return nil, fmt.Errorf("variable block must have a name label")
+		}
+		name := block.Labels[0]
+
+		if _, exists := variables[name]; exists {
+			return nil, fmt.Errorf("variable '%s' is declared more than once", name)
+		}
+
+		content, diags := block.Body.Content(schema)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to decode variable '%s': %s", name, diags.Error())
+		}
+
+		defaultVal := cty.NilVal
+		attr, hasDefault := content.Attributes["default"]
+		if hasDefault {
+			// Variable defaults may only use functions and the environment
+			if err := checkEnvReferences(attr.Expr); err != nil {
+				return nil, err
+			}
+			val, diags := attr.Expr.Value(ctx)
+			if diags.HasErrors() {
+				return nil, fmt.Errorf("failed to decode variable '%s': %s", name, diags.Error())
+			}
+			defaultVal = val
+		}
+
+		if supplied, exists := p.variables[name]; exists {
+			variables[name] = coerceVariable(name, supplied, defaultVal)
+			continue
+		}
+
+		if !hasDefault {
+			return nil, fmt.Errorf("variable '%s' has no default value; set it with --var or --var-file", name)
+		}
+		variables[name] = defaultVal
+	}
+
+	for name := range p.variables {
+		if _, declared := variables[name]; !declared {
+			return nil, fmt.Errorf("variable '%s' is set but not declared in a variable block", name)
+		}
+	}
+
+	return variables, nil
+}
+
+// evaluateLocals evaluates all locals blocks, resolving references between
+// locals in dependency order
+func (p *Parser) evaluateLocals(blocks hcl.Blocks, ctx *hcl.EvalContext) (map[string]cty.Value, error) {
+	pending := make(map[string]*hcl.Attribute)
+
+	for _, block := range blocks {
+		if block.Type != "locals" {
+			continue
+		}
+
+		attrs, diags := block.Body.JustAttributes()
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to decode locals: %s", diags.Error())
+		}
+
+		for name, attr := range attrs {
+			if _, exists := pending[name]; exists {
+				return nil, fmt.Errorf("local '%s' is defined more than once", name)
+			}
+			pending[name] = attr
+		}
+	}
+
+	locals := make(map[string]cty.Value)
+	for len(pending) > 0 {
+		progress := false
+
+		for _, name := range sortedAttributeNames(pending) {
+			attr := pending[name]
+			if !localsReady(attr.Expr, pending) {
+				continue
+			}
+
+			if err := checkEnvReferences(attr.Expr); err != nil {
+				return nil, err
+			}
+
+			ctx.Variables["local"] = cty.ObjectVal(locals)
+			val, diags := attr.Expr.Value(ctx)
+			if diags.HasErrors() {
+				return nil, fmt.Errorf("failed to decode local '%s': %s", name, diags.Error())
+			}
+
+			locals[name] = val
+			delete(pending, name)
+			progress = true
+		}
+
+		if !progress {
+			return nil, fmt.Errorf("locals have a reference cycle: %s",
+				strings.Join(sortedAttributeNames(pending), ", "))
+		}
+	}
+
+	return locals, nil
+}
+
+// localsReady reports whether an expression only references locals that
+// have already been evaluated
+func localsReady(expr hcl.Expression, pending map[string]*hcl.Attribute) bool {
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() != "local" || len(traversal) < 2 {
+			continue
+		}
+		attr, ok := traversal[1].(hcl.TraverseAttr)
+		if !ok {
+			continue
+		}
+		if _, isPending := pending[attr.Name]; isPending {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedAttributeNames returns attribute names in a stable order
+func sortedAttributeNames(attrs map[string]*hcl.Attribute) []string {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -2,22 +2,44 @@ package parser
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 )
 
 // AttributeDecoder handles decoding HCL attributes to Go types
-type AttributeDecoder struct{}
+type AttributeDecoder struct {
+	ctx *hcl.EvalContext
+}
 
-// NewAttributeDecoder creates a new AttributeDecoder
-func NewAttributeDecoder() *AttributeDecoder {
-	return &AttributeDecoder{}
+// NewAttributeDecoder creates a new AttributeDecoder that evaluates
+// expressions in the given context (nil disables variables and functions)
+func NewAttributeDecoder(ctx *hcl.EvalContext) *AttributeDecoder {
+	return &AttributeDecoder{ctx: ctx}
+}
+
+// DecodeValue evaluates an HCL attribute to a cty.Value
+func (d *AttributeDecoder) DecodeValue(attr *hcl.Attribute) (cty.Value, error) {
+	if err := checkEnvReferences(attr.Expr); err != nil {
+		return cty.NilVal, err
+	}
+
+	val, diags := attr.Expr.Value(d.ctx)
+	if diags.HasErrors() {
+		return cty.NilVal, fmt.Errorf("failed to decode %s: %s", attr.Name, diags.Error())
+	}
+	return val, nil
 }
 
 // DecodeString decodes an HCL attribute to a string
 func (d *AttributeDecoder) DecodeString(attr *hcl.Attribute, target *string) error {
-	val, diags := attr.Expr.Value(nil)
+	if err := checkEnvReferences(attr.Expr); err != nil {
+		return err
+	}
+
+	val, diags := attr.Expr.Value(d.ctx)
 	if diags.HasErrors() {
 		return fmt.Errorf("failed to decode string: %s", diags.Error())
 	}
@@ -25,6 +47,9 @@ func (d *AttributeDecoder) DecodeString(attr *hcl.Attribute, target *string) err
 	if val.Type() != cty.String {
 		return fmt.Errorf("expected string, got %s", val.Type().FriendlyName())
 	}
+	if val.IsNull() {
+		return fmt.Errorf("expected string, got null")
+	}
 
 	*target = val.AsString()
 	return nil
@@ -32,7 +57,11 @@ func (d *AttributeDecoder) DecodeString(attr *hcl.Attribute, target *string) err
 
 // DecodeInt decodes an HCL attribute to an integer
 func (d *AttributeDecoder) DecodeInt(attr *hcl.Attribute, target *int) error {
-	val, diags := attr.Expr.Value(nil)
+	if err := checkEnvReferences(attr.Expr); err != nil {
+		return err
+	}
+
+	val, diags := attr.Expr.Value(d.ctx)
 	if diags.HasErrors() {
 		return fmt.Errorf("failed to decode int: %s", diags.Error())
 	}
@@ -40,6 +69,9 @@ func (d *AttributeDecoder) DecodeInt(attr *hcl.Attribute, target *int) error {
 	if val.Type() != cty.Number {
 		return fmt.Errorf("expected number, got %s", val.Type().FriendlyName())
 	}
+	if val.IsNull() {
+		return fmt.Errorf("expected number, got null")
+	}
 
 	bf := val.AsBigFloat()
 	i, _ := bf.Int64()
@@ -47,9 +79,35 @@ func (d *AttributeDecoder) DecodeInt(attr *hcl.Attribute, target *int) error {
 	return nil
 }
 
+// DecodeBool decodes an HCL attribute to a bool
+func (d *AttributeDecoder) DecodeBool(attr *hcl.Attribute, target *bool) error {
+	if err := checkEnvReferences(attr.Expr); err != nil {
+		return err
+	}
+
+	val, diags := attr.Expr.Value(d.ctx)
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to decode bool: %s", diags.Error())
+	}
+
+	if val.Type() != cty.Bool {
+		return fmt.Errorf("expected bool, got %s", val.Type().FriendlyName())
+	}
+	if val.IsNull() {
+		return fmt.Errorf("expected bool, got null")
+	}
+
+	*target = val.True()
+	return nil
+}
+
 // DecodeStringMap decodes an HCL attribute to a map[string]string
 func (d *AttributeDecoder) DecodeStringMap(attr *hcl.Attribute, target *map[string]string) error {
-	val, diags := attr.Expr.Value(nil)
+	if err := checkEnvReferences(attr.Expr); err != nil {
+		return err
+	}
+
+	val, diags := attr.Expr.Value(d.ctx)
 	if diags.HasErrors() {
 		return fmt.Errorf("failed to decode map: %s", diags.Error())
 	}
@@ -62,7 +120,104 @@ func (d *AttributeDecoder) DecodeStringMap(attr *hcl.Attribute, target *map[stri
 	it := val.ElementIterator()
 	for it.Next() {
 		key, elemVal := it.Element()
-		(*target)[key.AsString()] = elemVal.AsString()
+		strVal, err := convert.Convert(elemVal, cty.String)
+		if err != nil || strVal.IsNull() {
+			return fmt.Errorf("expected string value for key '%s', got %s",
+				key.AsString(), elemVal.Type().FriendlyName())
+		}
+		(*target)[key.AsString()] = strVal.AsString()
+	}
+
+	return nil
+}
+
+// DecodeStringList decodes an HCL attribute to a []string
+func (d *AttributeDecoder) DecodeStringList(attr *hcl.Attribute, target *[]string) error {
+	if err := checkEnvReferences(attr.Expr); err != nil {
+		return err
+	}
+
+	val, diags := attr.Expr.Value(d.ctx)
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to decode list: %s", diags.Error())
+	}
+
+	if val.IsNull() || !(val.Type().IsListType() || val.Type().IsTupleType() || val.Type().IsSetType()) {
+		return fmt.Errorf("expected list of strings, got %s", val.Type().FriendlyName())
+	}
+
+	*target = make([]string, 0, val.LengthInt())
+	it := val.ElementIterator()
+	for it.Next() {
+		_, elemVal := it.Element()
+		strVal, err := convert.Convert(elemVal, cty.String)
+		if err != nil || strVal.IsNull() {
+			return fmt.Errorf("expected string list element, got %s", elemVal.Type().FriendlyName())
+		}
+		*target = append(*target, strVal.AsString())
+	}
+
+	return nil
+}
+
+// DecodeIntList decodes an HCL attribute to a []int
+func (d *AttributeDecoder) DecodeIntList(attr *hcl.Attribute, target *[]int) error {
+	if err := checkEnvReferences(attr.Expr); err != nil {
+		return err
+	}
+
+	val, diags := attr.Expr.Value(d.ctx)
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to decode list: %s", diags.Error())
+	}
+
+	if val.IsNull() || !(val.Type().IsListType() || val.Type().IsTupleType() || val.Type().IsSetType()) {
+		return fmt.Errorf("expected list of numbers, got %s", val.Type().FriendlyName())
+	}
+
+	*target = make([]int, 0, val.LengthInt())
+	it := val.ElementIterator()
+	for it.Next() {
+		_, elemVal := it.Element()
+		numVal, err := convert.Convert(elemVal, cty.Number)
+		if err != nil || numVal.IsNull() {
+			return fmt.Errorf("expected number list element, got %s", elemVal.Type().FriendlyName())
+		}
+		i, _ := numVal.AsBigFloat().Int64()
+		*target = append(*target, int(i))
+	}
+
+	return nil
+}
+
+// DecodeDuration decodes an HCL attribute to a time.Duration. Strings use
+// Go duration syntax ("500ms", "2s"); numbers are interpreted as seconds.
+func (d *AttributeDecoder) DecodeDuration(attr *hcl.Attribute, target *time.Duration) error {
+	if err := checkEnvReferences(attr.Expr); err != nil {
+		return err
+	}
+
+	val, diags := attr.Expr.Value(d.ctx)
+	if diags.HasErrors() {
+		return fmt.Errorf("failed to decode duration: %s", diags.Error())
+	}
+
+	if val.IsNull() {
+		return fmt.Errorf("expected duration, got null")
+	}
+
+	switch val.Type() {
+	case cty.String:
+		duration, err := time.ParseDuration(val.AsString())
+		if err != nil {
+			return fmt.Errorf("invalid duration for %s: %w", attr.Name, err)
+		}
+		*target = duration
+	case cty.Number:
+		seconds, _ := val.AsBigFloat().Float64()
+		*target = time.Duration(seconds * float64(time.Second))
+	default:
+		return fmt.Errorf("expected duration string or number, got %s", val.Type().FriendlyName())
 	}
 
 	return nil
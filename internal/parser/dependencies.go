@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"jsonrpc/pkg/types"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// requestDependencies returns the sorted, de-duplicated names of requests
+// referenced as `request.<name>` in an expression
+func requestDependencies(expr hcl.Expression) []string {
+	seen := make(map[string]struct{})
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() != "request" || len(traversal) < 2 {
+			continue
+		}
+		if attr, ok := traversal[1].(hcl.TraverseAttr); ok {
+			seen[attr.Name] = struct{}{}
+		}
+	}
+
+	if len(seen) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveParams evaluates the params of a request that references earlier
+// results, using the results of its dependencies keyed by request name.
+// Requests without deferred params are returned unchanged.
+func ResolveParams(req *types.Request, results map[string]*types.ExecutionResult) (*types.Request, error) {
+	if req.ParamsExpr == nil {
+		return req, nil
+	}
+
+	requestValues := make(map[string]cty.Value, len(req.DependsOn))
+	for _, name := range req.DependsOn {
+		result, exists := results[name]
+		if !exists {
+			return nil, fmt.Errorf("dependency '%s' has not been executed", name)
+		}
+		if !result.IsSuccess() {
+			return nil, fmt.Errorf("dependency '%s' failed", name)
+		}
+
+		resultVal, err := resultToCty(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode result of '%s': %w", name, err)
+		}
+		requestValues[name] = cty.ObjectVal(map[string]cty.Value{
+			"result": resultVal,
+		})
+	}
+
+	ctx := req.EvalContext.NewChild()
+	ctx.Variables = map[string]cty.Value{
+		"request": cty.ObjectVal(requestValues),
+	}
+
+	val, diags := req.ParamsExpr.Value(ctx)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to decode params: %s", diags.Error())
+	}
+
+	resolved := *req
+	resolved.Params = val
+	resolved.ProcessedParams = ConvertCtyToGo(val)
+	return &resolved, nil
+}
+
+// resultToCty converts the JSON result of an execution to a cty.Value
+func resultToCty(result *types.ExecutionResult) (cty.Value, error) {
+	if result.Response == nil || len(result.Response.Result) == 0 {
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+
+	ty, err := ctyjson.ImpliedType(result.Response.Result)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyjson.Unmarshal(result.Response.Result, ty)
+}
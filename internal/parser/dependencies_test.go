@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"jsonrpc/pkg/types"
+)
+
+func TestResolveParams(t *testing.T) {
+	path := writeHCL(t, `
+request "get_block_number" {
+  method = "eth_blockNumber"
+}
+
+request "get_block" {
+  method = "eth_getBlockByNumber"
+  params = [request.get_block_number.result, true]
+}
+
+request "get_tx" {
+  method = "eth_getTransactionByHash"
+  params = [request.get_block.result.transactions[0]]
+}
+`)
+
+	hclFile, err := New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	results := map[string]*types.ExecutionResult{
+		"get_block_number": {
+			Response: &types.JSONRPCResponse{Result: json.RawMessage(`"0x10"`)},
+		},
+		"get_block": {
+			Response: &types.JSONRPCResponse{Result: json.RawMessage(`{"transactions": ["0xaa", "0xbb"]}`)},
+		},
+	}
+
+	resolved, err := ResolveParams(hclFile.Requests[1], results)
+	if err != nil {
+		t.Fatalf("ResolveParams() error = %v", err)
+	}
+	if !reflect.DeepEqual(resolved.ProcessedParams, []any{"0x10", true}) {
+		t.Errorf("params = %v, want [0x10 true]", resolved.ProcessedParams)
+	}
+	if hclFile.Requests[1].ProcessedParams != nil {
+		t.Error("ResolveParams() should not modify the original request")
+	}
+
+	resolved, err = ResolveParams(hclFile.Requests[2], results)
+	if err != nil {
+		t.Fatalf("ResolveParams() error = %v", err)
+	}
+	if !reflect.DeepEqual(resolved.ProcessedParams, []any{"0xaa"}) {
+		t.Errorf("params = %v, want [0xaa]", resolved.ProcessedParams)
+	}
+
+	unchanged, err := ResolveParams(hclFile.Requests[0], results)
+	if err != nil || unchanged != hclFile.Requests[0] {
+		t.Errorf("ResolveParams() should return requests without references unchanged")
+	}
+
+	results["get_block_number"] = &types.ExecutionResult{Error: errors.New("connection refused")}
+	_, err = ResolveParams(hclFile.Requests[1], results)
+	if err == nil || !strings.Contains(err.Error(), "dependency 'get_block_number' failed") {
+		t.Errorf("ResolveParams() error = %v, want failed dependency error", err)
+	}
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// envFunc returns the value of an environment variable, falling back to the
+// optional second argument when it is not set
+var envFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "name", Type: cty.String},
+	},
+	VarParam: &function.Parameter{Name: "default", Type: cty.String},
+	Type:     function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		name := args[0].AsString()
+		if value, ok := os.LookupEnv(name); ok {
+			return cty.StringVal(value), nil
+		}
+
+		switch len(args) {
+		case 1:
+			return cty.UnknownVal(cty.String), fmt.Errorf("environment variable '%s' is not set", name)
+		case 2:
+			return args[1], nil
+		default:
+			return cty.UnknownVal(cty.String), fmt.Errorf("env() takes at most one default value")
+		}
+	},
+})
+
+// envObject returns the process environment as an object for `env.NAME` references
+func envObject() cty.Value {
+	values := make(map[string]cty.Value)
+	for _, entry := range os.Environ() {
+		name, value, found := strings.Cut(entry, "=")
+		if !found || name == "" {
+			continue
+		}
+		values[name] = cty.StringVal(value)
+	}
+	return cty.ObjectVal(values)
+}
+
+// checkEnvReferences returns an error for any `env.NAME` reference in the
+// expression whose environment variable is not set
+func checkEnvReferences(expr hcl.Expression) error {
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() != "env" || len(traversal) < 2 {
+			continue
+		}
+		attr, ok := traversal[1].(hcl.TraverseAttr)
+		if !ok {
+			continue
+		}
+		if _, set := os.LookupEnv(attr.Name); !set {
+			return fmt.Errorf("%s: environment variable '%s' is not set",
+				traversal.SourceRange().String(), attr.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"encoding/base64"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// Functions returns the function library available in every HCL expression
+func Functions() map[string]function.Function {
+	return map[string]function.Function{
+		// Strings
+		"upper":        stdlib.UpperFunc,
+		"lower":        stdlib.LowerFunc,
+		"title":        stdlib.TitleFunc,
+		"format":       stdlib.FormatFunc,
+		"formatlist":   stdlib.FormatListFunc,
+		"join":         stdlib.JoinFunc,
+		"split":        stdlib.SplitFunc,
+		"replace":      stdlib.ReplaceFunc,
+		"substr":       stdlib.SubstrFunc,
+		"strlen":       stdlib.StrlenFunc,
+		"trim":         stdlib.TrimFunc,
+		"trimspace":    stdlib.TrimSpaceFunc,
+		"trimprefix":   stdlib.TrimPrefixFunc,
+		"trimsuffix":   stdlib.TrimSuffixFunc,
+		"regex":        stdlib.RegexFunc,
+		"regexall":     stdlib.RegexAllFunc,
+		"strrev":       stdlib.ReverseFunc,
+		"chomp":        stdlib.ChompFunc,
+		"indent":       stdlib.IndentFunc,
+		"parseint":     stdlib.ParseIntFunc,
+		"formatdate":   stdlib.FormatDateFunc,
+		"timeadd":      stdlib.TimeAddFunc,
+		"csvdecode":    stdlib.CSVDecodeFunc,
+		"jsonencode":   stdlib.JSONEncodeFunc,
+		"jsondecode":   stdlib.JSONDecodeFunc,
+		"base64encode": base64EncodeFunc,
+		"base64decode": base64DecodeFunc,
+		"env":          envFunc,
+
+		// Collections
+		"concat":    stdlib.ConcatFunc,
+		"merge":     stdlib.MergeFunc,
+		"length":    stdlib.LengthFunc,
+		"element":   stdlib.ElementFunc,
+		"index":     stdlib.IndexFunc,
+		"keys":      stdlib.KeysFunc,
+		"values":    stdlib.ValuesFunc,
+		"lookup":    stdlib.LookupFunc,
+		"contains":  stdlib.ContainsFunc,
+		"distinct":  stdlib.DistinctFunc,
+		"flatten":   stdlib.FlattenFunc,
+		"reverse":   stdlib.ReverseListFunc,
+		"slice":     stdlib.SliceFunc,
+		"sort":      stdlib.SortFunc,
+		"range":     stdlib.RangeFunc,
+		"zipmap":    stdlib.ZipmapFunc,
+		"compact":   stdlib.CompactFunc,
+		"chunklist": stdlib.ChunklistFunc,
+		"coalesce":  stdlib.CoalesceFunc,
+
+		// Numbers
+		"abs":    stdlib.AbsoluteFunc,
+		"ceil":   stdlib.CeilFunc,
+		"floor":  stdlib.FloorFunc,
+		"min":    stdlib.MinFunc,
+		"max":    stdlib.MaxFunc,
+		"pow":    stdlib.PowFunc,
+		"log":    stdlib.LogFunc,
+		"signum": stdlib.SignumFunc,
+
+		// Type conversion
+		"tostring": stdlib.MakeToFunc(cty.String),
+		"tonumber": stdlib.MakeToFunc(cty.Number),
+		"tobool":   stdlib.MakeToFunc(cty.Bool),
+	}
+}
+
+// base64EncodeFunc encodes a string using standard base64 encoding
+var base64EncodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "str", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.StringVal(base64.StdEncoding.EncodeToString([]byte(args[0].AsString()))), nil
+	},
+})
+
+// base64DecodeFunc decodes a standard base64 string into a UTF-8 string
+var base64DecodeFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "str", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		decoded, err := base64.StdEncoding.DecodeString(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), fmt.Errorf("failed to decode base64 data: %w", err)
+		}
+		if !utf8.Valid(decoded) {
+			return cty.UnknownVal(cty.String), fmt.Errorf("decoded base64 data is not valid UTF-8")
+		}
+		return cty.StringVal(string(decoded)), nil
+	},
+})
@@ -4,24 +4,43 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
+	"jsonrpc/internal/bytesize"
+	"jsonrpc/internal/ratelimit"
+	"jsonrpc/internal/rpcid"
+	"jsonrpc/internal/transport"
+	"jsonrpc/pkg/constants"
 	"jsonrpc/pkg/types"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // Parser handles HCL file parsing
 type Parser struct {
 	hclParser *hclparse.Parser
+	variables map[string]cty.Value
 }
 
 // New creates a new Parser instance
 func New() *Parser {
 	return &Parser{
 		hclParser: hclparse.NewParser(),
+		variables: make(map[string]cty.Value),
+	}
+}
+
+// SetVariables sets variable values that take precedence over the defaults
+// declared in variable blocks (e.g. from --var and --var-file)
+func (p *Parser) SetVariables(variables map[string]cty.Value) {
+	p.variables = make(map[string]cty.Value, len(variables))
+	for name, val := range variables {
+		p.variables[name] = val
 	}
 }
 
@@ -52,10 +71,16 @@ func (p *Parser) ParseFile(filename string) (*types.HCLFile, error) {
 		return nil, fmt.Errorf("failed to extract blocks: %s", diags.Error())
 	}
 
+	// Build the evaluation context from variable and locals blocks
+	ctx, err := p.buildEvalContext(blocks)
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse config blocks
 	for _, block := range blocks {
 		if block.Type == "config" {
-			config, err := p.parseConfigBlock(block)
+			config, err := p.parseConfigBlock(block, ctx)
 			if err != nil {
 				return nil, err
 			}
@@ -68,7 +93,7 @@ func (p *Parser) ParseFile(filename string) (*types.HCLFile, error) {
 	// Parse request blocks
 	for _, block := range blocks {
 		if block.Type == "request" {
-			request, err := p.parseRequestBlock(block)
+			request, err := p.parseRequestBlock(block, ctx)
 			if err != nil {
 				return nil, err
 			}
@@ -76,9 +101,58 @@ func (p *Parser) ParseFile(filename string) (*types.HCLFile, error) {
 		}
 	}
 
+	// Parse subscribe blocks
+	for _, block := range blocks {
+		if block.Type == "subscribe" {
+			subscription, err := p.parseSubscribeBlock(block, ctx)
+			if err != nil {
+				return nil, err
+			}
+			result.Subscriptions = append(result.Subscriptions, subscription)
+		}
+	}
+
+	// Parse batch blocks
+	for _, block := range blocks {
+		if block.Type == "batch" {
+			batch, err := p.parseBatchBlock(block, ctx)
+			if err != nil {
+				return nil, err
+			}
+			result.Batches = append(result.Batches, batch)
+		}
+	}
+
 	return result, nil
 }
 
+// parseBatchBlock parses a batch block
+func (p *Parser) parseBatchBlock(block *hcl.Block, ctx *hcl.EvalContext) (*types.Batch, error) {
+	if len(block.Labels) == 0 {
+		return nil, fmt.Errorf("batch block must have a name label")
+	}
+
+	batch := &types.Batch{Name: block.Labels[0]}
+	decoder := NewAttributeDecoder(ctx)
+
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "requests", Required: true},
+		},
+	}
+
+	content, diags := block.Body.Content(schema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to decode batch '%s': %s", batch.Name, diags.Error())
+	}
+
+	if err := decoder.DecodeStringList(content.Attributes["requests"], &batch.Requests); err != nil {
+		return nil, fmt.Errorf("failed to decode requests in batch '%s': %w", batch.Name, err)
+	}
+
+	return batch, nil
+}
+
 // extractBlocks extracts all blocks from an HCL body
 func (p *Parser) extractBlocks(body hcl.Body) (hcl.Blocks, hcl.Diagnostics) {
 	// Check if this is an hclsyntax.Body (native syntax)
@@ -111,15 +185,29 @@ func (p *Parser) getConfigName(block *hcl.Block) string {
 }
 
 // parseConfigBlock parses a config block
-func (p *Parser) parseConfigBlock(block *hcl.Block) (*types.Config, error) {
+func (p *Parser) parseConfigBlock(block *hcl.Block, ctx *hcl.EvalContext) (*types.Config, error) {
 	config := types.NewConfig()
-	decoder := NewAttributeDecoder()
+	decoder := NewAttributeDecoder(ctx)
 
 	schema := &hcl.BodySchema{
 		Attributes: []hcl.AttributeSchema{
 			{Name: "url"},
 			{Name: "headers"},
 			{Name: "timeout"},
+			{Name: "command"},
+			{Name: "framing"},
+			{Name: "rate_limit"},
+			{Name: "credential_command"},
+			{Name: "credential_header"},
+			{Name: "credential_cache"},
+			{Name: "id_strategy"},
+			{Name: "max_response_size"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "retry"},
+			{Type: "tls"},
+			{Type: "auth"},
+			{Type: "signing"},
 		},
 	}
 
@@ -150,17 +238,251 @@ func (p *Parser) parseConfigBlock(block *hcl.Block) (*types.Config, error) {
 		}
 	}
 
+	// Decode command
+	if attr, exists := content.Attributes["command"]; exists {
+		if err := decoder.DecodeStringList(attr, &config.Command); err != nil {
+			return nil, err
+		}
+		if len(config.Command) == 0 {
+			return nil, fmt.Errorf("config '%s' has an empty command", p.getConfigName(block))
+		}
+		if config.URL != "" {
+			return nil, fmt.Errorf("config '%s' sets both url and command", p.getConfigName(block))
+		}
+	}
+
+	// Decode framing
+	if attr, exists := content.Attributes["framing"]; exists {
+		if err := decoder.DecodeString(attr, &config.Framing); err != nil {
+			return nil, err
+		}
+		if !isValidFraming(config.Framing) {
+			return nil, fmt.Errorf("invalid framing '%s' in config '%s' (expected ndjson or content-length)",
+				config.Framing, p.getConfigName(block))
+		}
+	}
+
+	// Decode rate limit
+	if attr, exists := content.Attributes["rate_limit"]; exists {
+		if err := decoder.DecodeString(attr, &config.RateLimit); err != nil {
+			return nil, err
+		}
+		if _, err := ratelimit.Parse(config.RateLimit); err != nil {
+			return nil, fmt.Errorf("config '%s': %w", p.getConfigName(block), err)
+		}
+	}
+
+	// Decode response size limit
+	if attr, exists := content.Attributes["max_response_size"]; exists {
+		var size string
+		if err := decoder.DecodeString(attr, &size); err != nil {
+			return nil, err
+		}
+		limit, err := bytesize.Parse(size)
+		if err != nil {
+			return nil, fmt.Errorf("config '%s': max_response_size: %w", p.getConfigName(block), err)
+		}
+		config.MaxResponseSize = limit
+	}
+
+	// Decode id strategy
+	if attr, exists := content.Attributes["id_strategy"]; exists {
+		if err := decodeIDStrategy(decoder, attr, &config.IDStrategy); err != nil {
+			return nil, fmt.Errorf("config '%s': %w", p.getConfigName(block), err)
+		}
+	}
+
+	// Decode credential helper
+	credential, err := p.parseCredentialHelper(content, decoder)
+	if err != nil {
+		return nil, fmt.Errorf("config '%s': %w", p.getConfigName(block), err)
+	}
+	config.Credential = credential
+
+	// Decode retry block
+	retry, err := p.parseRetryBlocks(content, decoder)
+	if err != nil {
+		return nil, fmt.Errorf("config '%s': %w", p.getConfigName(block), err)
+	}
+	config.Retry = retry
+
+	// Decode tls block
+	tlsBlocks := content.Blocks.OfType("tls")
+	if len(tlsBlocks) > 1 {
+		return nil, fmt.Errorf("config '%s' has more than one tls block", p.getConfigName(block))
+	}
+	for _, tlsBlock := range tlsBlocks {
+		tlsConfig, err := p.parseTLSBlock(tlsBlock, decoder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tls block of config '%s': %w", p.getConfigName(block), err)
+		}
+		config.TLS = tlsConfig
+	}
+
+	// Decode auth block
+	authConfig, err := p.parseAuthBlocks(content, decoder)
+	if err != nil {
+		return nil, fmt.Errorf("config '%s': %w", p.getConfigName(block), err)
+	}
+	config.Auth = authConfig
+
+	// Decode signing block
+	signingBlocks := content.Blocks.OfType("signing")
+	if len(signingBlocks) > 1 {
+		return nil, fmt.Errorf("config '%s' has more than one signing block", p.getConfigName(block))
+	}
+	for _, signingBlock := range signingBlocks {
+		signing, err := p.parseSigningBlock(signingBlock, decoder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode signing block of config '%s': %w", p.getConfigName(block), err)
+		}
+		config.Signing = signing
+	}
+
 	return config, nil
 }
 
+// parseTLSBlock parses a tls block. Files are read when the first request
+// using the config is sent.
+func (p *Parser) parseTLSBlock(block *hcl.Block, decoder *AttributeDecoder) (*types.TLSConfig, error) {
+	tlsConfig := &types.TLSConfig{}
+
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "ca_file"},
+			{Name: "cert_file"},
+			{Name: "key_file"},
+			{Name: "server_name"},
+			{Name: "insecure_skip_verify"},
+			{Name: "min_version"},
+		},
+	}
+
+	content, diags := block.Body.Content(schema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("%s", diags.Error())
+	}
+
+	// Decode string settings
+	for name, target := range map[string]*string{
+		"ca_file":     &tlsConfig.CAFile,
+		"cert_file":   &tlsConfig.CertFile,
+		"key_file":    &tlsConfig.KeyFile,
+		"server_name": &tlsConfig.ServerName,
+		"min_version": &tlsConfig.MinVersion,
+	} {
+		if attr, exists := content.Attributes[name]; exists {
+			if err := decoder.DecodeString(attr, target); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Decode insecure_skip_verify
+	if attr, exists := content.Attributes["insecure_skip_verify"]; exists {
+		var insecure bool
+		if err := decoder.DecodeBool(attr, &insecure); err != nil {
+			return nil, err
+		}
+		tlsConfig.InsecureSkipVerify = &insecure
+	}
+
+	if tlsConfig.MinVersion != "" && !transport.IsValidTLSVersion(tlsConfig.MinVersion) {
+		return nil, fmt.Errorf("invalid min_version '%s' (expected 1.0, 1.1, 1.2 or 1.3)", tlsConfig.MinVersion)
+	}
+
+	return tlsConfig, nil
+}
+
+// parseSigningBlock parses a signing block and fills in defaults
+func (p *Parser) parseSigningBlock(block *hcl.Block, decoder *AttributeDecoder) (*types.SigningConfig, error) {
+	signing := &types.SigningConfig{
+		Algorithm:       types.SigningHMACSHA256,
+		SignatureHeader: constants.DefaultSignatureHeader,
+		TimestampHeader: constants.DefaultTimestampHeader,
+		NonceHeader:     constants.DefaultNonceHeader,
+		Template:        constants.DefaultSigningTemplate,
+		Encoding:        "hex",
+	}
+
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "algorithm"},
+			{Name: "secret"},
+			{Name: "secret_env"},
+			{Name: "secret_file"},
+			{Name: "signature_header"},
+			{Name: "timestamp_header"},
+			{Name: "nonce_header"},
+			{Name: "template"},
+			{Name: "encoding"},
+		},
+	}
+
+	content, diags := block.Body.Content(schema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("%s", diags.Error())
+	}
+
+	targets := map[string]*string{
+		"algorithm":        &signing.Algorithm,
+		"secret":           &signing.Secret,
+		"secret_env":       &signing.SecretEnv,
+		"secret_file":      &signing.SecretFile,
+		"signature_header": &signing.SignatureHeader,
+		"timestamp_header": &signing.TimestampHeader,
+		"nonce_header":     &signing.NonceHeader,
+		"template":         &signing.Template,
+		"encoding":         &signing.Encoding,
+	}
+	for name, target := range targets {
+		if attr, exists := content.Attributes[name]; exists {
+			if err := decoder.DecodeString(attr, target); err != nil {
+				return nil, err
+			}
+			if *target == "" {
+				return nil, fmt.Errorf("%s is empty", name)
+			}
+		}
+	}
+
+	if signing.Algorithm != types.SigningHMACSHA256 {
+		return nil, fmt.Errorf("invalid algorithm '%s' (expected %s)", signing.Algorithm, types.SigningHMACSHA256)
+	}
+	if signing.Encoding != "hex" && signing.Encoding != "base64" {
+		return nil, fmt.Errorf("invalid encoding '%s' (expected hex or base64)", signing.Encoding)
+	}
+
+	sources := 0
+	for _, name := range []string{"secret", "secret_env", "secret_file"} {
+		if _, exists := content.Attributes[name]; exists {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, fmt.Errorf("exactly one of secret, secret_env or secret_file must be set")
+	}
+
+	if !strings.Contains(signing.Template, "{body}") {
+		return nil, fmt.Errorf("template must contain {body}")
+	}
+
+	return signing, nil
+}
+
+// isValidFraming reports whether framing is a supported stdio framing
+func isValidFraming(framing string) bool {
+	return framing == "ndjson" || framing == "content-length"
+}
+
 // parseRequestBlock parses a request block
-func (p *Parser) parseRequestBlock(block *hcl.Block) (*types.Request, error) {
+func (p *Parser) parseRequestBlock(block *hcl.Block, ctx *hcl.EvalContext) (*types.Request, error) {
 	if len(block.Labels) == 0 {
 		return nil, fmt.Errorf("request block must have a name label")
 	}
 
 	request := types.NewRequest(block.Labels[0])
-	decoder := NewAttributeDecoder()
+	decoder := NewAttributeDecoder(ctx)
 
 	schema := &hcl.BodySchema{
 		Attributes: []hcl.AttributeSchema{
@@ -170,6 +492,13 @@ func (p *Parser) parseRequestBlock(block *hcl.Block) (*types.Request, error) {
 			{Name: "headers"},
 			{Name: "timeout"},
 			{Name: "config"},
+			{Name: "notification"},
+			{Name: "id_strategy"},
+		},
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "expect"},
+			{Type: "retry"},
+			{Type: "auth"},
 		},
 	}
 
@@ -185,43 +514,523 @@ func (p *Parser) parseRequestBlock(block *hcl.Block) (*types.Request, error) {
 		}
 	}
 
-	// Decode params (complex type)
+	// Decode params (complex type). Params referencing other requests'
+	// results are resolved at execution time.
 	if attr, exists := content.Attributes["params"]; exists {
-		val, attrDiags := attr.Expr.Value(nil)
-		if attrDiags.HasErrors() {
-			return nil, fmt.Errorf("failed to decode params: %s", attrDiags.Error())
+		if deps := requestDependencies(attr.Expr); len(deps) > 0 {
+			if err := checkEnvReferences(attr.Expr); err != nil {
+				return nil, err
+			}
+			request.DependsOn = deps
+			request.ParamsExpr = attr.Expr
+			request.EvalContext = ctx
+		} else {
+			val, err := decoder.DecodeValue(attr)
+			if err != nil {
+				return nil, err
+			}
+			request.Params = val
+			request.ProcessedParams = ConvertCtyToGo(val)
 		}
-		request.Params = val
-		request.ProcessedParams = ConvertCtyToGo(val)
 	}
 
+	if err := p.decodeRequestOverrides(content, decoder, request); err != nil {
+		return nil, err
+	}
+
+	// Decode notification flag
+	if attr, exists := content.Attributes["notification"]; exists {
+		if err := decoder.DecodeBool(attr, &request.Notification); err != nil {
+			return nil, err
+		}
+	}
+
+	// Decode id strategy
+	if attr, exists := content.Attributes["id_strategy"]; exists {
+		if request.Notification {
+			return nil, fmt.Errorf("request '%s' is a notification and has no id_strategy", request.Name)
+		}
+		if err := decodeIDStrategy(decoder, attr, &request.IDStrategy); err != nil {
+			return nil, fmt.Errorf("request '%s': %w", request.Name, err)
+		}
+	}
+
+	// Decode expect block
+	for _, expectBlock := range content.Blocks.OfType("expect") {
+		if request.Expect != nil {
+			return nil, fmt.Errorf("request '%s' has more than one expect block", request.Name)
+		}
+		expect, err := p.parseExpectBlock(expectBlock, decoder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode expect block of request '%s': %w", request.Name, err)
+		}
+		request.Expect = expect
+	}
+	if request.Notification && request.Expect != nil {
+		return nil, fmt.Errorf("request '%s' is a notification and gets no response to expect", request.Name)
+	}
+
+	// Decode retry block
+	retry, err := p.parseRetryBlocks(content, decoder)
+	if err != nil {
+		return nil, fmt.Errorf("request '%s': %w", request.Name, err)
+	}
+	request.Retry = retry
+
+	// Decode auth block
+	authConfig, err := p.parseAuthBlocks(content, decoder)
+	if err != nil {
+		return nil, fmt.Errorf("request '%s': %w", request.Name, err)
+	}
+	request.Auth = authConfig
+
+	return request, nil
+}
+
+// decodeIDStrategy decodes and checks an id_strategy attribute
+func decodeIDStrategy(decoder *AttributeDecoder, attr *hcl.Attribute, target *string) error {
+	if err := decoder.DecodeString(attr, target); err != nil {
+		return err
+	}
+	_, err := rpcid.Parse(*target)
+	return err
+}
+
+// decodeRequestOverrides decodes the url, headers, timeout and config
+// attributes shared by request and subscribe blocks
+func (p *Parser) decodeRequestOverrides(
+	content *hcl.BodyContent,
+	decoder *AttributeDecoder,
+	request *types.Request,
+) error {
 	// Decode URL
 	if attr, exists := content.Attributes["url"]; exists {
 		if err := decoder.DecodeString(attr, &request.URL); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	// Decode headers
 	if attr, exists := content.Attributes["headers"]; exists {
 		if err := decoder.DecodeStringMap(attr, &request.Headers); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	// Decode timeout
 	if attr, exists := content.Attributes["timeout"]; exists {
 		if err := decoder.DecodeInt(attr, &request.Timeout); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	// Decode config reference
 	if attr, exists := content.Attributes["config"]; exists {
 		if err := decoder.DecodeString(attr, &request.Config); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseSubscribeBlock parses a subscribe block. The subscribe call is kept
+// as a request so that it goes through the same configuration merging.
+func (p *Parser) parseSubscribeBlock(block *hcl.Block, ctx *hcl.EvalContext) (*types.Subscription, error) {
+	if len(block.Labels) == 0 {
+		return nil, fmt.Errorf("subscribe block must have a name label")
+	}
+
+	request := types.NewRequest(block.Labels[0])
+	subscription := &types.Subscription{Name: request.Name, Request: request}
+	decoder := NewAttributeDecoder(ctx)
+
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "method", Required: true},
+			{Name: "params"},
+			{Name: "unsubscribe"},
+			{Name: "url"},
+			{Name: "headers"},
+			{Name: "timeout"},
+			{Name: "config"},
+		},
+	}
+
+	content, diags := block.Body.Content(schema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to decode subscription '%s': %s", subscription.Name, diags.Error())
+	}
+
+	if err := decoder.DecodeString(content.Attributes["method"], &request.Method); err != nil {
+		return nil, err
+	}
+
+	if attr, exists := content.Attributes["params"]; exists {
+		if deps := requestDependencies(attr.Expr); len(deps) > 0 {
+			return nil, fmt.Errorf("subscription '%s' cannot reference request results", subscription.Name)
+		}
+		val, err := decoder.DecodeValue(attr)
+		if err != nil {
 			return nil, err
 		}
+		request.Params = val
+		request.ProcessedParams = ConvertCtyToGo(val)
 	}
 
-	return request, nil
+	// The unsubscribe method defaults to the subscribe method with
+	// "subscribe" replaced, e.g. eth_subscribe -> eth_unsubscribe
+	subscription.Unsubscribe = strings.Replace(request.Method, "subscribe", "unsubscribe", 1)
+	if attr, exists := content.Attributes["unsubscribe"]; exists {
+		if err := decoder.DecodeString(attr, &subscription.Unsubscribe); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.decodeRequestOverrides(content, decoder, request); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// parseExpectBlock parses the assertions of a request's expect block
+func (p *Parser) parseExpectBlock(block *hcl.Block, decoder *AttributeDecoder) (*types.Expectation, error) {
+	expect := &types.Expectation{}
+
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "result_type"},
+			{Name: "equals"},
+			{Name: "jsonpath"},
+			{Name: "error_code"},
+			{Name: "max_duration"},
+		},
+	}
+
+	content, diags := block.Body.Content(schema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("%s", diags.Error())
+	}
+
+	// Decode expected result type
+	if attr, exists := content.Attributes["result_type"]; exists {
+		if err := decoder.DecodeString(attr, &expect.ResultType); err != nil {
+			return nil, err
+		}
+		if !isValidResultType(expect.ResultType) {
+			return nil, fmt.Errorf("invalid result_type '%s' (expected string, number, bool, object, array or null)",
+				expect.ResultType)
+		}
+	}
+
+	// Decode expected result value
+	if attr, exists := content.Attributes["equals"]; exists {
+		val, err := decoder.DecodeValue(attr)
+		if err != nil {
+			return nil, err
+		}
+		expect.Equals = ConvertCtyToGo(val)
+		expect.HasEquals = true
+	}
+
+	// Decode JSONPath checks (path => expected value)
+	if attr, exists := content.Attributes["jsonpath"]; exists {
+		val, err := decoder.DecodeValue(attr)
+		if err != nil {
+			return nil, err
+		}
+		if !val.Type().IsMapType() && !val.Type().IsObjectType() {
+			return nil, fmt.Errorf("expected jsonpath to be a map of path to value, got %s", val.Type().FriendlyName())
+		}
+		expect.JSONPath = convertCtyMap(val)
+	}
+
+	// Decode expected RPC error code
+	if attr, exists := content.Attributes["error_code"]; exists {
+		var code int
+		if err := decoder.DecodeInt(attr, &code); err != nil {
+			return nil, err
+		}
+		expect.ErrorCode = &code
+	}
+
+	// Decode maximum duration
+	if attr, exists := content.Attributes["max_duration"]; exists {
+		if err := decoder.DecodeDuration(attr, &expect.MaxDuration); err != nil {
+			return nil, err
+		}
+	}
+
+	return expect, nil
+}
+
+// parseRetryBlocks parses the optional retry block of a config or request
+func (p *Parser) parseRetryBlocks(content *hcl.BodyContent, decoder *AttributeDecoder) (*types.RetryPolicy, error) {
+	blocks := content.Blocks.OfType("retry")
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	if len(blocks) > 1 {
+		return nil, fmt.Errorf("more than one retry block")
+	}
+
+	retry, err := p.parseRetryBlock(blocks[0], decoder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode retry block: %w", err)
+	}
+	return retry, nil
+}
+
+// parseRetryBlock parses a retry block. Attributes that are not set are
+// left unset so that they can be inherited when configurations are merged.
+func (p *Parser) parseRetryBlock(block *hcl.Block, decoder *AttributeDecoder) (*types.RetryPolicy, error) {
+	retry := &types.RetryPolicy{}
+
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "max_attempts"},
+			{Name: "backoff_base"},
+			{Name: "backoff_max"},
+			{Name: "jitter"},
+			{Name: "retry_on_status"},
+			{Name: "retry_on_codes"},
+			{Name: "honor_retry_after"},
+		},
+	}
+
+	content, diags := block.Body.Content(schema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("%s", diags.Error())
+	}
+
+	// Decode maximum number of attempts, including the first one
+	if attr, exists := content.Attributes["max_attempts"]; exists {
+		if err := decoder.DecodeInt(attr, &retry.MaxAttempts); err != nil {
+			return nil, err
+		}
+		if retry.MaxAttempts < 1 {
+			return nil, fmt.Errorf("max_attempts must be at least 1, got %d", retry.MaxAttempts)
+		}
+	}
+
+	// Decode backoff durations
+	for name, target := range map[string]*time.Duration{
+		"backoff_base": &retry.BackoffBase,
+		"backoff_max":  &retry.BackoffMax,
+	} {
+		if attr, exists := content.Attributes[name]; exists {
+			if err := decoder.DecodeDuration(attr, target); err != nil {
+				return nil, err
+			}
+			if *target <= 0 {
+				return nil, fmt.Errorf("%s must be positive", name)
+			}
+		}
+	}
+
+	// Decode flags
+	for name, target := range map[string]**bool{
+		"jitter":            &retry.Jitter,
+		"honor_retry_after": &retry.HonorRetryAfter,
+	} {
+		if attr, exists := content.Attributes[name]; exists {
+			var value bool
+			if err := decoder.DecodeBool(attr, &value); err != nil {
+				return nil, err
+			}
+			*target = &value
+		}
+	}
+
+	// Decode HTTP statuses and JSON-RPC error codes to retry on
+	if attr, exists := content.Attributes["retry_on_status"]; exists {
+		if err := decoder.DecodeIntList(attr, &retry.RetryOnStatus); err != nil {
+			return nil, err
+		}
+	}
+	if attr, exists := content.Attributes["retry_on_codes"]; exists {
+		if err := decoder.DecodeIntList(attr, &retry.RetryOnCodes); err != nil {
+			return nil, err
+		}
+	}
+
+	return retry, nil
+}
+
+// parseCredentialHelper parses the credential_* attributes of a config block.
+// It returns nil if there is no credential_command.
+func (p *Parser) parseCredentialHelper(
+	content *hcl.BodyContent,
+	decoder *AttributeDecoder,
+) (*types.CredentialHelper, error) {
+	attr, exists := content.Attributes["credential_command"]
+	if !exists {
+		for _, name := range []string{"credential_header", "credential_cache"} {
+			if _, exists := content.Attributes[name]; exists {
+				return nil, fmt.Errorf("%s requires credential_command", name)
+			}
+		}
+		return nil, nil
+	}
+
+	helper := &types.CredentialHelper{Header: "Authorization"}
+	if err := decoder.DecodeStringList(attr, &helper.Command); err != nil {
+		return nil, err
+	}
+	if len(helper.Command) == 0 {
+		return nil, fmt.Errorf("credential_command is empty")
+	}
+
+	if attr, exists := content.Attributes["credential_header"]; exists {
+		if err := decoder.DecodeString(attr, &helper.Header); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(helper.Header) == "" {
+			return nil, fmt.Errorf("credential_header is empty")
+		}
+	}
+	if attr, exists := content.Attributes["credential_cache"]; exists {
+		if err := decoder.DecodeBool(attr, &helper.Cache); err != nil {
+			return nil, err
+		}
+	}
+
+	return helper, nil
+}
+
+// authAttributes lists the attributes each auth type accepts besides type
+var authAttributes = map[string][]string{
+	types.AuthBasic:                   {"username", "password"},
+	types.AuthBearer:                  {"token", "token_env", "token_file"},
+	types.AuthOAuth2ClientCredentials: {"token_url", "client_id", "client_secret", "scopes"},
+	types.AuthJWTHS256:                {"secret_file"},
+}
+
+// parseAuthBlocks parses the optional auth block of a config or request
+func (p *Parser) parseAuthBlocks(content *hcl.BodyContent, decoder *AttributeDecoder) (*types.AuthConfig, error) {
+	blocks := content.Blocks.OfType("auth")
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	if len(blocks) > 1 {
+		return nil, fmt.Errorf("more than one auth block")
+	}
+
+	authConfig, err := p.parseAuthBlock(blocks[0], decoder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode auth block: %w", err)
+	}
+	return authConfig, nil
+}
+
+// parseAuthBlock parses an auth block and checks that it has the
+// attributes its type needs
+func (p *Parser) parseAuthBlock(block *hcl.Block, decoder *AttributeDecoder) (*types.AuthConfig, error) {
+	authConfig := &types.AuthConfig{}
+
+	schema := &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "type", Required: true}},
+	}
+	for _, names := range authAttributes {
+		for _, name := range names {
+			schema.Attributes = append(schema.Attributes, hcl.AttributeSchema{Name: name})
+		}
+	}
+
+	content, diags := block.Body.Content(schema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("%s", diags.Error())
+	}
+
+	if err := decoder.DecodeString(content.Attributes["type"], &authConfig.Type); err != nil {
+		return nil, err
+	}
+	allowed, ok := authAttributes[authConfig.Type]
+	if !ok {
+		return nil, fmt.Errorf("invalid auth type '%s' (expected basic, bearer, oauth2_client_credentials or jwt_hs256)",
+			authConfig.Type)
+	}
+	for name, attr := range content.Attributes {
+		if name != "type" && !slices.Contains(allowed, name) {
+			return nil, fmt.Errorf("attribute '%s' is not supported by auth type '%s'", attr.Name, authConfig.Type)
+		}
+	}
+
+	// Decode string settings
+	for name, target := range map[string]*string{
+		"username":      &authConfig.Username,
+		"password":      &authConfig.Password,
+		"token":         &authConfig.Token,
+		"token_env":     &authConfig.TokenEnv,
+		"token_file":    &authConfig.TokenFile,
+		"token_url":     &authConfig.TokenURL,
+		"client_id":     &authConfig.ClientID,
+		"client_secret": &authConfig.ClientSecret,
+		"secret_file":   &authConfig.SecretFile,
+	} {
+		if attr, exists := content.Attributes[name]; exists {
+			if err := decoder.DecodeString(attr, target); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Decode scopes
+	if attr, exists := content.Attributes["scopes"]; exists {
+		if err := decoder.DecodeStringList(attr, &authConfig.Scopes); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateAuth(authConfig); err != nil {
+		return nil, err
+	}
+	return authConfig, nil
+}
+
+// validateAuth checks that an auth block has the settings its type requires
+func validateAuth(authConfig *types.AuthConfig) error {
+	var missing []string
+	require := func(name, value string) {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+
+	switch authConfig.Type {
+	case types.AuthBasic:
+		require("username", authConfig.Username)
+	case types.AuthBearer:
+		sources := 0
+		for _, source := range []string{authConfig.Token, authConfig.TokenEnv, authConfig.TokenFile} {
+			if source != "" {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return fmt.Errorf("bearer auth needs exactly one of token, token_env or token_file")
+		}
+	case types.AuthOAuth2ClientCredentials:
+		require("token_url", authConfig.TokenURL)
+		require("client_id", authConfig.ClientID)
+		require("client_secret", authConfig.ClientSecret)
+	case types.AuthJWTHS256:
+		require("secret_file", authConfig.SecretFile)
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%s auth requires %s", authConfig.Type, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// isValidResultType reports whether t is a JSON type name usable in result_type
+func isValidResultType(t string) bool {
+	switch t {
+	case "string", "number", "bool", "object", "array", "null":
+		return true
+	default:
+		return false
+	}
 }
@@ -0,0 +1,875 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// writeHCL writes HCL source to a temporary file and returns its path
+func writeHCL(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "requests.hcl")
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatalf("failed to write HCL file: %v", err)
+	}
+	return path
+}
+
+func TestParser_ParseFile_VariablesAndLocals(t *testing.T) {
+	path := writeHCL(t, `
+variable "address" {
+  default = "0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"
+}
+
+variable "host" {
+  default = "rpc.example.com"
+}
+
+locals {
+  url     = "https://${var.host}/v1"
+  block   = format("0x%x", local.height)
+  height  = 4096
+}
+
+config {
+  url = local.url
+  headers = {
+    X-Client = upper("rpc-cli")
+  }
+}
+
+request "get_balance" {
+  method = "eth_getBalance"
+  params = [var.address, local.block]
+}
+
+request "encoded" {
+  method  = "test.encode"
+  params  = [base64encode("hello"), jsonencode({ a = 1 })]
+  headers = merge({ A = "1" }, { B = "2" })
+}
+`)
+
+	hclFile, err := New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	config := hclFile.Configs["default"]
+	if config.URL != "https://rpc.example.com/v1" {
+		t.Errorf("config URL = %s, want https://rpc.example.com/v1", config.URL)
+	}
+	if config.Headers["X-Client"] != "RPC-CLI" {
+		t.Errorf("config header X-Client = %s, want RPC-CLI", config.Headers["X-Client"])
+	}
+
+	wantParams := []any{"0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0", "0x1000"}
+	if !reflect.DeepEqual(hclFile.Requests[0].ProcessedParams, wantParams) {
+		t.Errorf("params = %v, want %v", hclFile.Requests[0].ProcessedParams, wantParams)
+	}
+
+	wantEncoded := []any{"aGVsbG8=", `{"a":1}`}
+	if !reflect.DeepEqual(hclFile.Requests[1].ProcessedParams, wantEncoded) {
+		t.Errorf("params = %v, want %v", hclFile.Requests[1].ProcessedParams, wantEncoded)
+	}
+
+	wantHeaders := map[string]string{"A": "1", "B": "2"}
+	if !reflect.DeepEqual(hclFile.Requests[1].Headers, wantHeaders) {
+		t.Errorf("headers = %v, want %v", hclFile.Requests[1].Headers, wantHeaders)
+	}
+}
+
+func TestParser_ParseFile_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		errMsg string
+	}{
+		{
+			name: "locals cycle",
+			src: `
+locals {
+  a = local.b
+  b = local.a
+}
+`,
+			errMsg: "reference cycle: a, b",
+		},
+		{
+			name: "duplicate variable",
+			src: `
+variable "x" { default = 1 }
+variable "x" { default = 2 }
+`,
+			errMsg: "declared more than once",
+		},
+		{
+			name: "variable without default",
+			src: `
+variable "x" {}
+`,
+			errMsg: "has no default value",
+		},
+		{
+			name: "unknown variable",
+			src: `
+request "test" {
+  method = "test"
+  params = [var.missing]
+}
+`,
+			errMsg: "failed to decode params",
+		},
+		{
+			name: "config with url and command",
+			src: `
+config "local" {
+  url     = "http://localhost:8545"
+  command = ["./server"]
+}
+`,
+			errMsg: "sets both url and command",
+		},
+		{
+			name: "config with empty command",
+			src: `
+config "local" {
+  command = []
+}
+`,
+			errMsg: "has an empty command",
+		},
+		{
+			name: "config with unknown framing",
+			src: `
+config "local" {
+  command = ["./server"]
+  framing = "xml"
+}
+`,
+			errMsg: "invalid framing 'xml'",
+		},
+		{
+			name: "retry with zero attempts",
+			src: `
+request "test" {
+  method = "test"
+  retry {
+    max_attempts = 0
+  }
+}
+`,
+			errMsg: "max_attempts must be at least 1",
+		},
+		{
+			name: "duplicate retry block",
+			src: `
+config "slow" {
+  retry {}
+  retry {}
+}
+`,
+			errMsg: "more than one retry block",
+		},
+		{
+			name: "invalid rate limit",
+			src: `
+config "public" {
+  rate_limit = "25 per second"
+}
+`,
+			errMsg: "config 'public': invalid rate '25 per second'",
+		},
+		{
+			name: "invalid max_response_size",
+			src: `
+config "archive" {
+  max_response_size = "1.5GB"
+}
+`,
+			errMsg: "config 'archive': max_response_size: invalid size '1.5GB'",
+		},
+		{
+			name: "invalid tls min_version",
+			src: `
+config "internal" {
+  tls {
+    min_version = "1.4"
+  }
+}
+`,
+			errMsg: "invalid min_version '1.4'",
+		},
+		{
+			name: "unknown auth type",
+			src: `
+config "node" {
+  auth {
+    type = "digest"
+  }
+}
+`,
+			errMsg: "invalid auth type 'digest'",
+		},
+		{
+			name: "auth attribute of another type",
+			src: `
+config "node" {
+  auth {
+    type     = "bearer"
+    token    = "abc"
+    username = "alice"
+  }
+}
+`,
+			errMsg: "attribute 'username' is not supported by auth type 'bearer'",
+		},
+		{
+			name: "bearer with two token sources",
+			src: `
+request "test" {
+  method = "test"
+  auth {
+    type       = "bearer"
+    token      = "abc"
+    token_file = "token.txt"
+  }
+}
+`,
+			errMsg: "exactly one of token, token_env or token_file",
+		},
+		{
+			name: "oauth2 without client secret",
+			src: `
+config "node" {
+  auth {
+    type      = "oauth2_client_credentials"
+    token_url = "https://auth.example.com/token"
+    client_id = "cli"
+  }
+}
+`,
+			errMsg: "oauth2_client_credentials auth requires client_secret",
+		},
+		{
+			name: "empty credential command",
+			src: `
+config "staging" {
+  credential_command = []
+}
+`,
+			errMsg: "config 'staging': credential_command is empty",
+		},
+		{
+			name: "credential header without command",
+			src: `
+config "staging" {
+  credential_header = "X-API-Key"
+}
+`,
+			errMsg: "credential_header requires credential_command",
+		},
+		{
+			name: "signing without secret",
+			src: `
+config "gateway" {
+  signing {
+    signature_header = "X-Sig"
+  }
+}
+`,
+			errMsg: "exactly one of secret, secret_env or secret_file must be set",
+		},
+		{
+			name: "signing template without body",
+			src: `
+config "gateway" {
+  signing {
+    secret   = "s3cret"
+    template = "{timestamp}"
+  }
+}
+`,
+			errMsg: "template must contain {body}",
+		},
+		{
+			name: "unknown signing algorithm",
+			src: `
+config "gateway" {
+  signing {
+    algorithm = "hmac-sha1"
+    secret    = "s3cret"
+  }
+}
+`,
+			errMsg: "invalid algorithm 'hmac-sha1'",
+		},
+		{
+			name: "invalid id_strategy",
+			src: `
+config {
+  id_strategy = "random"
+}
+`,
+			errMsg: "invalid id_strategy 'random'",
+		},
+		{
+			name: "notification with id_strategy",
+			src: `
+request "ping" {
+  method       = "ping"
+  notification = true
+  id_strategy  = "uuid"
+}
+`,
+			errMsg: "request 'ping' is a notification and has no id_strategy",
+		},
+		{
+			name: "notification with expect",
+			src: `
+request "ping" {
+  method       = "ping"
+  notification = true
+  expect {
+    result_type = "string"
+  }
+}
+`,
+			errMsg: "request 'ping' is a notification and gets no response to expect",
+		},
+		{
+			name: "number for a string",
+			src: `
+request "test" {
+  method = 42
+}
+`,
+			errMsg: "expected string, got number",
+		},
+		{
+			name: "bool for a string",
+			src: `
+config "local" {
+  url = true
+}
+`,
+			errMsg: "expected string, got bool",
+		},
+		{
+			name: "string for a number",
+			src: `
+config "local" {
+  url     = "http://localhost:8545"
+  timeout = "30"
+}
+`,
+			errMsg: "expected number, got string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().ParseFile(writeHCL(t, tt.src))
+			if err == nil {
+				t.Fatal("ParseFile() expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("ParseFile() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestParser_ParseFile_CommandConfig(t *testing.T) {
+	src := `
+config "lsp" {
+  command = ["./server", "--stdio"]
+  framing = "content-length"
+}
+`
+	hclFile, err := New().ParseFile(writeHCL(t, src))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	config := hclFile.Configs["lsp"]
+	if !reflect.DeepEqual(config.Command, []string{"./server", "--stdio"}) {
+		t.Errorf("command = %v, want [./server --stdio]", config.Command)
+	}
+	if config.Framing != "content-length" {
+		t.Errorf("framing = %s, want content-length", config.Framing)
+	}
+}
+
+func TestParser_ParseFile_RetryBlock(t *testing.T) {
+	src := `
+config "public" {
+  url = "https://rpc.example.com"
+  retry {
+    max_attempts      = 5
+    backoff_base      = "250ms"
+    backoff_max       = 8
+    jitter            = false
+    retry_on_status   = [429, 503]
+    retry_on_codes    = [-32005]
+    honor_retry_after = true
+  }
+}
+
+request "test" {
+  method = "test"
+  retry {
+    max_attempts = 2
+  }
+}
+`
+	hclFile, err := New().ParseFile(writeHCL(t, src))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	retry := hclFile.Configs["public"].Retry
+	if retry == nil {
+		t.Fatal("config retry block was not parsed")
+	}
+	if retry.MaxAttempts != 5 || retry.BackoffBase != 250*time.Millisecond || retry.BackoffMax != 8*time.Second {
+		t.Errorf("retry = %+v", retry)
+	}
+	if retry.Jitter == nil || *retry.Jitter || retry.HonorRetryAfter == nil || !*retry.HonorRetryAfter {
+		t.Errorf("retry flags = jitter %v, honor_retry_after %v", retry.Jitter, retry.HonorRetryAfter)
+	}
+	if !reflect.DeepEqual(retry.RetryOnStatus, []int{429, 503}) || !reflect.DeepEqual(retry.RetryOnCodes, []int{-32005}) {
+		t.Errorf("retry_on_status = %v, retry_on_codes = %v", retry.RetryOnStatus, retry.RetryOnCodes)
+	}
+
+	request := hclFile.Requests[0].Retry
+	if request == nil || request.MaxAttempts != 2 || request.Jitter != nil || request.RetryOnStatus != nil {
+		t.Errorf("request retry = %+v, want only max_attempts set", request)
+	}
+}
+
+func TestParser_ParseFile_TLSBlock(t *testing.T) {
+	src := `
+config "internal" {
+  url = "https://node.internal:8545"
+  tls {
+    ca_file              = "certs/ca.pem"
+    cert_file            = "certs/client.pem"
+    key_file             = "certs/client-key.pem"
+    server_name          = "node.internal"
+    insecure_skip_verify = false
+    min_version          = "1.3"
+  }
+}
+`
+	hclFile, err := New().ParseFile(writeHCL(t, src))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	tls := hclFile.Configs["internal"].TLS
+	if tls == nil {
+		t.Fatal("tls block was not parsed")
+	}
+	if tls.CAFile != "certs/ca.pem" || tls.CertFile != "certs/client.pem" || tls.KeyFile != "certs/client-key.pem" {
+		t.Errorf("tls files = %+v", tls)
+	}
+	if tls.ServerName != "node.internal" || tls.MinVersion != "1.3" {
+		t.Errorf("tls = %+v", tls)
+	}
+	if tls.InsecureSkipVerify == nil || *tls.InsecureSkipVerify {
+		t.Errorf("insecure_skip_verify = %v, want false", tls.InsecureSkipVerify)
+	}
+}
+
+func TestParser_ParseFile_AuthBlock(t *testing.T) {
+	src := `
+config "engine" {
+  url = "http://localhost:8551"
+  auth {
+    type        = "jwt_hs256"
+    secret_file = "jwt.hex"
+  }
+}
+
+request "oauth" {
+  method = "test"
+  auth {
+    type          = "oauth2_client_credentials"
+    token_url     = "https://auth.example.com/token"
+    client_id     = "cli"
+    client_secret = "s3cret"
+    scopes        = ["rpc:read"]
+  }
+}
+`
+	hclFile, err := New().ParseFile(writeHCL(t, src))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	if auth := hclFile.Configs["engine"].Auth; auth == nil || auth.Type != "jwt_hs256" || auth.SecretFile != "jwt.hex" {
+		t.Errorf("config auth = %+v", auth)
+	}
+
+	auth := hclFile.Requests[0].Auth
+	if auth == nil || auth.TokenURL != "https://auth.example.com/token" || auth.ClientSecret != "s3cret" {
+		t.Fatalf("request auth = %+v", auth)
+	}
+	if !reflect.DeepEqual(auth.Scopes, []string{"rpc:read"}) {
+		t.Errorf("scopes = %v, want [rpc:read]", auth.Scopes)
+	}
+}
+
+func TestParser_ParseFile_CredentialCommand(t *testing.T) {
+	src := `
+config "staging" {
+  url                = "https://staging.example.com"
+  credential_command = ["get-token", "--env", "staging"]
+  credential_cache   = true
+}
+
+config "keyed" {
+  credential_command = ["get-key"]
+  credential_header  = "X-API-Key"
+}
+`
+	hclFile, err := New().ParseFile(writeHCL(t, src))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	want := &types.CredentialHelper{
+		Command: []string{"get-token", "--env", "staging"},
+		Header:  "Authorization",
+		Cache:   true,
+	}
+	if got := hclFile.Configs["staging"].Credential; !reflect.DeepEqual(got, want) {
+		t.Errorf("staging credential = %+v, want %+v", got, want)
+	}
+
+	want = &types.CredentialHelper{Command: []string{"get-key"}, Header: "X-API-Key"}
+	if got := hclFile.Configs["keyed"].Credential; !reflect.DeepEqual(got, want) {
+		t.Errorf("keyed credential = %+v, want %+v", got, want)
+	}
+}
+
+func TestParser_ParseFile_SigningBlock(t *testing.T) {
+	src := `
+config "gateway" {
+  url = "https://gateway.example.com/rpc"
+  signing {
+    secret_env       = "GATEWAY_SECRET"
+    signature_header = "X-Gateway-Signature"
+    template         = "{timestamp}.{nonce}.{body}"
+    encoding         = "base64"
+  }
+}
+`
+	hclFile, err := New().ParseFile(writeHCL(t, src))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	want := &types.SigningConfig{
+		Algorithm:       types.SigningHMACSHA256,
+		SecretEnv:       "GATEWAY_SECRET",
+		SignatureHeader: "X-Gateway-Signature",
+		TimestampHeader: "X-Timestamp",
+		NonceHeader:     "X-Nonce",
+		Template:        "{timestamp}.{nonce}.{body}",
+		Encoding:        "base64",
+	}
+	if got := hclFile.Configs["gateway"].Signing; !reflect.DeepEqual(got, want) {
+		t.Errorf("signing = %+v, want %+v", got, want)
+	}
+}
+
+func TestParser_ParseFile_IDStrategy(t *testing.T) {
+	src := `
+config {
+  id_strategy = "prefix:call-"
+}
+
+request "first" {
+  method      = "first"
+  id_strategy = "fixed:42"
+}
+
+request "ping" {
+  method       = "ping"
+  notification = true
+}
+`
+	hclFile, err := New().ParseFile(writeHCL(t, src))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	if got := hclFile.Configs["default"].IDStrategy; got != "prefix:call-" {
+		t.Errorf("config id_strategy = %q, want \"prefix:call-\"", got)
+	}
+	if got := hclFile.Requests[0].IDStrategy; got != "fixed:42" {
+		t.Errorf("request id_strategy = %q, want \"fixed:42\"", got)
+	}
+	if !hclFile.Requests[1].Notification {
+		t.Error("ping notification = false, want true")
+	}
+}
+
+func TestParser_ParseFile_MaxResponseSize(t *testing.T) {
+	hclFile, err := New().ParseFile(writeHCL(t, `
+config "archive" {
+  max_response_size = "100MB"
+}
+`))
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	if got := hclFile.Configs["archive"].MaxResponseSize; got != 100<<20 {
+		t.Errorf("max_response_size = %d, want %d", got, 100<<20)
+	}
+}
+
+func TestParser_ParseFile_Environment(t *testing.T) {
+	t.Setenv("RPC_CLI_TEST_TOKEN", "secret-token")
+	t.Setenv("RPC_CLI_TEST_HOST", "node.example.com")
+
+	path := writeHCL(t, `
+config "production" {
+  url = "https://${env.RPC_CLI_TEST_HOST}/rpc"
+  headers = {
+    Authorization = "Bearer ${env("RPC_CLI_TEST_TOKEN")}"
+  }
+}
+
+request "test" {
+  config = "production"
+  method = "test"
+  params = [env("RPC_CLI_TEST_UNSET", "fallback")]
+}
+`)
+
+	hclFile, err := New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	config := hclFile.Configs["production"]
+	if config.URL != "https://node.example.com/rpc" {
+		t.Errorf("config URL = %s, want https://node.example.com/rpc", config.URL)
+	}
+	if config.Headers["Authorization"] != "Bearer secret-token" {
+		t.Errorf("Authorization = %s, want Bearer secret-token", config.Headers["Authorization"])
+	}
+	if !reflect.DeepEqual(hclFile.Requests[0].ProcessedParams, []any{"fallback"}) {
+		t.Errorf("params = %v, want [fallback]", hclFile.Requests[0].ProcessedParams)
+	}
+}
+
+func TestParser_ParseFile_MissingEnvironment(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{
+			name: "env reference",
+			src:  `config { url = env.RPC_CLI_TEST_MISSING }`,
+		},
+		{
+			name: "env function",
+			src:  `config { url = env("RPC_CLI_TEST_MISSING") }`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().ParseFile(writeHCL(t, tt.src))
+			if err == nil {
+				t.Fatal("ParseFile() expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), "environment variable 'RPC_CLI_TEST_MISSING' is not set") {
+				t.Errorf("ParseFile() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestParser_ParseFile_RequestReferences(t *testing.T) {
+	path := writeHCL(t, `
+request "get_block_number" {
+  method = "eth_blockNumber"
+  params = []
+}
+
+request "get_block" {
+  method = "eth_getBlockByNumber"
+  params = [request.get_block_number.result, true]
+}
+`)
+
+	hclFile, err := New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	req := hclFile.Requests[1]
+	if !reflect.DeepEqual(req.DependsOn, []string{"get_block_number"}) {
+		t.Errorf("DependsOn = %v, want [get_block_number]", req.DependsOn)
+	}
+	if req.ParamsExpr == nil {
+		t.Fatal("ParamsExpr should be kept for deferred evaluation")
+	}
+	if req.ProcessedParams != nil {
+		t.Errorf("ProcessedParams = %v, want nil before resolution", req.ProcessedParams)
+	}
+}
+
+func TestParser_ParseFile_ExpectBlock(t *testing.T) {
+	path := writeHCL(t, `
+request "get_block_number" {
+  method = "eth_blockNumber"
+
+  expect {
+    result_type  = "string"
+    equals       = "0x10"
+    jsonpath     = { "$.number" = "0x10" }
+    error_code   = -32601
+    max_duration = "1500ms"
+  }
+}
+`)
+
+	hclFile, err := New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	expect := hclFile.Requests[0].Expect
+	if expect == nil {
+		t.Fatal("Expect should be parsed")
+	}
+	if expect.ResultType != "string" {
+		t.Errorf("ResultType = %s, want string", expect.ResultType)
+	}
+	if !expect.HasEquals || expect.Equals != "0x10" {
+		t.Errorf("Equals = %v, want 0x10", expect.Equals)
+	}
+	if expect.JSONPath["$.number"] != "0x10" {
+		t.Errorf("JSONPath = %v, want $.number = 0x10", expect.JSONPath)
+	}
+	if expect.ErrorCode == nil || *expect.ErrorCode != -32601 {
+		t.Errorf("ErrorCode = %v, want -32601", expect.ErrorCode)
+	}
+	if expect.MaxDuration != 1500*time.Millisecond {
+		t.Errorf("MaxDuration = %s, want 1.5s", expect.MaxDuration)
+	}
+
+	_, err = New().ParseFile(writeHCL(t, `
+request "test" {
+  method = "test"
+  expect { result_type = "text" }
+}
+`))
+	if err == nil || !strings.Contains(err.Error(), "invalid result_type 'text'") {
+		t.Errorf("ParseFile() error = %v, want invalid result_type error", err)
+	}
+}
+
+func TestParser_ParseFile_BatchBlock(t *testing.T) {
+	path := writeHCL(t, `
+locals {
+  balance = "get_balance"
+}
+
+request "get_block_number" {
+  method = "eth_blockNumber"
+}
+
+request "get_balance" {
+  method = "eth_getBalance"
+}
+
+batch "reads" {
+  requests = ["get_block_number", local.balance]
+}
+`)
+
+	hclFile, err := New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	batch := hclFile.FindBatch("reads")
+	if batch == nil {
+		t.Fatal("batch 'reads' should be parsed")
+	}
+	if strings.Join(batch.Requests, ",") != "get_block_number,get_balance" {
+		t.Errorf("Requests = %v, want [get_block_number get_balance]", batch.Requests)
+	}
+
+	_, err = New().ParseFile(writeHCL(t, `
+batch "reads" {
+  requests = "get_block_number"
+}
+`))
+	if err == nil || !strings.Contains(err.Error(), "expected list of strings") {
+		t.Errorf("ParseFile() error = %v, want list error", err)
+	}
+}
+
+func TestParser_ParseFile_SubscribeBlock(t *testing.T) {
+	path := writeHCL(t, `
+subscribe "new_heads" {
+  method = "eth_subscribe"
+  params = ["newHeads"]
+  url    = "wss://node.example.com"
+}
+
+subscribe "pending" {
+  method      = "custom_watch"
+  unsubscribe = "custom_unwatch"
+}
+`)
+
+	hclFile, err := New().ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	heads := hclFile.FindSubscription("new_heads")
+	if heads == nil {
+		t.Fatal("subscription 'new_heads' should be parsed")
+	}
+	if heads.Unsubscribe != "eth_unsubscribe" {
+		t.Errorf("Unsubscribe = %s, want eth_unsubscribe", heads.Unsubscribe)
+	}
+	if heads.Request.URL != "wss://node.example.com" {
+		t.Errorf("URL = %s, want wss://node.example.com", heads.Request.URL)
+	}
+
+	if pending := hclFile.FindSubscription("pending"); pending == nil || pending.Unsubscribe != "custom_unwatch" {
+		t.Errorf("pending subscription = %+v, want unsubscribe custom_unwatch", pending)
+	}
+
+	_, err = New().ParseFile(writeHCL(t, `
+request "get_block_number" {
+  method = "eth_blockNumber"
+}
+
+subscribe "logs" {
+  method = "eth_subscribe"
+  params = ["logs", request.get_block_number.result]
+}
+`))
+	if err == nil || !strings.Contains(err.Error(), "cannot reference request results") {
+		t.Errorf("ParseFile() error = %v, want request reference error", err)
+	}
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 
 	"jsonrpc/pkg/types"
 )
@@ -23,6 +24,125 @@ func (v *Validator) Validate(hclFile *types.HCLFile) error {
 		}
 	}
 
+	if err := v.validateDependencies(hclFile.Requests); err != nil {
+		return err
+	}
+
+	if err := v.validateBatches(hclFile); err != nil {
+		return err
+	}
+
+	return v.validateSubscriptions(hclFile)
+}
+
+// validateSubscriptions checks subscription names, methods and config
+// references
+func (v *Validator) validateSubscriptions(hclFile *types.HCLFile) error {
+	names := make(map[string]bool, len(hclFile.Subscriptions))
+	for _, subscription := range hclFile.Subscriptions {
+		if names[subscription.Name] {
+			return fmt.Errorf("subscription '%s' is defined more than once", subscription.Name)
+		}
+		names[subscription.Name] = true
+
+		if subscription.Unsubscribe == "" {
+			return fmt.Errorf("subscription '%s' has an empty 'unsubscribe' method", subscription.Name)
+		}
+		if err := v.validateRequest(subscription.Request, hclFile.Configs); err != nil {
+			return fmt.Errorf("subscription '%s': %w", subscription.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// validateBatches checks that batch names are unique, do not shadow request
+// names, and only list existing requests
+func (v *Validator) validateBatches(hclFile *types.HCLFile) error {
+	requestNames := make(map[string]bool, len(hclFile.Requests))
+	for _, req := range hclFile.Requests {
+		requestNames[req.Name] = true
+	}
+
+	batchNames := make(map[string]bool, len(hclFile.Batches))
+	for _, batch := range hclFile.Batches {
+		if batchNames[batch.Name] {
+			return fmt.Errorf("batch '%s' is defined more than once", batch.Name)
+		}
+		batchNames[batch.Name] = true
+
+		if requestNames[batch.Name] {
+			return fmt.Errorf("batch '%s' has the same name as a request", batch.Name)
+		}
+		if len(batch.Requests) == 0 {
+			return fmt.Errorf("batch '%s' must list at least one request", batch.Name)
+		}
+
+		for _, name := range batch.Requests {
+			if !requestNames[name] {
+				return fmt.Errorf("batch '%s' references non-existent request '%s'", batch.Name, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateDependencies checks that requests only reference existing requests
+// and that references between requests do not form a cycle
+func (v *Validator) validateDependencies(requests []*types.Request) error {
+	byName := make(map[string]*types.Request, len(requests))
+	for _, req := range requests {
+		byName[req.Name] = req
+	}
+
+	for _, req := range requests {
+		for _, dep := range req.DependsOn {
+			if dep == req.Name {
+				return fmt.Errorf("request '%s' references its own result", req.Name)
+			}
+			target, exists := byName[dep]
+			if !exists {
+				return fmt.Errorf("request '%s' references non-existent request '%s'", req.Name, dep)
+			}
+			if target.Notification {
+				return fmt.Errorf("request '%s' references notification '%s', which has no result", req.Name, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(requests))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("request dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		for _, dep := range byName[name].DependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+
+	for _, req := range requests {
+		if err := visit(req.Name, nil); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -61,6 +61,97 @@ func TestValidator_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid request reference",
+			hclFile: &types.HCLFile{
+				Requests: []*types.Request{
+					{Name: "get_block_number", Method: "eth_blockNumber"},
+					{Name: "get_block", Method: "eth_getBlockByNumber", DependsOn: []string{"get_block_number"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "reference to notification",
+			hclFile: &types.HCLFile{
+				Requests: []*types.Request{
+					{Name: "ping", Method: "ping", Notification: true},
+					{Name: "echo", Method: "echo", DependsOn: []string{"ping"}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "references notification 'ping', which has no result",
+		},
+		{
+			name: "non-existent request reference",
+			hclFile: &types.HCLFile{
+				Requests: []*types.Request{
+					{Name: "get_block", Method: "eth_getBlockByNumber", DependsOn: []string{"missing"}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "references non-existent request 'missing'",
+		},
+		{
+			name: "self reference",
+			hclFile: &types.HCLFile{
+				Requests: []*types.Request{
+					{Name: "loop", Method: "test", DependsOn: []string{"loop"}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "references its own result",
+		},
+		{
+			name: "dependency cycle",
+			hclFile: &types.HCLFile{
+				Requests: []*types.Request{
+					{Name: "a", Method: "test", DependsOn: []string{"b"}},
+					{Name: "b", Method: "test", DependsOn: []string{"c"}},
+					{Name: "c", Method: "test", DependsOn: []string{"a"}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "request dependency cycle: a -> b -> c -> a",
+		},
+		{
+			name: "valid batch",
+			hclFile: &types.HCLFile{
+				Requests: []*types.Request{
+					{Name: "a", Method: "test"},
+					{Name: "b", Method: "test"},
+				},
+				Batches: []*types.Batch{{Name: "reads", Requests: []string{"a", "b"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "batch with non-existent request",
+			hclFile: &types.HCLFile{
+				Requests: []*types.Request{{Name: "a", Method: "test"}},
+				Batches:  []*types.Batch{{Name: "reads", Requests: []string{"a", "missing"}}},
+			},
+			wantErr: true,
+			errMsg:  "batch 'reads' references non-existent request 'missing'",
+		},
+		{
+			name: "batch named like a request",
+			hclFile: &types.HCLFile{
+				Requests: []*types.Request{{Name: "a", Method: "test"}},
+				Batches:  []*types.Batch{{Name: "a", Requests: []string{"a"}}},
+			},
+			wantErr: true,
+			errMsg:  "has the same name as a request",
+		},
+		{
+			name: "empty batch",
+			hclFile: &types.HCLFile{
+				Requests: []*types.Request{{Name: "a", Method: "test"}},
+				Batches:  []*types.Batch{{Name: "reads", Requests: []string{}}},
+			},
+			wantErr: true,
+			errMsg:  "must list at least one request",
+		},
 	}
 
 	for _, tt := range tests {
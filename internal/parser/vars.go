@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// ParseVarFlag parses a `name=value` variable assignment from the command line
+func ParseVarFlag(assignment string) (string, cty.Value, error) {
+	name, value, found := strings.Cut(assignment, "=")
+	name = strings.TrimSpace(name)
+	if !found || name == "" {
+		return "", cty.NilVal, fmt.Errorf("invalid variable format: %s (expected 'name=value')", assignment)
+	}
+
+	if !hclsyntax.ValidIdentifier(name) {
+		return "", cty.NilVal, fmt.Errorf("invalid variable name: %s", name)
+	}
+
+	return name, cty.StringVal(value), nil
+}
+
+// LoadVarFile loads variable values from an HCL (.hcl) or JSON (.json) file
+// #nosec G304 - This is a CLI tool that intentionally reads user-specified files
+func LoadVarFile(filename string) (map[string]cty.Value, error) {
+	cleanPath := filepath.Clean(filename)
+	if strings.Contains(cleanPath, "..") {
+		return nil, fmt.Errorf("path traversal detected: %s", filename)
+	}
+
+	hclParser := hclparse.NewParser()
+
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.EqualFold(filepath.Ext(cleanPath), ".json") {
+		file, diags = hclParser.ParseJSONFile(cleanPath)
+	} else {
+		file, diags = hclParser.ParseHCLFile(cleanPath)
+	}
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse variable file: %s", diags.Error())
+	}
+
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to decode variable file: %s", diags.Error())
+	}
+
+	values := make(map[string]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to decode variable '%s': %s", name, diags.Error())
+		}
+		values[name] = val
+	}
+
+	return values, nil
+}
+
+// coerceVariable converts a supplied variable value to the type of the
+// variable's default. Command-line strings are parsed as HCL literals when
+// the default is not a string, so `--var blocks=[1,2]` yields a list.
+func coerceVariable(name string, supplied, defaultVal cty.Value) cty.Value {
+	if defaultVal.IsNull() || defaultVal.Type() == cty.String {
+		return supplied
+	}
+
+	if converted, err := convert.Convert(supplied, defaultVal.Type()); err == nil {
+		return converted
+	}
+
+	if supplied.Type() == cty.String && supplied.IsKnown() && !supplied.IsNull() {
+		expr, diags := hclsyntax.ParseExpression([]byte(supplied.AsString()), name, hcl.InitialPos)
+		if !diags.HasErrors() {
+			if val, diags := expr.Value(nil); !diags.HasErrors() {
+				if converted, err := convert.Convert(val, defaultVal.Type()); err == nil {
+					return converted
+				}
+				return val
+			}
+		}
+	}
+
+	return supplied
+}
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestParseVarFlag(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantName  string
+		wantValue string
+		wantErr   bool
+	}{
+		{
+			name:      "simple assignment",
+			input:     "address=0x1234",
+			wantName:  "address",
+			wantValue: "0x1234",
+		},
+		{
+			name:      "value containing equals sign",
+			input:     "query=a=b",
+			wantName:  "query",
+			wantValue: "a=b",
+		},
+		{
+			name:      "empty value",
+			input:     "empty=",
+			wantName:  "empty",
+			wantValue: "",
+		},
+		{
+			name:    "missing equals sign",
+			input:   "address",
+			wantErr: true,
+		},
+		{
+			name:    "invalid identifier",
+			input:   "my var=1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, val, err := ParseVarFlag(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseVarFlag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if name != tt.wantName {
+				t.Errorf("ParseVarFlag() name = %s, want %s", name, tt.wantName)
+			}
+			if val.AsString() != tt.wantValue {
+				t.Errorf("ParseVarFlag() value = %s, want %s", val.AsString(), tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestLoadVarFile(t *testing.T) {
+	dir := t.TempDir()
+
+	hclPath := filepath.Join(dir, "vars.hcl")
+	if err := os.WriteFile(hclPath, []byte("address = \"0xabc\"\nblock = 100\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	jsonPath := filepath.Join(dir, "vars.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"address": "0xdef", "tags": ["a", "b"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := LoadVarFile(hclPath)
+	if err != nil {
+		t.Fatalf("LoadVarFile(hcl) error = %v", err)
+	}
+	if values["address"].AsString() != "0xabc" {
+		t.Errorf("address = %s, want 0xabc", values["address"].AsString())
+	}
+	if ConvertCtyToGo(values["block"]) != 100 {
+		t.Errorf("block = %v, want 100", ConvertCtyToGo(values["block"]))
+	}
+
+	values, err = LoadVarFile(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadVarFile(json) error = %v", err)
+	}
+	if values["address"].AsString() != "0xdef" {
+		t.Errorf("address = %s, want 0xdef", values["address"].AsString())
+	}
+	if !reflect.DeepEqual(ConvertCtyToGo(values["tags"]), []any{"a", "b"}) {
+		t.Errorf("tags = %v, want [a b]", ConvertCtyToGo(values["tags"]))
+	}
+}
+
+func TestParser_SetVariables(t *testing.T) {
+	path := writeHCL(t, `
+variable "address" {}
+
+variable "count" {
+  default = 10
+}
+
+variable "blocks" {
+  default = ["latest"]
+}
+
+request "test" {
+  method = "test"
+  params = [var.address, var.count, var.blocks]
+}
+`)
+
+	p := New()
+	p.SetVariables(map[string]cty.Value{
+		"address": cty.StringVal("0xabc"),
+		"count":   cty.StringVal("25"),
+		"blocks":  cty.StringVal(`["0x1", "0x2"]`),
+	})
+
+	hclFile, err := p.ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	want := []any{"0xabc", 25, []any{"0x1", "0x2"}}
+	if !reflect.DeepEqual(hclFile.Requests[0].ProcessedParams, want) {
+		t.Errorf("params = %v, want %v", hclFile.Requests[0].ProcessedParams, want)
+	}
+
+	p.SetVariables(map[string]cty.Value{
+		"address": cty.StringVal("0xabc"),
+		"unknown": cty.StringVal("x"),
+	})
+	_, err = p.ParseFile(path)
+	if err == nil || !strings.Contains(err.Error(), "not declared") {
+		t.Errorf("ParseFile() error = %v, want undeclared variable error", err)
+	}
+}
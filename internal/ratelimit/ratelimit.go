@@ -0,0 +1,131 @@
+// Package ratelimit implements client-side rate limiting with token buckets.
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate is a number of requests allowed per interval
+type Rate struct {
+	Requests int
+	Per      time.Duration
+}
+
+// Parse parses a rate such as "25/s", "600/m", "1000/h" or "5/2s". The unit
+// after the slash is s, m or h, or any Go duration.
+func Parse(s string) (Rate, error) {
+	count, unit, found := strings.Cut(strings.TrimSpace(s), "/")
+	if !found {
+		return Rate{}, fmt.Errorf("invalid rate '%s' (expected requests/unit, e.g. 25/s)", s)
+	}
+
+	requests, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || requests < 1 {
+		return Rate{}, fmt.Errorf("invalid rate '%s': request count must be a positive integer", s)
+	}
+
+	var per time.Duration
+	switch unit = strings.TrimSpace(unit); unit {
+	case "s", "sec", "second":
+		per = time.Second
+	case "m", "min", "minute":
+		per = time.Minute
+	case "h", "hour":
+		per = time.Hour
+	default:
+		per, err = time.ParseDuration(unit)
+		if err != nil || per <= 0 {
+			return Rate{}, fmt.Errorf("invalid rate '%s': unknown interval '%s' (expected s, m, h or a duration)", s, unit)
+		}
+	}
+
+	return Rate{Requests: requests, Per: per}, nil
+}
+
+// String returns the rate as requests/interval
+func (r Rate) String() string {
+	return fmt.Sprintf("%d/%s", r.Requests, r.Per)
+}
+
+// interval returns the time it takes to earn one token
+func (r Rate) interval() time.Duration {
+	return r.Per / time.Duration(r.Requests)
+}
+
+// Limiter is a token bucket holding up to Rate.Requests tokens, refilled
+// evenly over Rate.Per. It is safe for concurrent use.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   Rate
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewLimiter creates a limiter with a full bucket
+func NewLimiter(rate Rate) *Limiter {
+	return newLimiterAt(rate, time.Now)
+}
+
+// newLimiterAt creates a limiter reading the time from now
+func newLimiterAt(rate Rate, now func() time.Time) *Limiter {
+	return &Limiter{
+		rate:   rate,
+		tokens: float64(rate.Requests),
+		last:   now(),
+		now:    now,
+	}
+}
+
+// Reserve takes a token and returns how long the caller has to wait before
+// sending its request. Tokens are handed out in call order, so concurrent
+// callers are spaced out instead of all waking up at once.
+func (l *Limiter) Reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	elapsed := now.Sub(l.last)
+	l.last = now
+
+	l.tokens += float64(elapsed) / float64(l.rate.interval())
+	if capacity := float64(l.rate.Requests); l.tokens > capacity {
+		l.tokens = capacity
+	}
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.rate.interval()))
+}
+
+// Registry hands out one limiter per key, e.g. per config profile
+type Registry struct {
+	mu       sync.Mutex
+	limiters map[string]*Limiter
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{limiters: make(map[string]*Limiter)}
+}
+
+// Limiter returns the limiter for key, creating it on first use. A key used
+// with a different rate gets a new limiter.
+func (r *Registry) Limiter(key string, rate Rate) *Limiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key = key + "\x00" + rate.String()
+	limiter, exists := r.limiters[key]
+	if !exists {
+		limiter = NewLimiter(rate)
+		r.limiters[key] = limiter
+	}
+	return limiter
+}
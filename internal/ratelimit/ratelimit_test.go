@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Rate
+		wantErr bool
+	}{
+		{value: "25/s", want: Rate{Requests: 25, Per: time.Second}},
+		{value: "600/m", want: Rate{Requests: 600, Per: time.Minute}},
+		{value: "1000/hour", want: Rate{Requests: 1000, Per: time.Hour}},
+		{value: " 5 / 2s ", want: Rate{Requests: 5, Per: 2 * time.Second}},
+		{value: "25", wantErr: true},
+		{value: "0/s", wantErr: true},
+		{value: "ten/s", wantErr: true},
+		{value: "25/fortnight", wantErr: true},
+		{value: "25/-1s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiter_Reserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newLimiterAt(Rate{Requests: 2, Per: time.Second}, func() time.Time { return now })
+
+	// The full bucket allows a burst of two requests
+	for i, want := range []time.Duration{0, 0, 500 * time.Millisecond, time.Second} {
+		if got := limiter.Reserve(); got != want {
+			t.Errorf("Reserve() #%d = %v, want %v", i+1, got, want)
+		}
+	}
+
+	// After the reserved tokens have been earned, the bucket refills
+	now = now.Add(3 * time.Second)
+	if got := limiter.Reserve(); got != 0 {
+		t.Errorf("Reserve() after refill = %v, want 0", got)
+	}
+}
+
+func TestLimiter_Concurrent(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newLimiterAt(Rate{Requests: 10, Per: time.Second}, func() time.Time { return now })
+
+	var wg sync.WaitGroup
+	delays := make(chan time.Duration, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			delays <- limiter.Reserve()
+		}()
+	}
+	wg.Wait()
+	close(delays)
+
+	// 10 tokens are available immediately; the other 40 are spread over 4s
+	var longest time.Duration
+	immediate := 0
+	for delay := range delays {
+		if delay == 0 {
+			immediate++
+		}
+		longest = max(longest, delay)
+	}
+	if immediate != 10 || longest != 4*time.Second {
+		t.Errorf("%d immediate reservations, longest delay %v, want 10 and 4s", immediate, longest)
+	}
+}
+
+func TestRegistry_Limiter(t *testing.T) {
+	registry := NewRegistry()
+	rate := Rate{Requests: 5, Per: time.Second}
+
+	if registry.Limiter("public", rate) != registry.Limiter("public", rate) {
+		t.Error("same key should share a limiter")
+	}
+	if registry.Limiter("public", rate) == registry.Limiter("archive", rate) {
+		t.Error("different keys should not share a limiter")
+	}
+	if registry.Limiter("public", rate) == registry.Limiter("public", Rate{Requests: 1, Per: time.Second}) {
+		t.Error("a different rate should get a new limiter")
+	}
+}
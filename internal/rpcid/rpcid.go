@@ -0,0 +1,75 @@
+// Package rpcid implements the id strategies of JSON-RPC requests.
+package rpcid
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kinds of id strategies
+const (
+	Sequential = "sequential"
+	UUID       = "uuid"
+	Prefix     = "prefix"
+	Fixed      = "fixed"
+)
+
+// Strategy assigns ids to requests
+type Strategy struct {
+	Kind  string
+	Value string // prefix for Prefix, the id for Fixed
+}
+
+// Parse parses an id strategy: "sequential", "uuid", "prefix:<prefix>" or
+// "fixed:<id>". An empty string is sequential.
+func Parse(s string) (Strategy, error) {
+	kind, value, hasValue := strings.Cut(strings.TrimSpace(s), ":")
+	switch kind {
+	case "", Sequential, UUID:
+		if hasValue {
+			return Strategy{}, fmt.Errorf("invalid id_strategy '%s': %s takes no value", s, kind)
+		}
+		if kind == "" {
+			kind = Sequential
+		}
+		return Strategy{Kind: kind}, nil
+	case Prefix, Fixed:
+		if value == "" {
+			return Strategy{}, fmt.Errorf("invalid id_strategy '%s' (expected %s:<value>)", s, kind)
+		}
+		return Strategy{Kind: kind, Value: value}, nil
+	default:
+		return Strategy{}, fmt.Errorf(
+			"invalid id_strategy '%s' (expected sequential, uuid, prefix:<prefix> or fixed:<id>)", s)
+	}
+}
+
+// ID returns the id of the request at position seq (1-based) of a run.
+// Sequential ids are numbers, prefixed and uuid ids are strings, and a
+// fixed id is a number if it parses as an integer.
+func (s Strategy) ID(seq int) any {
+	switch s.Kind {
+	case UUID:
+		return newUUID()
+	case Prefix:
+		return s.Value + strconv.Itoa(seq)
+	case Fixed:
+		if n, err := strconv.ParseInt(s.Value, 10, 64); err == nil {
+			return n
+		}
+		return s.Value
+	default:
+		return seq
+	}
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,77 @@
+package rpcid
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Strategy
+		wantErr string
+	}{
+		{spec: "", want: Strategy{Kind: Sequential}},
+		{spec: "sequential", want: Strategy{Kind: Sequential}},
+		{spec: "uuid", want: Strategy{Kind: UUID}},
+		{spec: "prefix:req-", want: Strategy{Kind: Prefix, Value: "req-"}},
+		{spec: "fixed:42", want: Strategy{Kind: Fixed, Value: "42"}},
+		{spec: "fixed:a:b", want: Strategy{Kind: Fixed, Value: "a:b"}},
+		{spec: "prefix:", wantErr: "expected prefix:<value>"},
+		{spec: "uuid:4", wantErr: "uuid takes no value"},
+		{spec: "random", wantErr: "expected sequential, uuid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := Parse(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.spec, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrategy_ID(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		want     any
+	}{
+		{name: "sequential", strategy: Strategy{Kind: Sequential}, want: 7},
+		{name: "prefix", strategy: Strategy{Kind: Prefix, Value: "req-"}, want: "req-7"},
+		{name: "fixed number", strategy: Strategy{Kind: Fixed, Value: "42"}, want: int64(42)},
+		{name: "fixed string", strategy: Strategy{Kind: Fixed, Value: "abc"}, want: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.strategy.ID(7); got != tt.want {
+				t.Errorf("ID(7) = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrategy_IDUUID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	strategy := Strategy{Kind: UUID}
+
+	first, second := strategy.ID(1).(string), strategy.ID(1).(string)
+	if !pattern.MatchString(first) || !pattern.MatchString(second) {
+		t.Errorf("ID() = %q, %q, want version 4 UUIDs", first, second)
+	}
+	if first == second {
+		t.Errorf("ID() returned %q twice", first)
+	}
+}
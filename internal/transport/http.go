@@ -0,0 +1,156 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"jsonrpc/pkg/constants"
+	"jsonrpc/pkg/types"
+)
+
+// HTTPTransport sends each payload as an HTTP POST
+type HTTPTransport struct {
+	client *http.Client
+}
+
+// NewHTTPTransport creates an HTTP transport using client
+func NewHTTPTransport(client *http.Client) *HTTPTransport {
+	return &HTTPTransport{client: client}
+}
+
+// RoundTrip posts the payload and returns the response body. HTTP error
+// statuses are returned as *HTTPError.
+func (t *HTTPTransport) RoundTrip(
+	ctx context.Context,
+	config *types.EffectiveConfig,
+	payload []byte,
+) ([]byte, error) {
+	trace := traceFrom(ctx)
+	if trace != nil {
+		ctx = withClientTrace(ctx, trace, time.Now())
+	}
+
+	// Create HTTP request
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	// Add headers
+	httpReq.Header.Set("Content-Type", constants.HeaderContentType)
+	for k, v := range requestHeaders(config) {
+		httpReq.Header.Set(k, v)
+	}
+	if trace != nil {
+		trace.Request.Method = httpReq.Method
+	}
+
+	// Execute request
+	httpResp, err := t.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer func() {
+		_ = httpResp.Body.Close()
+	}()
+
+	// Read response body
+	respBody, err := readBody(httpResp, config.MaxResponseSize)
+	if err != nil {
+		return nil, err
+	}
+	if trace != nil {
+		trace.Response = &types.TraceResponse{
+			Status:     httpResp.Status,
+			StatusCode: httpResp.StatusCode,
+			Headers:    flattenHeader(httpResp.Header),
+			Body:       respBody,
+		}
+	}
+
+	// Check HTTP status
+	if httpResp.StatusCode >= constants.MinClientErrorStatus {
+		return nil, &HTTPError{
+			StatusCode: httpResp.StatusCode,
+			Status:     httpResp.Status,
+			Body:       string(respBody),
+			RetryAfter: parseRetryAfter(httpResp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	return respBody, nil
+}
+
+// readBody reads a response body into a single buffer sized by its
+// Content-Length, so large bodies are not copied while growing. The size
+// announced by the server is only trusted up to limit (zero for no limit)
+// and maxPreallocation; beyond that the buffer grows as data arrives.
+// Bodies over limit fail without being read to the end.
+func readBody(resp *http.Response, limit int64) ([]byte, error) {
+	if limit > 0 && resp.ContentLength > limit {
+		return nil, responseTooLarge(limit)
+	}
+
+	var body bytes.Buffer
+	if resp.ContentLength > 0 {
+		body.Grow(int(min(resp.ContentLength, maxPreallocation)) + bytes.MinRead)
+	}
+
+	reader := io.Reader(resp.Body)
+	if limit > 0 {
+		reader = io.LimitReader(resp.Body, limit+1)
+	}
+	if _, err := body.ReadFrom(reader); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if limit > 0 && int64(body.Len()) > limit {
+		return nil, responseTooLarge(limit)
+	}
+
+	return body.Bytes(), nil
+}
+
+// Close is a no-op; idle HTTP connections are managed by the client
+func (t *HTTPTransport) Close() error {
+	return nil
+}
+
+// HTTPError is returned for HTTP error statuses
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Body       string
+
+	// RetryAfter is the delay requested by a Retry-After header, or zero
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP error: %d %s - %s", e.StatusCode, e.Status, e.Body)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date. Invalid or past values yield zero.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "seconds", value: "120", want: 2 * time.Minute},
+		{name: "http date", value: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30 * time.Second},
+		{name: "date in the past", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{name: "negative", value: "-5", want: 0},
+		{name: "invalid", value: "soon", want: 0},
+		{name: "empty", value: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPTransport_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	config := types.NewEffectiveConfig()
+	config.URL = server.URL
+
+	_, err := NewHTTPTransport(http.DefaultClient).RoundTrip(context.Background(), config, []byte(`{}`))
+
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("RoundTrip() error = %v, want *HTTPError", err)
+	}
+	if httpErr.StatusCode != http.StatusTooManyRequests || httpErr.RetryAfter != 3*time.Second {
+		t.Errorf("HTTPError = %+v, want status 429 and Retry-After 3s", httpErr)
+	}
+}
+
+func TestHTTPTransport_MaxResponseSize(t *testing.T) {
+	body := `{"jsonrpc":"2.0","result":"0x10","id":1}`
+
+	tests := []struct {
+		name    string
+		limit   int64
+		chunked bool
+		wantErr bool
+	}{
+		{name: "no limit", limit: 0},
+		{name: "exactly the limit", limit: int64(len(body))},
+		{name: "content length over the limit", limit: 16, wantErr: true},
+		{name: "chunked body over the limit", limit: 16, chunked: true, wantErr: true},
+		{name: "chunked body under the limit", limit: 1024, chunked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.chunked {
+					// Flushing before the body is complete leaves out Content-Length
+					_, _ = w.Write([]byte(body[:10]))
+					w.(http.Flusher).Flush()
+					_, _ = w.Write([]byte(body[10:]))
+					return
+				}
+				_, _ = w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			config := types.NewEffectiveConfig()
+			config.URL = server.URL
+			config.MaxResponseSize = tt.limit
+
+			got, err := NewHTTPTransport(http.DefaultClient).RoundTrip(context.Background(), config, []byte(`{}`))
+			if tt.wantErr {
+				if !errors.Is(err, ErrResponseTooLarge) {
+					t.Fatalf("RoundTrip() error = %v, want %v", err, ErrResponseTooLarge)
+				}
+				if err.Error() != "response exceeds the maximum response size of 16B" {
+					t.Errorf("RoundTrip() error = %q", err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RoundTrip() error = %v", err)
+			}
+			if string(got) != body {
+				t.Errorf("RoundTrip() = %s, want %s", got, body)
+			}
+		})
+	}
+}
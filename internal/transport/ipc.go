@@ -0,0 +1,67 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+)
+
+// ipcStream carries newline-delimited JSON-RPC messages over a Unix domain
+// socket, as exposed by geth.ipc and similar node endpoints
+type ipcStream struct {
+	conn    net.Conn
+	reader  *jsonReader
+	writeMu sync.Mutex
+}
+
+// dialIPC connects to the socket path of an ipc:// or unix:// URL. Messages
+// over limit bytes are rejected.
+func dialIPC(ctx context.Context, endpoint *url.URL, limit int64) (*ipcStream, error) {
+	path := socketPath(endpoint)
+	if path == "" {
+		return nil, fmt.Errorf("no socket path in %s", endpoint.String())
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("IPC connection failed: %w", err)
+	}
+
+	return &ipcStream{conn: conn, reader: newJSONReader(conn, limit)}, nil
+}
+
+// socketPath returns the filesystem path of an ipc:// or unix:// URL.
+// ipc:///var/run/geth.ipc is absolute; ipc://geth.ipc is relative.
+func socketPath(endpoint *url.URL) string {
+	return endpoint.Host + endpoint.Path
+}
+
+// ReadMessage reads the next JSON value. Values are decoded from the stream
+// directly, so servers that omit the newline separator are also supported.
+func (s *ipcStream) ReadMessage() ([]byte, error) {
+	return s.reader.next()
+}
+
+// WriteMessage writes data followed by a newline
+func (s *ipcStream) WriteMessage(data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	trimmed := bytes.TrimSpace(data)
+	line := make([]byte, 0, len(trimmed)+1)
+	line = append(line, trimmed...)
+	line = append(line, '\n')
+	if _, err := s.conn.Write(line); err != nil {
+		return fmt.Errorf("failed to write to IPC socket: %w", err)
+	}
+	return nil
+}
+
+// Close closes the socket
+func (s *ipcStream) Close() error {
+	return s.conn.Close()
+}
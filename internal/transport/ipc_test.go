@@ -0,0 +1,187 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// newIPCServer listens on a Unix socket and answers each newline-delimited
+// call by echoing its params. Responses are written without a separator, as
+// some servers do.
+func newIPCServer(t *testing.T) string {
+	t.Helper()
+
+	// Socket paths are limited in length, so avoid the long test temp dir
+	dir, err := os.MkdirTemp("", "rpc-ipc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+	path := filepath.Join(dir, "node.ipc")
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", path, err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() {
+					_ = conn.Close()
+				}()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					var call struct {
+						Params json.RawMessage `json:"params"`
+						ID     int             `json:"id"`
+					}
+					if err := json.Unmarshal(scanner.Bytes(), &call); err != nil {
+						return
+					}
+					data, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "result": call.Params, "id": call.ID})
+					_, _ = conn.Write(data)
+				}
+			}()
+		}
+	}()
+
+	return path
+}
+
+func TestIPC_RoundTrip(t *testing.T) {
+	path := newIPCServer(t)
+
+	config := types.NewEffectiveConfig()
+	config.URL = "ipc://" + path
+	config.Headers["Authorization"] = "ignored"
+
+	pool := NewPool(http.DefaultClient)
+	defer func() {
+		_ = pool.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for id := 1; id <= 3; id++ {
+		tr, err := pool.Get(ctx, config)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		payload := `{"jsonrpc":"2.0","method":"admin_nodeInfo","params":["` + strings.Repeat("x", id) + `"],"id":` + strconv.Itoa(id) + `}`
+		data, err := tr.RoundTrip(ctx, config, []byte(payload))
+		if err != nil {
+			t.Fatalf("RoundTrip() error = %v", err)
+		}
+
+		var resp types.JSONRPCResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			t.Fatalf("invalid response %s: %v", data, err)
+		}
+		if resp.IDKey() != strconv.Itoa(id) || string(resp.Result) != `["`+strings.Repeat("x", id)+`"]` {
+			t.Errorf("call %d got response %s", id, data)
+		}
+	}
+}
+
+func TestIPC_UnixScheme(t *testing.T) {
+	path := newIPCServer(t)
+
+	config := types.NewEffectiveConfig()
+	config.URL = "unix://" + path
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool := NewPool(http.DefaultClient)
+	defer func() {
+		_ = pool.Close()
+	}()
+
+	tr, err := pool.Get(ctx, config)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, ok := tr.(Streamer); !ok {
+		t.Error("IPC transport should support notifications")
+	}
+}
+
+func TestIPC_MissingSocket(t *testing.T) {
+	config := types.NewEffectiveConfig()
+	config.URL = "ipc:///nonexistent/geth.ipc"
+
+	_, err := NewPool(http.DefaultClient).Get(context.Background(), config)
+	if err == nil || !strings.Contains(err.Error(), "IPC connection failed") {
+		t.Errorf("Get() error = %v, want connection failure", err)
+	}
+}
+
+func TestIPC_ReadMessage_Limit(t *testing.T) {
+	message := `{"jsonrpc":"2.0","result":"` + strings.Repeat("a", 600) + `","id":1}`
+
+	tests := []struct {
+		name    string
+		output  string
+		limit   int64
+		want    int
+		wantErr error
+	}{
+		{name: "messages within limit", output: message + "\n" + message + "\n", limit: 1024, want: 2},
+		{name: "message over limit", output: message + "\n", limit: 512, wantErr: ErrResponseTooLarge},
+		{name: "message without end", output: `{"result":"` + strings.Repeat("a", 4096), limit: 1024,
+			wantErr: ErrResponseTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer func() {
+				_ = client.Close()
+			}()
+			go func() {
+				_, _ = server.Write([]byte(tt.output))
+				_ = server.Close()
+			}()
+
+			s := &ipcStream{conn: client, reader: newJSONReader(client, tt.limit)}
+			for i := 0; i < tt.want; i++ {
+				got, err := s.ReadMessage()
+				if err != nil {
+					t.Fatalf("ReadMessage() #%d error = %v", i+1, err)
+				}
+				if string(got) != message {
+					t.Fatalf("ReadMessage() #%d = %.40s..., want the message", i+1, got)
+				}
+			}
+			if tt.wantErr != nil {
+				if _, err := s.ReadMessage(); !errors.Is(err, tt.wantErr) {
+					t.Errorf("ReadMessage() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"jsonrpc/pkg/types"
+)
+
+// messageStream reads and writes whole JSON-RPC messages over a connection
+type messageStream interface {
+	ReadMessage() ([]byte, error)
+	WriteMessage(data []byte) error
+	Close() error
+}
+
+// muxTransport multiplexes concurrent calls over a single message stream.
+// Responses are matched to calls by JSON-RPC id, so they may arrive in any
+// order.
+type muxTransport struct {
+	stream  messageStream
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	pending       map[string]chan []byte
+	notifications chan []byte
+	readErr       error
+	done          chan struct{}
+}
+
+// newMuxTransport wraps a message stream and starts reading responses
+func newMuxTransport(stream messageStream) *muxTransport {
+	t := &muxTransport{
+		stream:  stream,
+		pending: make(map[string]chan []byte),
+		done:    make(chan struct{}),
+	}
+	go t.readLoop()
+	return t
+}
+
+// RoundTrip writes the payload and waits for the response that carries its
+// id. For batches the response array is matched by any of the batch's ids.
+func (t *muxTransport) RoundTrip(
+	ctx context.Context,
+	config *types.EffectiveConfig,
+	payload []byte,
+) ([]byte, error) {
+	ids := messageIDs(payload)
+
+	response := make(chan []byte, 1)
+	if len(ids) > 0 {
+		t.mu.Lock()
+		if t.readErr != nil {
+			err := t.readErr
+			t.mu.Unlock()
+			return nil, err
+		}
+		// Another call waiting for the same id would never get its response
+		for _, id := range ids {
+			if _, exists := t.pending[id]; exists {
+				t.mu.Unlock()
+				return nil, fmt.Errorf("%w: %s", ErrIDInUse, id)
+			}
+		}
+		for _, id := range ids {
+			t.pending[id] = response
+		}
+		t.mu.Unlock()
+
+		defer func() {
+			t.mu.Lock()
+			for _, id := range ids {
+				if t.pending[id] == response {
+					delete(t.pending, id)
+				}
+			}
+			t.mu.Unlock()
+		}()
+	}
+
+	t.writeMu.Lock()
+	err := t.stream.WriteMessage(payload)
+	t.writeMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	// Notifications have no response
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	select {
+	case data := <-response:
+		if limit := config.MaxResponseSize; limit > 0 && int64(len(data)) > limit {
+			return nil, responseTooLarge(limit)
+		}
+		return data, nil
+	case <-t.done:
+		return nil, t.err()
+	case <-ctx.Done():
+		return nil, fmt.Errorf("request timed out waiting for response: %w", ctx.Err())
+	}
+}
+
+// Close closes the underlying stream, failing calls that are still waiting
+func (t *muxTransport) Close() error {
+	return t.stream.Close()
+}
+
+// Notifications returns a channel receiving server notifications, i.e.
+// messages with a method and no id. It is closed when the connection closes.
+// Once requested, notifications must be drained or reading blocks.
+func (t *muxTransport) Notifications() <-chan []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.notifications == nil {
+		t.notifications = make(chan []byte, notificationBuffer)
+		if t.readErr != nil {
+			close(t.notifications)
+		}
+	}
+	return t.notifications
+}
+
+// closed reports whether the connection has stopped reading responses
+func (t *muxTransport) closed() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// err returns why the connection stopped reading
+func (t *muxTransport) err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.readErr
+}
+
+// readLoop delivers incoming messages to the calls waiting for them
+func (t *muxTransport) readLoop() {
+	for {
+		data, err := t.stream.ReadMessage()
+		if err != nil {
+			t.mu.Lock()
+			t.readErr = fmt.Errorf("connection closed: %w", err)
+			if t.notifications != nil {
+				close(t.notifications)
+			}
+			t.mu.Unlock()
+			close(t.done)
+			return
+		}
+		t.deliver(data)
+	}
+}
+
+// deliver hands a message to the call waiting for one of its ids, or to the
+// notification channel for server notifications. A message without a known
+// id (e.g. an error with a null id) goes to the only call in flight, if there
+// is exactly one.
+func (t *muxTransport) deliver(data []byte) {
+	t.mu.Lock()
+
+	var target chan []byte
+	for _, id := range messageIDs(data) {
+		if ch, exists := t.pending[id]; exists {
+			target = ch
+			break
+		}
+	}
+
+	if target == nil && isNotification(data) {
+		notifications := t.notifications
+		t.mu.Unlock()
+		if notifications != nil {
+			notifications <- data
+		}
+		return
+	}
+
+	if target == nil {
+		target = t.onlyPending()
+	}
+	t.mu.Unlock()
+
+	if target == nil {
+		return
+	}
+
+	select {
+	case target <- data:
+	default:
+	}
+}
+
+// onlyPending returns the waiting call if exactly one call is in flight
+func (t *muxTransport) onlyPending() chan []byte {
+	var only chan []byte
+	for _, ch := range t.pending {
+		if only != nil && ch != only {
+			return nil
+		}
+		only = ch
+	}
+	return only
+}
+
+// messageIDs returns the normalized ids of a JSON-RPC message or batch.
+// Null and missing ids are skipped.
+func messageIDs(data []byte) []string {
+	type envelope struct {
+		ID json.RawMessage `json:"id"`
+	}
+
+	var envelopes []envelope
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &envelopes); err != nil {
+			return nil
+		}
+	} else {
+		var single envelope
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return nil
+		}
+		envelopes = []envelope{single}
+	}
+
+	ids := make([]string, 0, len(envelopes))
+	for _, env := range envelopes {
+		if len(env.ID) == 0 || string(env.ID) == "null" {
+			continue
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, env.ID); err != nil {
+			continue
+		}
+		ids = append(ids, compact.String())
+	}
+	return ids
+}
+
+// isNotification reports whether a message is a JSON-RPC notification: an
+// object with a method and without an id
+func isNotification(data []byte) bool {
+	var envelope struct {
+		Method string          `json:"method"`
+		ID     json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return false
+	}
+	return envelope.Method != "" && (len(envelope.ID) == 0 || string(envelope.ID) == "null")
+}
+
+// notificationBuffer is the number of notifications queued before reading
+// from the connection waits for the consumer
+const notificationBuffer = 256
+
+// errConnectionClosed is returned when writing to a closed connection
+var errConnectionClosed = errors.New("connection closed")
@@ -0,0 +1,94 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// heldStream is a message stream that answers a call only when told to
+type heldStream struct {
+	written   chan []byte
+	responses chan []byte
+	closed    chan struct{}
+}
+
+func newHeldStream() *heldStream {
+	return &heldStream{
+		written:   make(chan []byte, 10),
+		responses: make(chan []byte),
+		closed:    make(chan struct{}),
+	}
+}
+
+func (s *heldStream) ReadMessage() ([]byte, error) {
+	select {
+	case data := <-s.responses:
+		return data, nil
+	case <-s.closed:
+		return nil, io.EOF
+	}
+}
+
+func (s *heldStream) WriteMessage(data []byte) error {
+	s.written <- data
+	return nil
+}
+
+func (s *heldStream) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func TestMuxTransport_ConcurrentFixedID(t *testing.T) {
+	stream := newHeldStream()
+	mux := newMuxTransport(stream)
+	defer func() {
+		_ = mux.Close()
+	}()
+
+	config := types.NewEffectiveConfig()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The first call waits for its response
+	type outcome struct {
+		data []byte
+		err  error
+	}
+	first := make(chan outcome, 1)
+	go func() {
+		data, err := mux.RoundTrip(ctx, config, []byte(`{"jsonrpc":"2.0","method":"a","id":42}`))
+		first <- outcome{data, err}
+	}()
+	<-stream.written
+
+	// A second call with the same id fails at once instead of taking over
+	// the first call's response
+	_, err := mux.RoundTrip(ctx, config, []byte(`{"jsonrpc":"2.0","method":"b","id":42}`))
+	if !errors.Is(err, ErrIDInUse) {
+		t.Fatalf("second RoundTrip() error = %v, want ErrIDInUse", err)
+	}
+
+	stream.responses <- []byte(`{"jsonrpc":"2.0","result":"a","id":42}`)
+	got := <-first
+	if got.err != nil {
+		t.Fatalf("first RoundTrip() error = %v", got.err)
+	}
+	if string(got.data) != `{"jsonrpc":"2.0","result":"a","id":42}` {
+		t.Errorf("first RoundTrip() = %s, want the response to id 42", got.data)
+	}
+
+	// Once answered, the id can be used again
+	go func() {
+		<-stream.written
+		stream.responses <- []byte(`{"jsonrpc":"2.0","result":"c","id":42}`)
+	}()
+	if _, err := mux.RoundTrip(ctx, config, []byte(`{"jsonrpc":"2.0","method":"c","id":42}`)); err != nil {
+		t.Errorf("third RoundTrip() error = %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stdio framings
+const (
+	FramingNDJSON        = "ndjson"
+	FramingContentLength = "content-length"
+)
+
+// processShutdownTimeout is how long a server gets to exit after its stdin
+// is closed before it is killed
+const processShutdownTimeout = 2 * time.Second
+
+// stdioStream carries JSON-RPC messages over the stdin and stdout of a
+// subprocess, framed as newline-delimited JSON or with LSP-style
+// Content-Length headers
+type stdioStream struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  *bufio.Reader
+	json    *jsonReader // for ndjson framing
+	framing string
+	limit   int64 // largest message accepted
+
+	// readDone is closed when ReadMessage fails, e.g. at EOF, after which
+	// the reader stops reading stdout
+	readDone     chan struct{}
+	readDoneOnce sync.Once
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// startProcess starts a JSON-RPC server subprocess. Its stderr is passed
+// through so that server logs stay visible. Messages over limit bytes are
+// rejected.
+func startProcess(command []string, framing string, limit int64) (*stdioStream, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	if framing == "" {
+		framing = FramingNDJSON
+	}
+	if framing != FramingNDJSON && framing != FramingContentLength {
+		return nil, fmt.Errorf("unsupported framing '%s' (expected ndjson or content-length)", framing)
+	}
+
+	// #nosec G204 - the command is configured by the user in the HCL file
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdin of %s: %w", command[0], err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdout of %s: %w", command[0], err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", command[0], err)
+	}
+
+	s := &stdioStream{
+		cmd:      cmd,
+		stdin:    stdin,
+		stdout:   bufio.NewReader(stdout),
+		framing:  framing,
+		limit:    limit,
+		readDone: make(chan struct{}),
+	}
+	s.json = newJSONReader(s.stdout, limit)
+	return s, nil
+}
+
+// ReadMessage reads the next framed message from the process's stdout
+func (s *stdioStream) ReadMessage() ([]byte, error) {
+	message, err := s.readMessage()
+	if err != nil {
+		s.readDoneOnce.Do(func() {
+			close(s.readDone)
+		})
+	}
+	return message, err
+}
+
+// readMessage reads a message in the configured framing
+func (s *stdioStream) readMessage() ([]byte, error) {
+	if s.framing == FramingNDJSON {
+		return s.json.next()
+	}
+
+	headers, err := textproto.NewReader(s.stdout).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.ParseInt(strings.TrimSpace(headers.Get("Content-Length")), 10, 64)
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header '%s'", headers.Get("Content-Length"))
+	}
+	if length > s.limit {
+		return nil, responseTooLarge(s.limit)
+	}
+
+	// The buffer grows with the data received rather than the announced length
+	var message bytes.Buffer
+	message.Grow(int(min(length, maxPreallocation)))
+	if _, err := io.CopyN(&message, s.stdout, length); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
+
+// WriteMessage writes a framed message to the process's stdin
+func (s *stdioStream) WriteMessage(data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	var b bytes.Buffer
+	trimmed := bytes.TrimSpace(data)
+	if s.framing == FramingContentLength {
+		fmt.Fprintf(&b, "Content-Length: %d\r\n\r\n", len(trimmed))
+		b.Write(trimmed)
+	} else {
+		b.Write(trimmed)
+		b.WriteByte('\n')
+	}
+
+	if _, err := s.stdin.Write(b.Bytes()); err != nil {
+		return fmt.Errorf("failed to write to %s: %w", s.cmd.Path, err)
+	}
+	return nil
+}
+
+// Close closes the process's stdin and waits for it to exit, killing it if
+// it does not exit in time
+func (s *stdioStream) Close() error {
+	s.closeOnce.Do(func() {
+		s.writeMu.Lock()
+		_ = s.stdin.Close()
+		s.writeMu.Unlock()
+
+		// Wait closes stdout, so it runs only once reading has stopped: a
+		// server exits when its stdin is closed, and stdout reaches EOF
+		killed := !waitFor(s.readDone)
+		if killed {
+			s.kill()
+			// A process started by the server may still hold stdout open
+			waitFor(s.readDone)
+		}
+
+		exited := make(chan struct{})
+		go func() {
+			_ = s.cmd.Wait()
+			close(exited)
+		}()
+
+		if !killed && !waitFor(exited) {
+			s.kill()
+		}
+		<-exited
+	})
+	return s.closeErr
+}
+
+// kill kills the process for not exiting after its stdin was closed
+func (s *stdioStream) kill() {
+	_ = s.cmd.Process.Kill()
+	s.closeErr = fmt.Errorf("%s did not exit after stdin was closed and was killed", s.cmd.Path)
+}
+
+// waitFor waits up to processShutdownTimeout for done to be closed and
+// reports whether it was
+func waitFor(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	case <-time.After(processShutdownTimeout):
+		return false
+	}
+}
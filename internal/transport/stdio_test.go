@@ -0,0 +1,255 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// TestHelperProcess is not a real test. It runs as the JSON-RPC server
+// subprocess for the stdio tests and echoes each call's params.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	framing := os.Args[len(os.Args)-1]
+	reader := bufio.NewReader(os.Stdin)
+	decoder := json.NewDecoder(reader)
+
+	for {
+		var data []byte
+		if framing == FramingContentLength {
+			headers, err := textproto.NewReader(reader).ReadMIMEHeader()
+			if err != nil {
+				return
+			}
+			length, _ := strconv.Atoi(headers.Get("Content-Length"))
+			data = make([]byte, length)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return
+			}
+		} else {
+			var message json.RawMessage
+			if err := decoder.Decode(&message); err != nil {
+				return
+			}
+			data = message
+		}
+
+		var call struct {
+			Params json.RawMessage `json:"params"`
+			ID     int             `json:"id"`
+		}
+		if err := json.Unmarshal(data, &call); err != nil {
+			return
+		}
+		response, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "result": call.Params, "id": call.ID})
+
+		if framing == FramingContentLength {
+			fmt.Printf("Content-Length: %d\r\n\r\n%s", len(response), response)
+		} else {
+			fmt.Printf("%s\n", response)
+		}
+	}
+}
+
+// helperCommand returns a command that runs TestHelperProcess as a server
+func helperCommand(t *testing.T, framing string) []string {
+	t.Helper()
+	t.Setenv("GO_WANT_HELPER_PROCESS", "1")
+	return []string{os.Args[0], "-test.run=^TestHelperProcess$", "--", framing}
+}
+
+func TestStdio_RoundTrip(t *testing.T) {
+	for _, framing := range []string{FramingNDJSON, FramingContentLength} {
+		t.Run(framing, func(t *testing.T) {
+			config := types.NewEffectiveConfig()
+			config.Command = helperCommand(t, framing)
+			config.Framing = framing
+
+			pool := NewPool(http.DefaultClient)
+			defer func() {
+				if err := pool.Close(); err != nil {
+					t.Errorf("Close() error = %v", err)
+				}
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			var first Transport
+			for id := 1; id <= 3; id++ {
+				tr, err := pool.Get(ctx, config)
+				if err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				if first == nil {
+					first = tr
+				} else if tr != first {
+					t.Error("pool should reuse the running process")
+				}
+
+				payload := `{"jsonrpc":"2.0","method":"initialize","params":["` + strings.Repeat("x", id) + `"],"id":` + strconv.Itoa(id) + `}`
+				data, err := tr.RoundTrip(ctx, config, []byte(payload))
+				if err != nil {
+					t.Fatalf("RoundTrip() error = %v", err)
+				}
+
+				var resp types.JSONRPCResponse
+				if err := json.Unmarshal(data, &resp); err != nil {
+					t.Fatalf("invalid response %s: %v", data, err)
+				}
+				if resp.IDKey() != strconv.Itoa(id) || string(resp.Result) != `["`+strings.Repeat("x", id)+`"]` {
+					t.Errorf("call %d got response %s", id, data)
+				}
+			}
+		})
+	}
+}
+
+func TestStdio_StartErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		framing string
+		wantErr string
+	}{
+		{name: "missing binary", command: []string{"/nonexistent/server"}, wantErr: "failed to start"},
+		{name: "unknown framing", command: []string{"cat"}, framing: "xml", wantErr: "unsupported framing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := types.NewEffectiveConfig()
+			config.Command = tt.command
+			config.Framing = tt.framing
+
+			_, err := NewPool(http.DefaultClient).Get(context.Background(), config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Get() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStdio_ReadMessage_ContentLengthLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr error
+	}{
+		{name: "within limit", output: "Content-Length: 2\r\n\r\n{}", want: "{}"},
+		{name: "over limit", output: "Content-Length: 1025\r\n\r\n{}", wantErr: ErrResponseTooLarge},
+		{name: "huge length", output: "Content-Length: 9223372036854775807\r\n\r\n{}", wantErr: ErrResponseTooLarge},
+		{name: "short body", output: "Content-Length: 1000\r\n\r\n{}", wantErr: io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &stdioStream{
+				stdout:   bufio.NewReader(strings.NewReader(tt.output)),
+				framing:  FramingContentLength,
+				limit:    1024,
+				readDone: make(chan struct{}),
+			}
+
+			got, err := s.ReadMessage()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ReadMessage() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadMessage() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadMessage() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStdio_ReadMessage_NDJSONLimit(t *testing.T) {
+	line := `{"jsonrpc":"2.0","result":"` + strings.Repeat("a", 600) + `","id":1}`
+
+	tests := []struct {
+		name    string
+		output  string
+		want    int
+		wantErr error
+	}{
+		{name: "lines within limit", output: line + "\n" + line + "\n", want: 2},
+		{name: "oversized line", output: strings.Replace(line, "aaa", strings.Repeat("a", 1000), 1) + "\n",
+			wantErr: ErrResponseTooLarge},
+		{name: "line after a short one", output: line + "\n" + strings.Repeat(" ", 2000) + "{}\n", want: 1,
+			wantErr: ErrResponseTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := bufio.NewReader(strings.NewReader(tt.output))
+			s := &stdioStream{
+				stdout:   stdout,
+				json:     newJSONReader(stdout, 1024),
+				framing:  FramingNDJSON,
+				limit:    1024,
+				readDone: make(chan struct{}),
+			}
+
+			for i := 0; i < tt.want; i++ {
+				got, err := s.ReadMessage()
+				if err != nil {
+					t.Fatalf("ReadMessage() #%d error = %v", i+1, err)
+				}
+				if string(got) != line {
+					t.Fatalf("ReadMessage() #%d = %.40s..., want the line", i+1, got)
+				}
+			}
+			if tt.wantErr != nil {
+				if _, err := s.ReadMessage(); !errors.Is(err, tt.wantErr) {
+					t.Errorf("ReadMessage() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestStdio_CloseKillsServerThatDoesNotExit(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	config := types.NewEffectiveConfig()
+	config.Command = []string{"sleep", "30"}
+
+	pool := NewPool(http.DefaultClient)
+	if _, err := pool.Get(context.Background(), config); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	start := time.Now()
+	err := pool.Close()
+	if err == nil || !strings.Contains(err.Error(), "was killed") {
+		t.Errorf("Close() error = %v, want the server to be killed", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*processShutdownTimeout {
+		t.Errorf("Close() took %v", elapsed)
+	}
+}
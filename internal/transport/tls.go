@@ -0,0 +1,72 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"jsonrpc/pkg/types"
+)
+
+// tlsVersions maps min_version values to TLS versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// IsValidTLSVersion reports whether version is a supported min_version
+func IsValidTLSVersion(version string) bool {
+	_, ok := tlsVersions[version]
+	return ok
+}
+
+// newTLSConfig builds a client TLS configuration from a tls block. A CA
+// file replaces the system roots. TLS 1.2 is the default minimum version.
+func newTLSConfig(settings *types.TLSConfig) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if settings == nil {
+		return config, nil
+	}
+
+	if settings.MinVersion != "" {
+		version, ok := tlsVersions[settings.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported TLS min_version '%s' (expected 1.0, 1.1, 1.2 or 1.3)", settings.MinVersion)
+		}
+		config.MinVersion = version
+	}
+
+	if settings.CAFile != "" {
+		pem, err := os.ReadFile(settings.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA file %s", settings.CAFile)
+		}
+		config.RootCAs = roots
+	}
+
+	if (settings.CertFile == "") != (settings.KeyFile == "") {
+		return nil, fmt.Errorf("a client certificate needs both cert_file and key_file")
+	}
+	if settings.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	config.ServerName = settings.ServerName
+	if settings.InsecureSkipVerify != nil {
+		// #nosec G402 - explicitly requested with insecure_skip_verify or --insecure
+		config.InsecureSkipVerify = *settings.InsecureSkipVerify
+	}
+
+	return config, nil
+}
@@ -0,0 +1,187 @@
+package transport
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// writePEM writes a PEM block to a file in dir and returns its path
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newClientCertificate creates a CA and a client certificate signed by it,
+// and returns the CA pool and the paths of the client certificate and key
+func newClientCertificate(t *testing.T) (*x509.CertPool, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "rpc-cli"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, caCert, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	return pool, writePEM(t, dir, "client.pem", "CERTIFICATE", clientDER), writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER)
+}
+
+func TestPool_TLS(t *testing.T) {
+	clientCAs, certFile, keyFile := newClientCertificate(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":"0x1","id":1}`))
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MaxVersion: tls.VersionTLS12,
+	}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := writePEM(t, t.TempDir(), "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+	insecure := true
+
+	tests := []struct {
+		name    string
+		tls     *types.TLSConfig
+		wantErr string
+	}{
+		{name: "system roots", wantErr: "certificate"},
+		{name: "no client certificate", tls: &types.TLSConfig{CAFile: caFile}, wantErr: "handshake failure"},
+		{name: "mutual TLS", tls: &types.TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}},
+		{name: "insecure", tls: &types.TLSConfig{CertFile: certFile, KeyFile: keyFile, InsecureSkipVerify: &insecure}},
+		{
+			name:    "server name mismatch",
+			tls:     &types.TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, ServerName: "rpc.internal"},
+			wantErr: "rpc.internal",
+		},
+		{
+			name:    "min version above server",
+			tls:     &types.TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3"},
+			wantErr: "version",
+		},
+		{name: "cert without key", tls: &types.TLSConfig{CertFile: certFile}, wantErr: "both cert_file and key_file"},
+		{name: "missing CA file", tls: &types.TLSConfig{CAFile: "/nonexistent/ca.pem"}, wantErr: "failed to read CA file"},
+	}
+
+	pool := NewPool(&http.Client{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := types.NewEffectiveConfig()
+			config.URL = server.URL
+			config.TLS = tt.tls
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			tr, err := pool.Get(ctx, config)
+			if err == nil {
+				_, err = tr.RoundTrip(ctx, config, []byte(`{"jsonrpc":"2.0","method":"eth_chainId","id":1}`))
+			}
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("RoundTrip() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("RoundTrip() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPool_TLSClientsShared(t *testing.T) {
+	pool := NewPool(&http.Client{})
+	insecure := true
+
+	config := types.NewEffectiveConfig()
+	config.URL = "https://rpc.example.com"
+	config.TLS = &types.TLSConfig{InsecureSkipVerify: &insecure}
+
+	first, err := pool.Get(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	// Same settings in a different struct share the client
+	same := true
+	config.TLS = &types.TLSConfig{InsecureSkipVerify: &same}
+	second, err := pool.Get(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if first != second {
+		t.Error("requests with the same TLS settings should share a client")
+	}
+
+	config.TLS = nil
+	if plain, _ := pool.Get(context.Background(), config); plain == first {
+		t.Error("requests without TLS settings should use the default client")
+	}
+}
@@ -0,0 +1,85 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// traceKey is the context key of the trace being recorded
+type traceKey struct{}
+
+// WithTrace returns a context under which the HTTP transport records the
+// request, response and timings of a round trip in trace
+func WithTrace(ctx context.Context, trace *types.Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// traceFrom returns the trace to record in, or nil
+func traceFrom(ctx context.Context) *types.Trace {
+	trace, _ := ctx.Value(traceKey{}).(*types.Trace)
+	return trace
+}
+
+// withClientTrace attaches an httptrace.ClientTrace to ctx that records the
+// request headers as written and the connection phases of a request sent at
+// start. Dual-stack dials may report connects concurrently, so the hooks
+// share a lock.
+func withClientTrace(ctx context.Context, trace *types.Trace, start time.Time) context.Context {
+	timings := &trace.Timings
+	trace.Request.Headers = make(map[string]string)
+
+	var mu sync.Mutex
+	var dnsStart, connectStart, tlsStart time.Time
+
+	record := func(fn func()) {
+		mu.Lock()
+		defer mu.Unlock()
+		fn()
+	}
+
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteHeaderField: func(key string, value []string) {
+			record(func() { trace.Request.Headers[key] = strings.Join(value, ", ") })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			record(func() { timings.ReusedConn = info.Reused })
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			record(func() { dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			record(func() { timings.DNS = time.Since(dnsStart) })
+		},
+		ConnectStart: func(_, _ string) {
+			record(func() { connectStart = time.Now() })
+		},
+		ConnectDone: func(_, _ string, _ error) {
+			record(func() { timings.Connect = time.Since(connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			record(func() { tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			record(func() { timings.TLS = time.Since(tlsStart) })
+		},
+		GotFirstResponseByte: func() {
+			record(func() { timings.TimeToFirstByte = time.Since(start) })
+		},
+	})
+}
+
+// flattenHeader joins the values of each header with commas
+func flattenHeader(header http.Header) map[string]string {
+	flat := make(map[string]string, len(header))
+	for name, values := range header {
+		flat[name] = strings.Join(values, ", ")
+	}
+	return flat
+}
@@ -0,0 +1,314 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"jsonrpc/internal/bytesize"
+	"jsonrpc/pkg/types"
+)
+
+// ErrResponseTooLarge is returned for responses over the configured
+// max_response_size
+var ErrResponseTooLarge = errors.New("response exceeds the maximum response size")
+
+// maxPreallocation bounds buffers sized from lengths announced by a server,
+// such as Content-Length, before the data has arrived
+const maxPreallocation = 64 << 20
+
+// maxMessageSize bounds messages on persistent connections without a
+// max_response_size, so that a server cannot make the client buffer an
+// unbounded amount of data
+const maxMessageSize = 1 << 30
+
+// ErrIDInUse is returned for a call on a shared connection whose id is
+// already waiting for a response, e.g. concurrent requests with a fixed id
+var ErrIDInUse = errors.New("another request on this connection is waiting for the same id; " +
+	"concurrent requests need an id_strategy other than fixed")
+
+// responseTooLarge returns ErrResponseTooLarge with the limit
+func responseTooLarge(limit int64) error {
+	return fmt.Errorf("%w of %s", ErrResponseTooLarge, bytesize.Format(limit))
+}
+
+// messageLimit returns the largest message read on a connection for config
+func messageLimit(config *types.EffectiveConfig) int64 {
+	if config.MaxResponseSize > 0 {
+		return config.MaxResponseSize
+	}
+	return maxMessageSize
+}
+
+// errMessageTooLarge is returned by messageReader once a message reaches
+// its limit
+var errMessageTooLarge = errors.New("message exceeds the size limit")
+
+// messageReader lets a json.Decoder read at most up to end, the offset in
+// the stream where the current message must have ended
+type messageReader struct {
+	r    io.Reader
+	read int64 // bytes read from r so far
+	end  int64
+}
+
+// Read implements io.Reader
+func (m *messageReader) Read(p []byte) (int, error) {
+	if m.read >= m.end {
+		return 0, errMessageTooLarge
+	}
+	if remaining := m.end - m.read; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := m.r.Read(p)
+	m.read += int64(n)
+	return n, err
+}
+
+// jsonReader decodes consecutive JSON values from a stream, failing values
+// over a size limit before they are read whole
+type jsonReader struct {
+	reader  *messageReader
+	decoder *json.Decoder
+	limit   int64
+}
+
+// newJSONReader creates a jsonReader for values of up to limit bytes
+func newJSONReader(r io.Reader, limit int64) *jsonReader {
+	reader := &messageReader{r: r}
+	return &jsonReader{reader: reader, decoder: json.NewDecoder(reader), limit: limit}
+}
+
+// next returns the next JSON value. The separator before a value (usually
+// a newline) counts towards the limit by one byte.
+func (j *jsonReader) next() ([]byte, error) {
+	j.reader.end = j.decoder.InputOffset() + j.limit + 1
+
+	var message json.RawMessage
+	if err := j.decoder.Decode(&message); err != nil {
+		if errors.Is(err, errMessageTooLarge) {
+			return nil, responseTooLarge(j.limit)
+		}
+		return nil, err
+	}
+	return message, nil
+}
+
+// Transport sends JSON-RPC payloads to an endpoint
+type Transport interface {
+	// RoundTrip sends a request or batch payload and returns the raw response
+	// payload. Payloads without ids (notifications) return a nil response.
+	RoundTrip(ctx context.Context, config *types.EffectiveConfig, payload []byte) ([]byte, error)
+
+	// Close releases the resources held by the transport
+	Close() error
+}
+
+// Streamer is implemented by transports on persistent connections, which
+// can receive server notifications such as subscription updates
+type Streamer interface {
+	Transport
+
+	// Notifications returns a channel receiving incoming messages that are
+	// not responses to a call. It is closed when the connection closes.
+	Notifications() <-chan []byte
+}
+
+// Pool hands out transports by URL scheme or command and keeps one
+// connection (or process) per endpoint for connection-oriented transports.
+// HTTP transports are kept per TLS settings. It is safe for concurrent use.
+type Pool struct {
+	client *http.Client
+	http   *HTTPTransport
+
+	mu         sync.Mutex
+	conns      map[string]*muxTransport
+	tlsClients map[string]*HTTPTransport
+}
+
+// NewPool creates a transport pool that sends HTTP requests with client
+func NewPool(client *http.Client) *Pool {
+	return &Pool{
+		client:     client,
+		http:       NewHTTPTransport(client),
+		conns:      make(map[string]*muxTransport),
+		tlsClients: make(map[string]*HTTPTransport),
+	}
+}
+
+// Get returns the transport for the configured URL, connecting if needed
+func (p *Pool) Get(ctx context.Context, config *types.EffectiveConfig) (Transport, error) {
+	// A command runs a local server; the process is started once per pool
+	// and response size limit
+	if len(config.Command) > 0 {
+		key := fmt.Sprintf("command:%s\x00%s\x00%d",
+			strings.Join(config.Command, "\x00"), config.Framing, config.MaxResponseSize)
+		return p.connection(ctx, key, func(ctx context.Context) (messageStream, error) {
+			return startProcess(config.Command, config.Framing, messageLimit(config))
+		})
+	}
+
+	endpoint, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL '%s': %w", config.URL, err)
+	}
+
+	switch strings.ToLower(endpoint.Scheme) {
+	case "http", "https":
+		if config.TLS == nil {
+			return p.http, nil
+		}
+		t, err := p.httpTransport(config.TLS)
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	case "ws", "wss":
+		return p.connection(ctx, endpointKey(config), func(ctx context.Context) (messageStream, error) {
+			tlsConfig, err := newTLSConfig(config.TLS)
+			if err != nil {
+				return nil, err
+			}
+			return dialWebSocket(ctx, endpoint, requestHeaders(config), tlsConfig, messageLimit(config))
+		})
+	case "ipc", "unix":
+		// Headers do not apply to sockets, so the connection is keyed by URL
+		// and response size limit only
+		key := fmt.Sprintf("%s\x00%d", config.URL, config.MaxResponseSize)
+		return p.connection(ctx, key, func(ctx context.Context) (messageStream, error) {
+			return dialIPC(ctx, endpoint, messageLimit(config))
+		})
+	default:
+		return nil, fmt.Errorf("unsupported URL scheme '%s' in %s", endpoint.Scheme, config.URL)
+	}
+}
+
+// Client returns the HTTP client for a tls block, e.g. for calls to an OAuth2
+// token endpoint of the same profile. A nil block gets the pool's client.
+func (p *Pool) Client(settings *types.TLSConfig) (*http.Client, error) {
+	if settings == nil {
+		return p.client, nil
+	}
+	t, err := p.httpTransport(settings)
+	if err != nil {
+		return nil, err
+	}
+	return t.client, nil
+}
+
+// httpTransport returns the HTTP transport for a tls block, creating a
+// client with its own connection pool on first use
+func (p *Pool) httpTransport(settings *types.TLSConfig) (*HTTPTransport, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := settings.Key()
+	if t, exists := p.tlsClients[key]; exists {
+		return t, nil
+	}
+
+	tlsConfig, err := newTLSConfig(settings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS settings: %w", err)
+	}
+
+	base, ok := p.client.Transport.(*http.Transport)
+	if !ok {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	roundTripper := base.Clone()
+	roundTripper.TLSClientConfig = tlsConfig
+
+	client := *p.client
+	client.Transport = roundTripper
+
+	t := NewHTTPTransport(&client)
+	p.tlsClients[key] = t
+	return t, nil
+}
+
+// connection returns the open connection for an endpoint, dialing a new one
+// when there is none or the previous one was closed
+func (p *Pool) connection(
+	ctx context.Context,
+	key string,
+	dial func(ctx context.Context) (messageStream, error),
+) (Transport, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if conn, exists := p.conns[key]; exists && !conn.closed() {
+		return conn, nil
+	}
+
+	stream, err := dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := newMuxTransport(stream)
+	p.conns[key] = conn
+	return conn, nil
+}
+
+// Close closes every open connection. The pool can be used again afterwards.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var firstErr error
+	for key, conn := range p.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(p.conns, key)
+	}
+	return firstErr
+}
+
+// endpointKey identifies a connection by URL, TLS settings, auth block,
+// credential helper, response size limit and the headers sent when
+// connecting. Credentials change between requests (e.g. a fresh JWT), so
+// they are not part of the key.
+func endpointKey(config *types.EffectiveConfig) string {
+	names := make([]string, 0, len(config.Headers))
+	for name := range config.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(config.URL)
+	b.WriteString("\n" + config.TLS.Key())
+	b.WriteString("\n" + config.Auth.Key())
+	b.WriteString("\n" + config.Credential.Key())
+	fmt.Fprintf(&b, "\n%d", config.MaxResponseSize)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n%s: %s", strings.ToLower(name), config.Headers[name])
+	}
+	return b.String()
+}
+
+// requestHeaders returns the configured headers with the credential headers
+// of the auth block applied on top
+func requestHeaders(config *types.EffectiveConfig) map[string]string {
+	if len(config.Credentials) == 0 {
+		return config.Headers
+	}
+
+	headers := make(map[string]string, len(config.Headers)+len(config.Credentials))
+	for k, v := range config.Headers {
+		headers[k] = v
+	}
+	for k, v := range config.Credentials {
+		headers[k] = v
+	}
+	return headers
+}
@@ -0,0 +1,339 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" // #nosec G505 - required by the WebSocket handshake (RFC 6455)
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// WebSocket opcodes (RFC 6455 section 5.2)
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// webSocketGUID is appended to the client key to compute the accept header
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// errFrameTooLarge is returned by readFrame for frames over its limit
+var errFrameTooLarge = errors.New("WebSocket frame exceeds the size limit")
+
+// webSocketConn is a client WebSocket connection carrying one JSON-RPC
+// message per text message
+type webSocketConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	limit  int64 // largest message accepted, including all its fragments
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+	closed    bool
+}
+
+// dialWebSocket connects to a ws:// or wss:// endpoint and performs the
+// opening handshake. Headers are sent with the handshake request; tlsConfig
+// is used for wss://. Messages over limit bytes are rejected.
+func dialWebSocket(
+	ctx context.Context,
+	endpoint *url.URL,
+	headers map[string]string,
+	tlsConfig *tls.Config,
+	limit int64,
+) (*webSocketConn, error) {
+	secure := endpoint.Scheme == "wss"
+
+	address := endpoint.Host
+	if endpoint.Port() == "" {
+		port := "80"
+		if secure {
+			port = "443"
+		}
+		address = net.JoinHostPort(endpoint.Hostname(), port)
+	}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if secure {
+		config := tlsConfig.Clone()
+		if config.ServerName == "" {
+			config.ServerName = endpoint.Hostname()
+		}
+		dialer := &tls.Dialer{Config: config}
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("WebSocket connection failed: %w", err)
+	}
+
+	ws := &webSocketConn{conn: conn, reader: bufio.NewReader(conn), limit: limit}
+	if err := ws.handshake(ctx, endpoint, headers); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+// handshake sends the HTTP upgrade request and validates the response
+func (ws *webSocketConn) handshake(ctx context.Context, endpoint *url.URL, headers map[string]string) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = ws.conn.SetDeadline(deadline)
+		defer func() {
+			_ = ws.conn.SetDeadline(time.Time{})
+		}()
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate WebSocket key: %w", err)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	handshakeURL := *endpoint
+	handshakeURL.Scheme = "http"
+	if endpoint.Scheme == "wss" {
+		handshakeURL.Scheme = "https"
+	}
+
+	req, err := http.NewRequest("GET", handshakeURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create WebSocket handshake: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	if err := req.Write(ws.conn); err != nil {
+		return fmt.Errorf("failed to send WebSocket handshake: %w", err)
+	}
+
+	resp, err := http.ReadResponse(ws.reader, req)
+	if err != nil {
+		return fmt.Errorf("failed to read WebSocket handshake: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return fmt.Errorf("WebSocket handshake failed: %s", resp.Status)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return fmt.Errorf("WebSocket handshake failed: invalid Sec-WebSocket-Accept header")
+	}
+
+	return nil
+}
+
+// ReadMessage reads the next text or binary message, answering pings and
+// reassembling fragmented messages
+func (ws *webSocketConn) ReadMessage() ([]byte, error) {
+	var message []byte
+	inMessage := false
+
+	for {
+		// The limit applies to the message so far, not to each fragment
+		fin, opcode, payload, err := readFrame(ws.reader, ws.limit-int64(len(message)))
+		if errors.Is(err, errFrameTooLarge) {
+			return nil, responseTooLarge(ws.limit)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := ws.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			_ = ws.writeFrame(opClose, payload)
+			return nil, io.EOF
+		case opText, opBinary:
+			if inMessage {
+				return nil, errors.New("WebSocket protocol error: new message before previous one finished")
+			}
+			message = payload
+			inMessage = true
+		case opContinuation:
+			if !inMessage {
+				return nil, errors.New("WebSocket protocol error: unexpected continuation frame")
+			}
+			message = append(message, payload...)
+		default:
+			return nil, fmt.Errorf("WebSocket protocol error: unknown opcode %d", opcode)
+		}
+
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+// WriteMessage sends data as a single text message
+func (ws *webSocketConn) WriteMessage(data []byte) error {
+	return ws.writeFrame(opText, data)
+}
+
+// Close sends a close frame and closes the connection
+func (ws *webSocketConn) Close() error {
+	var err error
+	ws.closeOnce.Do(func() {
+		// Normal closure status code 1000
+		_ = ws.writeFrame(opClose, []byte{0x03, 0xE8})
+
+		ws.writeMu.Lock()
+		ws.closed = true
+		ws.writeMu.Unlock()
+
+		err = ws.conn.Close()
+	})
+	return err
+}
+
+// writeFrame writes a single masked frame
+func (ws *webSocketConn) writeFrame(opcode byte, payload []byte) error {
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+
+	if ws.closed {
+		return errConnectionClosed
+	}
+	return writeFrame(ws.conn, opcode, payload, true)
+}
+
+// writeFrame encodes a final frame with the given opcode. Client frames must
+// be masked; server frames must not.
+func writeFrame(w io.Writer, opcode byte, payload []byte, mask bool) error {
+	header := make([]byte, 2, 14)
+	header[0] = 0x80 | opcode
+
+	var maskBit byte
+	if mask {
+		maskBit = 0x80
+	}
+
+	length := len(payload)
+	switch {
+	case length < 126:
+		header[1] = maskBit | byte(length)
+	case length <= 0xFFFF:
+		header[1] = maskBit | 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = maskBit | 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	data := payload
+	if mask {
+		key := make([]byte, 4)
+		if _, err := rand.Read(key); err != nil {
+			return fmt.Errorf("failed to generate frame mask: %w", err)
+		}
+		header = append(header, key...)
+
+		data = make([]byte, length)
+		for i := range payload {
+			data[i] = payload[i] ^ key[i%4]
+		}
+	}
+
+	if _, err := w.Write(append(header, data...)); err != nil {
+		return fmt.Errorf("failed to write WebSocket frame: %w", err)
+	}
+	return nil
+}
+
+// readFrame decodes a single frame, unmasking its payload if needed. Frames
+// with a payload over limit bytes fail with errFrameTooLarge.
+func readFrame(r *bufio.Reader, limit int64) (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if limit < 0 || length > uint64(limit) {
+		return false, 0, nil, errFrameTooLarge
+	}
+
+	var key [4]byte
+	if masked {
+		if _, err := io.ReadFull(r, key[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	// The buffer grows with the data received rather than the announced length
+	var buf bytes.Buffer
+	buf.Grow(int(min(length, maxPreallocation)))
+	if _, err := io.CopyN(&buf, r, int64(length)); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return false, 0, nil, err
+	}
+	payload = buf.Bytes()
+
+	if masked {
+		for i := range payload {
+			payload[i] ^= key[i%4]
+		}
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// acceptKey computes the Sec-WebSocket-Accept value for a client key
+func acceptKey(key string) string {
+	// #nosec G401 - SHA-1 is mandated by RFC 6455 for the handshake
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,278 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"jsonrpc/pkg/types"
+)
+
+// newWebSocketServer starts a JSON-RPC WebSocket server. Calls are answered
+// concurrently after params[0] milliseconds, echoing the params; calls to
+// "hang" are never answered. Every connection starts with a ping. The number
+// of accepted connections is counted.
+func newWebSocketServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" || r.Header.Get("X-Token") != "secret" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		atomic.AddInt32(&connections, 1)
+
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+			"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		_ = rw.Flush()
+
+		var writeMu sync.Mutex
+		write := func(opcode byte, data []byte) {
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			_ = writeFrame(conn, opcode, data, false)
+		}
+		write(opPing, []byte("hello"))
+
+		reader := bufio.NewReader(rw)
+		for {
+			_, opcode, payload, err := readFrame(reader, maxMessageSize)
+			if err != nil || opcode == opClose {
+				return
+			}
+			if opcode != opText {
+				continue
+			}
+
+			var call struct {
+				Method string `json:"method"`
+				Params []int  `json:"params"`
+				ID     int    `json:"id"`
+			}
+			if err := json.Unmarshal(payload, &call); err != nil || call.Method == "hang" {
+				continue
+			}
+
+			go func() {
+				if len(call.Params) > 0 {
+					time.Sleep(time.Duration(call.Params[0]) * time.Millisecond)
+				}
+				data, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "result": call.Params, "id": call.ID})
+				write(opText, data)
+			}()
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &connections
+}
+
+func webSocketConfig(server *httptest.Server) *types.EffectiveConfig {
+	config := types.NewEffectiveConfig()
+	config.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	config.Headers["X-Token"] = "secret"
+	return config
+}
+
+func TestWebSocket_ConcurrentCalls(t *testing.T) {
+	server, connections := newWebSocketServer(t)
+	config := webSocketConfig(server)
+
+	pool := NewPool(http.DefaultClient)
+	defer func() {
+		_ = pool.Close()
+	}()
+
+	// Slower calls are sent first, so responses arrive out of order
+	var wg sync.WaitGroup
+	for i := 1; i <= 5; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			tr, err := pool.Get(ctx, config)
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+				return
+			}
+
+			delay := (6 - id) * 20
+			payload := []byte(`{"jsonrpc":"2.0","method":"echo","params":[` + strconv.Itoa(delay) + `],"id":` + strconv.Itoa(id) + `}`)
+			data, err := tr.RoundTrip(ctx, config, payload)
+			if err != nil {
+				t.Errorf("RoundTrip(%d) error = %v", id, err)
+				return
+			}
+
+			var resp types.JSONRPCResponse
+			if err := json.Unmarshal(data, &resp); err != nil {
+				t.Errorf("invalid response %s: %v", data, err)
+				return
+			}
+			if resp.IDKey() != strconv.Itoa(id) || string(resp.Result) != "["+strconv.Itoa(delay)+"]" {
+				t.Errorf("call %d got response %s", id, data)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(connections); got != 1 {
+		t.Errorf("server accepted %d connections, want 1", got)
+	}
+}
+
+func TestWebSocket_Timeout(t *testing.T) {
+	server, _ := newWebSocketServer(t)
+	config := webSocketConfig(server)
+
+	pool := NewPool(http.DefaultClient)
+	defer func() {
+		_ = pool.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	tr, err := pool.Get(ctx, config)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	_, err = tr.RoundTrip(ctx, config, []byte(`{"jsonrpc":"2.0","method":"hang","id":1}`))
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("RoundTrip() error = %v, want timeout", err)
+	}
+
+	// The connection stays usable after a timed out call
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel2()
+	if _, err := tr.RoundTrip(ctx2, config, []byte(`{"jsonrpc":"2.0","method":"echo","params":[0],"id":2}`)); err != nil {
+		t.Errorf("RoundTrip() after timeout error = %v", err)
+	}
+}
+
+func TestWebSocket_HandshakeRejected(t *testing.T) {
+	server, _ := newWebSocketServer(t)
+	config := webSocketConfig(server)
+	delete(config.Headers, "X-Token")
+
+	_, err := NewPool(http.DefaultClient).Get(context.Background(), config)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Get() error = %v, want handshake failure", err)
+	}
+}
+
+func TestPool_UnsupportedScheme(t *testing.T) {
+	config := types.NewEffectiveConfig()
+	config.URL = "ftp://example.com"
+
+	_, err := NewPool(http.DefaultClient).Get(context.Background(), config)
+	if err == nil || !strings.Contains(err.Error(), "unsupported URL scheme 'ftp'") {
+		t.Errorf("Get() error = %v, want unsupported scheme", err)
+	}
+}
+
+func TestMessageIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{name: "single", data: `{"id": 1}`, want: []string{"1"}},
+		{name: "string id", data: `{"id": "abc"}`, want: []string{`"abc"`}},
+		{name: "batch", data: `[{"id": 2}, {"id": null}, {"id": 3}]`, want: []string{"2", "3"}},
+		{name: "notification", data: `{"method": "x"}`, want: []string{}},
+		{name: "invalid", data: `not json`, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := messageIDs([]byte(tt.data))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("messageIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebSocket_ReadMessage_Limit(t *testing.T) {
+	// frame encodes an unmasked server frame with a 7-bit length
+	frame := func(fin bool, opcode byte, payload string) string {
+		first := opcode
+		if fin {
+			first |= 0x80
+		}
+		return string([]byte{first, byte(len(payload))}) + payload
+	}
+
+	tests := []struct {
+		name    string
+		frames  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "fragments within limit",
+			frames: frame(false, opText, "aaaa") + frame(true, opContinuation, "bbbb"),
+			want:   "aaaabbbb",
+		},
+		{
+			name:    "fragments over limit",
+			frames:  frame(false, opText, "aaaaaa") + frame(true, opContinuation, "bbbbbb"),
+			wantErr: ErrResponseTooLarge,
+		},
+		{
+			name:    "huge announced length",
+			frames:  "\x81\x7f\x7f\xff\xff\xff\xff\xff\xff\xff{}",
+			wantErr: ErrResponseTooLarge,
+		},
+		{
+			name:    "short payload",
+			frames:  "\x81\x7e\x00\x08{}",
+			wantErr: io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := &webSocketConn{reader: bufio.NewReader(strings.NewReader(tt.frames)), limit: 10}
+
+			got, err := ws.ReadMessage()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ReadMessage() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadMessage() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadMessage() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"context"
+	"sort"
 	"strings"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zclconf/go-cty/cty"
 	"jsonrpc/internal/executor"
 	"jsonrpc/internal/parser"
 	"jsonrpc/pkg/types"
@@ -24,6 +27,8 @@ const (
 	ViewDetail
 	ViewResults
 	ViewHelp
+	ViewWatchSelect
+	ViewWatch
 )
 
 // ExecutionHistory stores past execution results
@@ -36,12 +41,13 @@ type ExecutionHistory struct {
 // Model holds the state of our TUI application
 type Model struct {
 	// Data
-	hclFile  *types.HCLFile
-	requests []*types.Request
-	selected map[int]struct{}
-	results  []*types.ExecutionResult
-	filename string
-	history  []ExecutionHistory
+	hclFile   *types.HCLFile
+	requests  []*types.Request
+	selected  map[int]struct{}
+	results   []*types.ExecutionResult
+	filename  string
+	history   []ExecutionHistory
+	variables map[string]cty.Value
 
 	// File selection
 	hclFiles      []string
@@ -72,6 +78,10 @@ type Model struct {
 	// Execution
 	executor  *executor.Executor
 	overrides *types.CLIOverrides
+	cancelRun context.CancelFunc // cancels the running execution, if any
+
+	// Subscriptions
+	watch watchState
 
 	// Styles
 	styles *Styles
@@ -91,6 +101,9 @@ type keyMap struct {
 	Search      key.Binding
 	Help        key.Binding
 	ClearSearch key.Binding
+	Parallel    key.Binding
+	Watch       key.Binding
+	Cancel      key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
@@ -100,7 +113,7 @@ func (k keyMap) ShortHelp() []key.Binding {
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Select, k.Enter},
-		{k.Run, k.SelectAll, k.DeselectAll},
+		{k.Run, k.Cancel, k.SelectAll, k.DeselectAll, k.Parallel, k.Watch},
 		{k.Search, k.Back, k.Help, k.Quit},
 	}
 }
@@ -155,6 +168,18 @@ func defaultKeyMap() keyMap {
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
 		),
+		Parallel: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "change parallelism"),
+		),
+		Watch: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "watch subscription"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel running requests"),
+		),
 	}
 }
 
@@ -200,6 +225,41 @@ func NewModelWithFileSelect(files []string) *Model {
 	return m
 }
 
+// SetVariables sets the variable values used when parsing HCL files
+func (m *Model) SetVariables(variables map[string]cty.Value) {
+	m.variables = variables
+}
+
+// parallelismLevels are the values the parallelism key cycles through
+var parallelismLevels = []int{1, 2, 4, 8, 16}
+
+// SetParallelism sets how many selected requests run concurrently
+func (m *Model) SetParallelism(n int) {
+	m.executor.SetParallelism(n)
+}
+
+// SetTrace enables showing the wire-level trace of each result
+func (m *Model) SetTrace(enabled bool) {
+	m.executor.SetTrace(enabled)
+}
+
+// cycleParallelism switches to the next parallelism level, wrapping to 1.
+// The level is kept while requests run, since the executor reads it then.
+func (m *Model) cycleParallelism() {
+	if m.loading {
+		return
+	}
+
+	current := m.executor.Parallelism()
+	for _, level := range parallelismLevels {
+		if level > current {
+			m.executor.SetParallelism(level)
+			return
+		}
+	}
+	m.executor.SetParallelism(parallelismLevels[0])
+}
+
 // Init initializes the model
 func (m *Model) Init() tea.Cmd {
 	cmds := []tea.Cmd{m.spinner.Tick}
@@ -213,6 +273,7 @@ func (m *Model) Init() tea.Cmd {
 func (m *Model) LoadFile(filename string) tea.Cmd {
 	return func() tea.Msg {
 		p := parser.New()
+		p.SetVariables(m.variables)
 		hclFile, err := p.ParseFile(filename)
 		if err != nil {
 			return loadFileMsg{err: err}
@@ -263,6 +324,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case executionCompleteMsg:
+		m.stopRun()
 		m.loading = false
 		m.results = msg.results
 		if msg.err != nil {
@@ -284,6 +346,13 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateViewportContent()
 		return m, nil
 
+	case notificationMsg:
+		return m, m.handleNotification(msg)
+
+	case watchEndedMsg:
+		m.handleWatchEnded(msg)
+		return m, nil
+
 	case spinner.TickMsg:
 		m.spinner, cmd = m.spinner.Update(msg)
 		cmds = append(cmds, cmd)
@@ -314,6 +383,10 @@ func (m *Model) View() string {
 		return m.renderResultsView()
 	case ViewHelp:
 		return m.renderHelpView()
+	case ViewWatchSelect:
+		return m.renderWatchSelectView()
+	case ViewWatch:
+		return m.renderWatchView()
 	default:
 		return "Unknown view"
 	}
@@ -334,6 +407,10 @@ func (m *Model) updateViewportContent() {
 		content = m.buildDetailContent()
 	case ViewResults:
 		content = m.buildResultsContent()
+	case ViewWatchSelect:
+		content = m.buildWatchSelectContent()
+	case ViewWatch:
+		content = m.buildWatchContent()
 	}
 
 	m.viewport.SetContent(content)
@@ -342,9 +419,17 @@ func (m *Model) updateViewportContent() {
 // handleKeyMsg processes keyboard input
 func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if key.Matches(msg, m.keys.Quit) {
+		m.stopWatch()
+		m.stopRun()
 		return m, tea.Quit
 	}
 
+	// While requests run, esc cancels them; the results arrive as usual
+	if m.loading && key.Matches(msg, m.keys.Cancel) {
+		m.stopRun()
+		return m, nil
+	}
+
 	if m.currentView != ViewFileSelect && key.Matches(msg, m.keys.Help) {
 		if m.currentView == ViewHelp {
 			m.currentView = ViewList
@@ -365,6 +450,10 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleResultsKeys(msg)
 	case ViewHelp:
 		return m.handleHelpKeys(msg)
+	case ViewWatchSelect:
+		return m.handleWatchSelectKeys(msg)
+	case ViewWatch:
+		return m.handleWatchKeys(msg)
 	}
 	return m, nil
 }
@@ -419,6 +508,10 @@ func (m *Model) handleListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.selectAll()
 	case key.Matches(msg, m.keys.DeselectAll):
 		m.deselectAll()
+	case key.Matches(msg, m.keys.Parallel):
+		m.cycleParallelism()
+	case key.Matches(msg, m.keys.Watch):
+		m.openWatchSelect()
 	}
 	return m, nil
 }
@@ -565,8 +658,18 @@ func (m *Model) executeSelected() tea.Cmd {
 	m.loading = true
 	m.error = nil
 
-	var selectedReqs []*types.Request
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelRun = cancel
+
+	// Run in file order so results are listed the way requests are
+	indices := make([]int, 0, len(m.selected))
 	for idx := range m.selected {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	var selectedReqs []*types.Request
+	for _, idx := range indices {
 		if idx < len(m.requests) {
 			selectedReqs = append(selectedReqs, m.requests[idx])
 		}
@@ -575,12 +678,20 @@ func (m *Model) executeSelected() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
 		func() tea.Msg {
-			results, err := m.executor.ExecuteAll(m.hclFile, selectedReqs, m.overrides)
+			results, err := m.executor.ExecuteAll(ctx, m.hclFile, selectedReqs, m.overrides)
 			return executionCompleteMsg{results: results, err: err}
 		},
 	)
 }
 
+// stopRun cancels the running execution, if any
+func (m *Model) stopRun() {
+	if m.cancelRun != nil {
+		m.cancelRun()
+		m.cancelRun = nil
+	}
+}
+
 // filterRequests filters requests based on search input
 func (m *Model) filterRequests() {
 	query := strings.ToLower(strings.TrimSpace(m.searchInput.Value()))
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"jsonrpc/internal/output"
 	"jsonrpc/pkg/types"
 )
 
@@ -101,7 +102,8 @@ func (m *Model) renderListView() string {
 	selectedCount := len(m.selected)
 	totalCount := len(m.requests)
 	filteredCount := len(m.filteredReqs)
-	statusLine := fmt.Sprintf("Total: %d | Filtered: %d | Selected: %d", totalCount, filteredCount, selectedCount)
+	statusLine := fmt.Sprintf("Total: %d | Filtered: %d | Selected: %d | Parallel: %d",
+		totalCount, filteredCount, selectedCount, m.executor.Parallelism())
 	b.WriteString(m.styles.InstructionsStyle.Render(statusLine))
 	b.WriteString("\n")
 
@@ -218,7 +220,11 @@ func (m *Model) buildDetailContent() string {
 	if req.Config != "" {
 		if config, exists := m.hclFile.Configs[req.Config]; exists {
 			details = append(details, m.renderDetailField("Config", req.Config, m.styles.ConfigStyle))
-			details = append(details, m.renderDetailField("URL", config.URL, m.styles.ValueStyle))
+			if len(config.Command) > 0 {
+				details = append(details, m.renderDetailField("Command", strings.Join(config.Command, " "), m.styles.ValueStyle))
+			} else {
+				details = append(details, m.renderDetailField("URL", config.URL, m.styles.ValueStyle))
+			}
 			details = append(details, m.renderDetailField("Timeout", fmt.Sprintf("%ds", config.Timeout), m.styles.ValueStyle))
 
 			if len(config.Headers) > 0 {
@@ -280,7 +286,7 @@ func (m *Model) renderResultsView() string {
 
 	if m.loading {
 		b.WriteString("\n")
-		b.WriteString(m.spinner.View() + " Executing requests...\n")
+		b.WriteString(m.spinner.View() + " Executing requests... (esc to cancel)\n")
 		return b.String()
 	}
 
@@ -369,6 +375,9 @@ func (m *Model) renderResult(index int, result *types.ExecutionResult) string {
 
 	if result.Error != nil {
 		details = append(details, m.styles.ErrorStyle.Render("Error: "+result.Error.Error()))
+		if result.Attempts > 1 {
+			details = append(details, m.renderDetailField("Attempts", fmt.Sprintf("%d", result.Attempts), m.styles.ValueStyle))
+		}
 	} else {
 		details = append(details, m.styles.KeyStyle.Render("Status: ")+statusStyle.Render("Success"))
 
@@ -377,6 +386,9 @@ func (m *Model) renderResult(index int, result *types.ExecutionResult) string {
 				fmt.Sprintf("%dms", result.Duration.Milliseconds()),
 				m.styles.ValueStyle))
 		}
+		if result.Attempts > 1 {
+			details = append(details, m.renderDetailField("Attempts", fmt.Sprintf("%d", result.Attempts), m.styles.ValueStyle))
+		}
 
 		if result.Response != nil {
 			details = append(details, "")
@@ -391,6 +403,14 @@ func (m *Model) renderResult(index int, result *types.ExecutionResult) string {
 		}
 	}
 
+	if result.Trace != nil {
+		details = append(details, "")
+		details = append(details, m.styles.SectionHeader.Render("Trace:"))
+		for _, line := range output.TraceLines(result.Trace) {
+			details = append(details, m.styles.ValueStyle.Render(line))
+		}
+	}
+
 	b.WriteString(boxStyle.Render(strings.Join(details, "\n")))
 
 	return b.String()
@@ -417,6 +437,9 @@ func (m *Model) buildFooter() string {
 
 	if m.searchMode {
 		parts = append(parts, "ESC: exit search")
+	} else if m.loading {
+		parts = append(parts, "running... ESC: cancel")
+		parts = append(parts, "q: quit")
 	} else {
 		switch m.currentView {
 		case ViewList:
@@ -424,6 +447,8 @@ func (m *Model) buildFooter() string {
 			parts = append(parts, "/: search")
 			parts = append(parts, "space: select")
 			parts = append(parts, "r: run")
+			parts = append(parts, "p: parallel")
+			parts = append(parts, "w: watch")
 		case ViewDetail:
 			parts = append(parts, "ESC: back")
 			parts = append(parts, "space: select")
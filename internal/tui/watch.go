@@ -0,0 +1,262 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"jsonrpc/internal/executor"
+	"jsonrpc/pkg/types"
+)
+
+// maxWatchNotifications is the number of notifications kept in the live pane
+const maxWatchNotifications = 200
+
+// watchState holds the state of the subscription picker and live pane
+type watchState struct {
+	cursor        int
+	subscription  *types.Subscription
+	notifications []*types.Notification
+	received      int
+	cancel        context.CancelFunc
+	events        chan tea.Msg
+	err           error
+}
+
+// Watch messages
+type notificationMsg struct {
+	notification *types.Notification
+}
+
+type watchEndedMsg struct {
+	err error
+}
+
+// openWatchSelect shows the subscription picker
+func (m *Model) openWatchSelect() {
+	if m.hclFile == nil || len(m.hclFile.Subscriptions) == 0 {
+		m.error = fmt.Errorf("no subscribe blocks in %s", m.filename)
+		return
+	}
+	m.error = nil
+	m.watch.cursor = 0
+	m.currentView = ViewWatchSelect
+	m.updateViewportContent()
+}
+
+// handleWatchSelectKeys handles keys in the subscription picker
+func (m *Model) handleWatchSelectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.currentView = ViewList
+	case key.Matches(msg, m.keys.Up):
+		if m.watch.cursor > 0 {
+			m.watch.cursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.watch.cursor < len(m.hclFile.Subscriptions)-1 {
+			m.watch.cursor++
+		}
+	case key.Matches(msg, m.keys.Enter):
+		if m.watch.cancel != nil {
+			m.error = fmt.Errorf("still stopping %s", m.watch.subscription.Name)
+			return m, nil
+		}
+		cmd := m.startWatch(m.hclFile.Subscriptions[m.watch.cursor])
+		m.updateViewportContent()
+		return m, cmd
+	}
+
+	m.updateViewportContent()
+	return m, nil
+}
+
+// handleWatchKeys handles keys in the live notification pane
+func (m *Model) handleWatchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keys.Back) {
+		m.stopWatch()
+		m.currentView = ViewList
+		m.updateViewportContent()
+	}
+	return m, nil
+}
+
+// startWatch subscribes in the background and streams notifications to the
+// model as messages
+func (m *Model) startWatch(subscription *types.Subscription) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan tea.Msg, 64)
+
+	m.watch.subscription = subscription
+	m.watch.notifications = nil
+	m.watch.received = 0
+	m.watch.err = nil
+	m.watch.cancel = cancel
+	m.watch.events = events
+	m.currentView = ViewWatch
+
+	// A dedicated executor keeps the subscription's connection separate from
+	// request runs
+	exec := executor.New()
+	hclFile := m.hclFile
+	overrides := m.overrides
+
+	go func() {
+		err := exec.Subscribe(ctx, hclFile, subscription, overrides, func(notification *types.Notification) {
+			events <- notificationMsg{notification: notification}
+		})
+		events <- watchEndedMsg{err: err}
+		close(events)
+	}()
+
+	return waitForWatchEvent(events)
+}
+
+// stopWatch cancels the running subscription; it is unsubscribed in the
+// background and reports back with a watchEndedMsg
+func (m *Model) stopWatch() {
+	if m.watch.cancel != nil {
+		m.watch.cancel()
+	}
+}
+
+// waitForWatchEvent returns a command that waits for the next watch event
+func waitForWatchEvent(events chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, open := <-events
+		if !open {
+			return nil
+		}
+		return msg
+	}
+}
+
+// handleNotification adds a notification to the live pane
+func (m *Model) handleNotification(msg notificationMsg) tea.Cmd {
+	m.watch.received++
+	m.watch.notifications = append(m.watch.notifications, msg.notification)
+	if len(m.watch.notifications) > maxWatchNotifications {
+		m.watch.notifications = m.watch.notifications[len(m.watch.notifications)-maxWatchNotifications:]
+	}
+
+	if m.currentView == ViewWatch {
+		atBottom := m.viewport.AtBottom()
+		m.updateViewportContent()
+		if atBottom {
+			m.viewport.GotoBottom()
+		}
+	}
+
+	return waitForWatchEvent(m.watch.events)
+}
+
+// handleWatchEnded records why a watch stopped
+func (m *Model) handleWatchEnded(msg watchEndedMsg) {
+	m.watch.cancel = nil
+	m.watch.err = msg.err
+	if m.currentView == ViewWatch {
+		m.updateViewportContent()
+	}
+}
+
+func (m *Model) renderWatchSelectView() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.HeaderStyle.Render(" Watch Subscription "))
+	b.WriteString("\n")
+	b.WriteString(m.styles.InstructionsStyle.Render(
+		fmt.Sprintf("%d subscription(s) in %s", len(m.hclFile.Subscriptions), m.filename)))
+	b.WriteString("\n")
+
+	content := strings.TrimSpace(m.viewport.View())
+	if content != "" {
+		b.WriteString(content)
+		b.WriteString("\n")
+	}
+
+	b.WriteString(m.styles.FooterStyle.Render("↑/k: up | ↓/j: down | enter: watch | ESC: back | q: quit"))
+
+	if m.error != nil {
+		b.WriteString("\n")
+		b.WriteString(m.styles.ErrorStyle.Render("⚠ Error: " + m.error.Error()))
+	}
+
+	return b.String()
+}
+
+func (m *Model) buildWatchSelectContent() string {
+	var b strings.Builder
+
+	for i, subscription := range m.hclFile.Subscriptions {
+		cursor := " "
+		nameStyle := m.styles.ItemNameStyle
+		if i == m.watch.cursor {
+			cursor = m.styles.AccentStyle.Render("→")
+			nameStyle = nameStyle.Background(m.styles.CursorColor).Foreground(lipgloss.Color("0"))
+		}
+
+		b.WriteString(cursor)
+		b.WriteString(" ")
+		b.WriteString(nameStyle.Render(subscription.Name))
+		b.WriteString("  ")
+		b.WriteString(m.styles.InstructionsStyle.Render(subscription.Request.Method))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func (m *Model) renderWatchView() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.HeaderStyle.Render(" Watching: " + m.watch.subscription.Name + " "))
+	b.WriteString("\n")
+
+	status := "stopped"
+	if m.watch.cancel != nil {
+		status = m.spinner.View() + "live"
+	}
+	b.WriteString(m.styles.InstructionsStyle.Render(
+		fmt.Sprintf("Notifications: %d | %s", m.watch.received, status)))
+	b.WriteString("\n")
+
+	b.WriteString(m.viewport.View())
+	b.WriteString("\n")
+
+	b.WriteString(m.styles.FooterStyle.Render("ESC: stop and go back | q: quit"))
+
+	if m.watch.err != nil {
+		b.WriteString("\n")
+		b.WriteString(m.styles.ErrorStyle.Render("⚠ Error: " + m.watch.err.Error()))
+	}
+
+	return b.String()
+}
+
+func (m *Model) buildWatchContent() string {
+	if len(m.watch.notifications) == 0 {
+		return m.styles.InstructionsStyle.Render("Waiting for notifications...")
+	}
+
+	var b strings.Builder
+	for _, notification := range m.watch.notifications {
+		b.WriteString(m.styles.SectionHeader.Render(fmt.Sprintf("[%s] #%d",
+			notification.Received.Format("15:04:05.000"), notification.Sequence)))
+		b.WriteString("\n")
+
+		var resultObj any
+		if err := json.Unmarshal(notification.Result, &resultObj); err == nil {
+			resultJSON, _ := json.MarshalIndent(resultObj, "", "  ")
+			b.WriteString(m.highlightJSON(string(resultJSON)))
+		} else {
+			b.WriteString(string(notification.Result))
+		}
+		b.WriteString("\n\n")
+	}
+
+	return b.String()
+}
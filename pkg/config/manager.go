@@ -4,16 +4,13 @@ import (
 	"jsonrpc/pkg/types"
 )
 
-// Manager manages configuration sources and building effective configurations
-type Manager struct {
-	merger *Merger
-}
+// Manager manages configuration sources and building effective configurations.
+// Each build uses its own Merger, so a Manager is safe for concurrent use.
+type Manager struct{}
 
 // NewManager creates a new configuration manager
 func NewManager() *Manager {
-	return &Manager{
-		merger: NewMerger(),
-	}
+	return &Manager{}
 }
 
 // BuildForRequest builds an effective configuration for a specific request
@@ -23,67 +20,70 @@ func (m *Manager) BuildForRequest(
 	request *types.Request,
 	cliOverrides *types.CLIOverrides,
 ) *types.EffectiveConfig {
-	m.merger.ClearSources()
+	merger := NewMerger()
 
 	// Add sources in priority order (they will be auto-sorted by the merger)
 
 	// 1. Default config (priority: 10)
 	if defaultConfig, exists := hclFile.Configs[DefaultConfigName]; exists {
-		m.merger.AddSource(NewDefaultConfigSource(defaultConfig))
+		merger.AddSource(NewDefaultConfigSource(defaultConfig))
 	}
 
 	// 2. Named config if specified (priority: 20)
 	configName := GetConfigName(request, cliOverrides)
 	if configName != "" && configName != DefaultConfigName {
 		if namedConfig, exists := hclFile.Configs[configName]; exists {
-			m.merger.AddSource(NewNamedConfigSource(configName, namedConfig))
+			merger.AddSource(NewNamedConfigSource(configName, namedConfig))
 		}
 	}
 
 	// 3. Request overrides (priority: 30)
-	m.merger.AddSource(NewRequestConfigSource(request))
+	merger.AddSource(NewRequestConfigSource(request))
 
 	// 4. CLI overrides (priority: 40)
 	if cliOverrides != nil {
-		m.merger.AddSource(NewCLIConfigSource(cliOverrides))
+		merger.AddSource(NewCLIConfigSource(cliOverrides))
 	}
 
-	return m.merger.BuildEffective()
+	config := merger.BuildEffective()
+	config.Profile = m.GetConfigNameForRequest(hclFile, request, cliOverrides)
+	config.RateLimitGlobal = cliOverrides != nil && cliOverrides.RateLimit != ""
+	return config
 }
 
 // BuildForCLI builds an effective configuration using only CLI overrides
 func (m *Manager) BuildForCLI(cliOverrides *types.CLIOverrides) *types.EffectiveConfig {
-	m.merger.ClearSources()
+	merger := NewMerger()
 
 	if cliOverrides != nil {
-		m.merger.AddSource(NewCLIConfigSource(cliOverrides))
+		merger.AddSource(NewCLIConfigSource(cliOverrides))
 	}
 
-	return m.merger.BuildEffective()
+	config := merger.BuildEffective()
+	config.RateLimitGlobal = cliOverrides != nil && cliOverrides.RateLimit != ""
+	return config
 }
 
-// GetConfigNameForRequest returns the effective configuration name for a request
+// GetConfigNameForRequest returns the effective configuration name for a request,
+// or an empty string when the file has neither that config nor a default one
 func (m *Manager) GetConfigNameForRequest(
 	hclFile *types.HCLFile,
 	request *types.Request,
 	cliOverrides *types.CLIOverrides,
 ) string {
-	m.merger.ClearSources()
-
 	// Build sources just for config name determination
 	configName := GetConfigName(request, cliOverrides)
 
 	// Validate that the config exists in HCL file
-	if configName != "" && configName != DefaultConfigName {
-		if _, exists := hclFile.Configs[configName]; !exists {
-			// Fallback to default if named config doesn't exist
-			if _, hasDefault := hclFile.Configs["default"]; hasDefault {
-				configName = "default"
-			}
-		}
+	if _, exists := hclFile.Configs[configName]; exists {
+		return configName
 	}
 
-	return configName
+	// Fallback to default if named config doesn't exist
+	if _, hasDefault := hclFile.Configs[DefaultConfigName]; hasDefault {
+		return DefaultConfigName
+	}
+	return ""
 }
 
 // GetConfigName is a utility function to determine the config name for a request
@@ -208,3 +208,31 @@ func TestManager_GetConfigNameForRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_GetConfigNameForRequest_NoDefault(t *testing.T) {
+	hclFile := &types.HCLFile{
+		Configs: map[string]*types.Config{
+			"production": {URL: "https://prod.example.com"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		request *types.Request
+		want    string
+	}{
+		{name: "Existing named config", request: &types.Request{Config: "production"}, want: "production"},
+		{name: "Non-existent config", request: &types.Request{Config: "non-existent"}, want: ""},
+		{name: "No config", request: &types.Request{}, want: ""},
+	}
+
+	manager := NewManager()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := manager.GetConfigNameForRequest(hclFile, tt.request, nil)
+			if got != tt.want {
+				t.Errorf("GetConfigNameForRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
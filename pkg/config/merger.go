@@ -86,8 +86,17 @@ func (m *Merger) GetConfigName() string {
 // mergeInto merges a source configuration into an effective configuration
 // This is the core merging logic that eliminates duplication
 func (m *Merger) mergeInto(effective *types.EffectiveConfig, source *types.Config) {
+	// A URL or a command replaces whichever endpoint was inherited
 	if source.URL != "" {
 		effective.URL = source.URL
+		effective.Command = nil
+	}
+	if len(source.Command) > 0 {
+		effective.Command = source.Command
+		effective.URL = ""
+	}
+	if source.Framing != "" {
+		effective.Framing = source.Framing
 	}
 
 	// Merge headers - source headers override existing ones
@@ -98,6 +107,98 @@ func (m *Merger) mergeInto(effective *types.EffectiveConfig, source *types.Confi
 	if source.Timeout > 0 {
 		effective.Timeout = source.Timeout
 	}
+
+	if source.Retry != nil {
+		effective.Retry = mergeRetry(effective.Retry, source.Retry)
+	}
+
+	if source.RateLimit != "" {
+		effective.RateLimit = source.RateLimit
+	}
+
+	if source.IDStrategy != "" {
+		effective.IDStrategy = source.IDStrategy
+	}
+
+	if source.MaxResponseSize > 0 {
+		effective.MaxResponseSize = source.MaxResponseSize
+	}
+
+	if source.TLS != nil {
+		effective.TLS = mergeTLS(effective.TLS, source.TLS)
+	}
+
+	// Auth blocks of different types do not mix, so they are not merged
+	if source.Auth != nil {
+		effective.Auth = source.Auth
+	}
+	if source.Credential != nil {
+		effective.Credential = source.Credential
+	}
+	if source.Signing != nil {
+		effective.Signing = source.Signing
+	}
+}
+
+// mergeRetry returns a copy of base with the fields set in source applied
+func mergeRetry(base, source *types.RetryPolicy) *types.RetryPolicy {
+	merged := &types.RetryPolicy{}
+	if base != nil {
+		*merged = *base
+	}
+
+	if source.MaxAttempts > 0 {
+		merged.MaxAttempts = source.MaxAttempts
+	}
+	if source.BackoffBase > 0 {
+		merged.BackoffBase = source.BackoffBase
+	}
+	if source.BackoffMax > 0 {
+		merged.BackoffMax = source.BackoffMax
+	}
+	if source.Jitter != nil {
+		merged.Jitter = source.Jitter
+	}
+	if source.RetryOnStatus != nil {
+		merged.RetryOnStatus = source.RetryOnStatus
+	}
+	if source.RetryOnCodes != nil {
+		merged.RetryOnCodes = source.RetryOnCodes
+	}
+	if source.HonorRetryAfter != nil {
+		merged.HonorRetryAfter = source.HonorRetryAfter
+	}
+
+	return merged
+}
+
+// mergeTLS returns a copy of base with the fields set in source applied
+func mergeTLS(base, source *types.TLSConfig) *types.TLSConfig {
+	merged := &types.TLSConfig{}
+	if base != nil {
+		*merged = *base
+	}
+
+	if source.CAFile != "" {
+		merged.CAFile = source.CAFile
+	}
+	if source.CertFile != "" {
+		merged.CertFile = source.CertFile
+	}
+	if source.KeyFile != "" {
+		merged.KeyFile = source.KeyFile
+	}
+	if source.ServerName != "" {
+		merged.ServerName = source.ServerName
+	}
+	if source.InsecureSkipVerify != nil {
+		merged.InsecureSkipVerify = source.InsecureSkipVerify
+	}
+	if source.MinVersion != "" {
+		merged.MinVersion = source.MinVersion
+	}
+
+	return merged
 }
 
 // ClearSources removes all sources from the merger
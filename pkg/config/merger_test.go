@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 
 	"jsonrpc/pkg/types"
@@ -92,6 +93,103 @@ func TestMerger_BuildEffective(t *testing.T) {
 	}
 }
 
+func TestMerger_BuildEffective_Endpoint(t *testing.T) {
+	tests := []struct {
+		name        string
+		named       *types.Config
+		overrides   *types.CLIOverrides
+		wantURL     string
+		wantCommand []string
+	}{
+		{
+			name:        "command replaces default url",
+			named:       &types.Config{Command: []string{"./server"}, Framing: "ndjson"},
+			wantCommand: []string{"./server"},
+		},
+		{
+			name:      "cli url replaces command",
+			named:     &types.Config{Command: []string{"./server"}},
+			overrides: &types.CLIOverrides{URL: "http://localhost:8545"},
+			wantURL:   "http://localhost:8545",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merger := NewMerger()
+			merger.AddSource(NewDefaultConfigSource(&types.Config{URL: "https://default.example.com"}))
+			merger.AddSource(NewNamedConfigSource("local", tt.named))
+			if tt.overrides != nil {
+				merger.AddSource(NewCLIConfigSource(tt.overrides))
+			}
+
+			config := merger.BuildEffective()
+			if config.URL != tt.wantURL {
+				t.Errorf("URL = %q, want %q", config.URL, tt.wantURL)
+			}
+			if strings.Join(config.Command, " ") != strings.Join(tt.wantCommand, " ") {
+				t.Errorf("Command = %v, want %v", config.Command, tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestMerger_BuildEffective_Retry(t *testing.T) {
+	jitter := false
+
+	merger := NewMerger()
+	merger.AddSource(NewNamedConfigSource("public", &types.Config{
+		Retry: &types.RetryPolicy{MaxAttempts: 5, Jitter: &jitter, RetryOnStatus: []int{429}},
+	}))
+	merger.AddSource(NewRequestConfigSource(&types.Request{
+		Retry: &types.RetryPolicy{MaxAttempts: 2, RetryOnCodes: []int{-32005}},
+	}))
+
+	retry := merger.BuildEffective().Retry
+	if retry == nil {
+		t.Fatal("Expected merged retry policy, got nil")
+	}
+
+	// Fields are merged individually
+	if retry.MaxAttempts != 2 {
+		t.Errorf("Expected max attempts from request, got %d", retry.MaxAttempts)
+	}
+	if retry.Jitter == nil || *retry.Jitter {
+		t.Errorf("Expected jitter from config, got %v", retry.Jitter)
+	}
+	if len(retry.RetryOnStatus) != 1 || len(retry.RetryOnCodes) != 1 {
+		t.Errorf("Expected statuses from config and codes from request, got %v and %v",
+			retry.RetryOnStatus, retry.RetryOnCodes)
+	}
+
+	if NewMerger().BuildEffective().Retry != nil {
+		t.Error("Expected no retry policy without retry blocks")
+	}
+}
+
+func TestMerger_BuildEffective_TLS(t *testing.T) {
+	insecure := true
+
+	merger := NewMerger()
+	merger.AddSource(NewNamedConfigSource("internal", &types.Config{
+		TLS: &types.TLSConfig{CAFile: "ca.pem", ServerName: "node.internal"},
+	}))
+	merger.AddSource(NewCLIConfigSource(&types.CLIOverrides{
+		TLS: &types.TLSConfig{CertFile: "client.pem", KeyFile: "client-key.pem", InsecureSkipVerify: &insecure},
+	}))
+
+	tls := merger.BuildEffective().TLS
+	if tls == nil {
+		t.Fatal("Expected merged TLS settings, got nil")
+	}
+	if tls.CAFile != "ca.pem" || tls.ServerName != "node.internal" {
+		t.Errorf("Expected CA file and server name from config, got %+v", tls)
+	}
+	if tls.CertFile != "client.pem" || tls.KeyFile != "client-key.pem" || !*tls.InsecureSkipVerify {
+		t.Errorf("Expected client certificate and insecure from CLI, got %+v", tls)
+	}
+}
+
 func TestMerger_GetConfigName(t *testing.T) {
 	merger := NewMerger()
 
@@ -86,9 +86,12 @@ func (s *RequestConfigSource) Name() string {
 
 func (s *RequestConfigSource) GetConfig() *types.Config {
 	return &types.Config{
-		URL:     s.request.URL,
-		Headers: s.request.Headers,
-		Timeout: s.request.Timeout,
+		URL:        s.request.URL,
+		Headers:    s.request.Headers,
+		Timeout:    s.request.Timeout,
+		Retry:      s.request.Retry,
+		Auth:       s.request.Auth,
+		IDStrategy: s.request.IDStrategy,
 	}
 }
 
@@ -112,9 +115,13 @@ func (s *CLIConfigSource) Name() string {
 
 func (s *CLIConfigSource) GetConfig() *types.Config {
 	return &types.Config{
-		URL:     s.overrides.URL,
-		Headers: s.overrides.Headers,
-		Timeout: s.overrides.Timeout,
+		URL:       s.overrides.URL,
+		Headers:   s.overrides.Headers,
+		Timeout:   s.overrides.Timeout,
+		RateLimit: s.overrides.RateLimit,
+		TLS:       s.overrides.TLS,
+
+		MaxResponseSize: s.overrides.MaxResponseSize,
 	}
 }
 
@@ -38,3 +38,39 @@ const (
 	// MinClientErrorStatus is the minimum HTTP status code considered a client error
 	MinClientErrorStatus = 400
 )
+
+// Retry defaults, used for settings a retry block leaves unset
+const (
+	// DefaultRetryMaxAttempts is the number of attempts, including the first
+	DefaultRetryMaxAttempts = 3
+
+	// DefaultRetryBackoffBaseMs is the delay before the first retry in milliseconds
+	DefaultRetryBackoffBaseMs = 500
+
+	// DefaultRetryBackoffMaxMs is the upper bound of the backoff delay in milliseconds
+	DefaultRetryBackoffMaxMs = 10000
+)
+
+// Signing defaults, used for settings a signing block leaves unset
+const (
+	// DefaultSignatureHeader carries the HMAC signature
+	DefaultSignatureHeader = "X-Signature"
+
+	// DefaultTimestampHeader carries the Unix timestamp in seconds
+	DefaultTimestampHeader = "X-Timestamp"
+
+	// DefaultNonceHeader carries the random nonce
+	DefaultNonceHeader = "X-Nonce"
+
+	// DefaultSigningTemplate is the canonical string that is signed
+	DefaultSigningTemplate = "{timestamp}\n{nonce}\n{body}"
+)
+
+// Bench defaults
+const (
+	// DefaultBenchConcurrency is the number of requests bench keeps in flight
+	DefaultBenchConcurrency = 10
+
+	// DefaultBenchDurationSeconds is how long bench runs without --duration or --requests
+	DefaultBenchDurationSeconds = 10
+)
@@ -1,9 +1,14 @@
 package types
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
 	"jsonrpc/pkg/constants"
 )
@@ -13,6 +18,162 @@ type Config struct {
 	URL     string            `hcl:"url,optional" json:"url,omitempty"`
 	Headers map[string]string `hcl:"headers,optional" json:"headers,omitempty"`
 	Timeout int               `hcl:"timeout,optional" json:"timeout,omitempty"` // in seconds
+
+	// Command starts a local JSON-RPC server speaking over stdin/stdout,
+	// as an alternative to URL. Framing is "ndjson" or "content-length".
+	Command []string `hcl:"command,optional" json:"command,omitempty"`
+	Framing string   `hcl:"framing,optional" json:"framing,omitempty"`
+
+	Retry *RetryPolicy `hcl:"-" json:"retry,omitempty"`
+
+	// RateLimit caps the requests sent with this profile, e.g. "25/s"
+	RateLimit string `hcl:"rate_limit,optional" json:"rate_limit,omitempty"`
+
+	// IDStrategy chooses request ids: sequential, uuid, prefix:<p> or fixed:<id>
+	IDStrategy string `hcl:"id_strategy,optional" json:"id_strategy,omitempty"`
+
+	// MaxResponseSize is the largest response accepted in bytes, parsed
+	// from max_response_size (e.g. "100MB"); zero for no limit
+	MaxResponseSize int64 `hcl:"-" json:"max_response_size,omitempty"`
+
+	TLS  *TLSConfig  `hcl:"-" json:"tls,omitempty"`
+	Auth *AuthConfig `hcl:"-" json:"auth,omitempty"`
+
+	// Credential is set by the credential_command, credential_header and
+	// credential_cache attributes
+	Credential *CredentialHelper `hcl:"-" json:"credential,omitempty"`
+
+	Signing *SigningConfig `hcl:"-" json:"signing,omitempty"`
+}
+
+// CredentialHelper is a command printing a credential, like kubectl exec
+// credentials or git credential helpers
+type CredentialHelper struct {
+	Command []string `json:"command"`
+	Header  string   `json:"header"`          // header receiving the credential
+	Cache   bool     `json:"cache,omitempty"` // cache on disk until expiry
+}
+
+// Key identifies the helper, so that cached credentials can be shared
+func (h *CredentialHelper) Key() string {
+	if h == nil {
+		return ""
+	}
+	return strings.Join(h.Command, "\x00") + "\x00" + h.Header + "\x00" + strconv.FormatBool(h.Cache)
+}
+
+// SigningHMACSHA256 is the algorithm of signing blocks
+const SigningHMACSHA256 = "hmac-sha256"
+
+// SigningConfig holds the settings of a signing block. The parser fills in
+// defaults, and a signing block replaces an inherited one as a whole.
+type SigningConfig struct {
+	Algorithm string `json:"algorithm"`
+
+	// Exactly one secret source
+	Secret     string `json:"-"`
+	SecretEnv  string `json:"secret_env,omitempty"`
+	SecretFile string `json:"secret_file,omitempty"`
+
+	SignatureHeader string `json:"signature_header"`
+	TimestampHeader string `json:"timestamp_header"`
+	NonceHeader     string `json:"nonce_header"`
+
+	// Template is the canonical string, with {timestamp}, {nonce}, {path}
+	// (URL path) and {body} placeholders
+	Template string `json:"template"`
+	Encoding string `json:"encoding"` // hex or base64
+}
+
+// Key identifies the settings, so that requests signed differently are not
+// batched together
+func (c *SigningConfig) Key() string {
+	if c == nil {
+		return ""
+	}
+	return strings.Join([]string{
+		c.Algorithm, c.Secret, c.SecretEnv, c.SecretFile, c.SignatureHeader,
+		c.TimestampHeader, c.NonceHeader, c.Template, c.Encoding,
+	}, "\x00")
+}
+
+// Authentication types supported in auth blocks
+const (
+	AuthBasic                   = "basic"
+	AuthBearer                  = "bearer"
+	AuthOAuth2ClientCredentials = "oauth2_client_credentials"
+	AuthJWTHS256                = "jwt_hs256"
+)
+
+// AuthConfig holds the settings of an auth block. Which fields apply
+// depends on Type. An auth block replaces an inherited one as a whole.
+type AuthConfig struct {
+	Type string `json:"type"`
+
+	// basic
+	Username string `json:"username,omitempty"`
+	Password string `json:"-"`
+
+	// bearer: exactly one token source
+	Token     string `json:"-"`
+	TokenEnv  string `json:"token_env,omitempty"`
+	TokenFile string `json:"token_file,omitempty"`
+
+	// oauth2_client_credentials
+	TokenURL     string   `json:"token_url,omitempty"`
+	ClientID     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"-"`
+	Scopes       []string `json:"scopes,omitempty"`
+
+	// jwt_hs256: hex-encoded shared secret, e.g. an Engine API jwt.hex
+	SecretFile string `json:"secret_file,omitempty"`
+}
+
+// Key identifies the settings, so that providers and cached tokens can be
+// shared by requests using the same auth block
+func (c *AuthConfig) Key() string {
+	if c == nil {
+		return ""
+	}
+	return strings.Join([]string{
+		c.Type, c.Username, c.Password, c.Token, c.TokenEnv, c.TokenFile,
+		c.TokenURL, c.ClientID, c.ClientSecret, strings.Join(c.Scopes, " "), c.SecretFile,
+	}, "\x00")
+}
+
+// TLSConfig holds the settings of a tls block. Unset fields are inherited
+// from lower priority sources when merging.
+type TLSConfig struct {
+	CAFile             string `json:"ca_file,omitempty"`
+	CertFile           string `json:"cert_file,omitempty"`
+	KeyFile            string `json:"key_file,omitempty"`
+	ServerName         string `json:"server_name,omitempty"`
+	InsecureSkipVerify *bool  `json:"insecure_skip_verify,omitempty"`
+	MinVersion         string `json:"min_version,omitempty"`
+}
+
+// Key identifies the settings, so that connections and clients using the
+// same settings can be shared
+func (c *TLSConfig) Key() string {
+	if c == nil {
+		return ""
+	}
+	insecure := c.InsecureSkipVerify != nil && *c.InsecureSkipVerify
+	return strings.Join([]string{
+		c.CAFile, c.CertFile, c.KeyFile, c.ServerName, strconv.FormatBool(insecure), c.MinVersion,
+	}, "\x00")
+}
+
+// RetryPolicy holds the settings of a retry block. Unset fields (zero or
+// nil) are inherited from lower priority sources when merging.
+type RetryPolicy struct {
+	MaxAttempts     int           `json:"max_attempts,omitempty"`
+	BackoffBase     time.Duration `json:"backoff_base,omitempty"`
+	BackoffMax      time.Duration `json:"backoff_max,omitempty"`
+	Jitter          *bool         `json:"jitter,omitempty"`
+	RetryOnStatus   []int         `json:"retry_on_status,omitempty"`
+	RetryOnCodes    []int         `json:"retry_on_codes,omitempty"`
+	HonorRetryAfter *bool         `json:"honor_retry_after,omitempty"`
 }
 
 // NewConfig creates a new Config with sensible defaults
@@ -33,6 +194,19 @@ type Request struct {
 	Timeout         int               `hcl:"timeout,optional" json:"timeout,omitempty"`
 	Config          string            `hcl:"config,optional" json:"config,omitempty"`
 	ProcessedParams any               `hcl:"-" json:"params,omitempty"`
+	Expect          *Expectation      `hcl:"-" json:"expect,omitempty"`
+	Retry           *RetryPolicy      `hcl:"-" json:"retry,omitempty"`
+	Auth            *AuthConfig       `hcl:"-" json:"auth,omitempty"`
+	IDStrategy      string            `hcl:"id_strategy,optional" json:"id_strategy,omitempty"`
+
+	// Notification requests are sent without an id and get no response
+	Notification bool `hcl:"notification,optional" json:"notification,omitempty"`
+
+	// DependsOn lists the requests whose results are referenced in params.
+	// Such params are kept as an expression and resolved at execution time.
+	DependsOn   []string         `hcl:"-" json:"depends_on,omitempty"`
+	ParamsExpr  hcl.Expression   `hcl:"-" json:"-"`
+	EvalContext *hcl.EvalContext `hcl:"-" json:"-"`
 }
 
 // NewRequest creates a new Request with initialized maps
@@ -43,30 +217,90 @@ func NewRequest(name string) *Request {
 	}
 }
 
+// Expectation holds the assertions declared in a request's expect block
+type Expectation struct {
+	ResultType  string         `json:"result_type,omitempty"`
+	Equals      any            `json:"equals,omitempty"`
+	HasEquals   bool           `json:"-"` // distinguishes `equals = null` from no check
+	JSONPath    map[string]any `json:"jsonpath,omitempty"`
+	ErrorCode   *int           `json:"error_code,omitempty"`
+	MaxDuration time.Duration  `json:"max_duration,omitempty"`
+}
+
+// Batch represents a named group of requests sent as JSON-RPC batches
+type Batch struct {
+	Name     string   `json:"name"`
+	Requests []string `json:"requests"`
+}
+
+// Subscription represents a subscribe block: a subscribe call whose
+// notifications are streamed, and the method that cancels it
+type Subscription struct {
+	Name        string   `json:"name"`
+	Request     *Request `json:"request"`
+	Unsubscribe string   `json:"unsubscribe"`
+}
+
+// Notification is a server notification received for a subscription
+type Notification struct {
+	Subscription   string          `json:"subscription"`
+	SubscriptionID string          `json:"subscription_id"`
+	Sequence       int             `json:"sequence"`
+	Received       time.Time       `json:"received"`
+	Result         json.RawMessage `json:"result"`
+}
+
 // HCLFile represents the entire parsed HCL file structure
 type HCLFile struct {
-	Configs  map[string]*Config
-	Requests []*Request
+	Configs       map[string]*Config
+	Requests      []*Request
+	Batches       []*Batch
+	Subscriptions []*Subscription
 }
 
 // NewHCLFile creates a new HCLFile with initialized maps
 func NewHCLFile() *HCLFile {
 	return &HCLFile{
-		Configs:  make(map[string]*Config),
-		Requests: make([]*Request, 0),
+		Configs:       make(map[string]*Config),
+		Requests:      make([]*Request, 0),
+		Batches:       make([]*Batch, 0),
+		Subscriptions: make([]*Subscription, 0),
+	}
+}
+
+// FindSubscription returns the subscription with the given name, or nil if
+// there is none
+func (f *HCLFile) FindSubscription(name string) *Subscription {
+	for _, subscription := range f.Subscriptions {
+		if subscription.Name == name {
+			return subscription
+		}
 	}
+	return nil
 }
 
-// JSONRPCRequest represents a JSON-RPC 2.0 request
+// FindBatch returns the batch with the given name, or nil if there is none
+func (f *HCLFile) FindBatch(name string) *Batch {
+	for _, batch := range f.Batches {
+		if batch.Name == name {
+			return batch
+		}
+	}
+	return nil
+}
+
+// JSONRPCRequest represents a JSON-RPC 2.0 request. A nil ID makes it a
+// notification.
 type JSONRPCRequest struct {
 	JSONRPC string `json:"jsonrpc"`
 	Method  string `json:"method"`
 	Params  any    `json:"params"`
-	ID      int    `json:"id"`
+	ID      any    `json:"id,omitempty"`
 }
 
-// NewJSONRPCRequest creates a new JSON-RPC request
-func NewJSONRPCRequest(method string, params any, id int) *JSONRPCRequest {
+// NewJSONRPCRequest creates a new JSON-RPC request. id is a number or a
+// string.
+func NewJSONRPCRequest(method string, params any, id any) *JSONRPCRequest {
 	return &JSONRPCRequest{
 		JSONRPC: constants.DefaultJSONRPCVersion,
 		Method:  method,
@@ -75,12 +309,53 @@ func NewJSONRPCRequest(method string, params any, id int) *JSONRPCRequest {
 	}
 }
 
-// JSONRPCResponse represents a JSON-RPC 2.0 response
+// NewJSONRPCNotification creates a JSON-RPC notification, a request without
+// an id that the server does not answer
+func NewJSONRPCNotification(method string, params any) *JSONRPCRequest {
+	return NewJSONRPCRequest(method, params, nil)
+}
+
+// IsNotification returns true if the request has no id
+func (r *JSONRPCRequest) IsNotification() bool {
+	return r.ID == nil
+}
+
+// JSONRPCResponse represents a JSON-RPC 2.0 response. The id is kept as
+// raw JSON, so that numbers, strings and null all decode.
 type JSONRPCResponse struct {
 	JSONRPC string          `json:"jsonrpc"`
 	Result  json.RawMessage `json:"result,omitempty"`
 	Error   *RPCError       `json:"error,omitempty"`
-	ID      int             `json:"id"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// IDKey returns the normalized id of the response, see IDKey
+func (r *JSONRPCResponse) IDKey() string {
+	return IDKey(r.ID)
+}
+
+// IDKey normalizes a JSON-RPC id to compact JSON, so that ids compare
+// equal regardless of their Go type: 1 and json.RawMessage("1") both give
+// "1", "a" gives "\"a\"". Missing ids give "null".
+func IDKey(id any) string {
+	var data []byte
+	switch v := id.(type) {
+	case json.RawMessage:
+		data = v
+	case nil:
+	default:
+		marshalled, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		data = marshalled
+	}
+
+	var compact bytes.Buffer
+	if len(data) == 0 || json.Compact(&compact, data) != nil {
+		return "null"
+	}
+	return compact.String()
 }
 
 // IsError returns true if the response contains an error
@@ -100,12 +375,67 @@ func (e *RPCError) Error() string {
 	return e.Message
 }
 
+// Errors of requests interrupted by cancelling a run, e.g. with Ctrl-C or
+// after the total timeout. Results wrap them with the cancellation cause.
+var (
+	// ErrCancelled is the error of a request that was in flight
+	ErrCancelled = errors.New("cancelled")
+
+	// ErrNotRun is the error of a request that had not started
+	ErrNotRun = errors.New("not run")
+)
+
 // ExecutionResult contains the result of executing a request
 type ExecutionResult struct {
-	Request  *Request
-	Response *JSONRPCResponse
-	Duration time.Duration
-	Error    error
+	Request    *Request
+	Response   *JSONRPCResponse
+	Duration   time.Duration
+	Error      error
+	Assertions []AssertionResult
+
+	// Attempts is the number of times the request was sent, including retries
+	Attempts int
+
+	// Trace records the last attempt on the wire when tracing is enabled
+	Trace *Trace
+}
+
+// Trace is the wire-level record of one attempt. Headers are recorded
+// unmasked; masking is left to the output.
+type Trace struct {
+	Request  TraceRequest
+	Response *TraceResponse // nil if no response was received
+	Timings  TraceTimings
+}
+
+// TraceRequest is the outgoing request of a trace. Method and Headers are
+// only set for HTTP.
+type TraceRequest struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    string
+}
+
+// TraceResponse is the raw response of a trace. Status and Headers are only
+// set for HTTP. Body shares its buffer with the decoded response, so the
+// trace of a large response does not keep a copy of it.
+type TraceResponse struct {
+	Status     string
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+}
+
+// TraceTimings breaks down the duration of an attempt. Phases that did not
+// happen, such as DNS on a reused connection, are zero.
+type TraceTimings struct {
+	DNS             time.Duration
+	Connect         time.Duration
+	TLS             time.Duration
+	TimeToFirstByte time.Duration
+	Total           time.Duration
+	ReusedConn      bool
 }
 
 // IsSuccess returns true if the execution was successful
@@ -113,11 +443,164 @@ func (r *ExecutionResult) IsSuccess() bool {
 	return r.Error == nil && (r.Response == nil || !r.Response.IsError())
 }
 
+// AssertionsPassed returns true if every evaluated assertion passed
+func (r *ExecutionResult) AssertionsPassed() bool {
+	for _, assertion := range r.Assertions {
+		if !assertion.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// AssertionResult is the outcome of a single expect check
+type AssertionResult struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// BenchReport summarizes a bench run: every request sent and, when more
+// than one request was benchmarked, each request on its own
+type BenchReport struct {
+	BenchStats
+
+	Elapsed     time.Duration // wall time from the first request to the last response
+	Concurrency int
+	Rate        string // target rate of an open-loop run; empty for closed-loop
+	Interrupted bool   // stopped early by Ctrl-C
+	PerRequest  []BenchStats
+}
+
+// BenchStats are the outcomes and latencies of benchmarked requests.
+// Latencies are those of successful requests.
+type BenchStats struct {
+	Name      string
+	Total     int
+	Succeeded int
+	Errors    []BenchError // most frequent first
+	Latency   LatencySummary
+	Histogram []HistogramBucket
+}
+
+// Failed returns the number of failed requests
+func (s *BenchStats) Failed() int {
+	return s.Total - s.Succeeded
+}
+
+// Throughput returns the number of requests per second over elapsed
+func (s *BenchStats) Throughput(elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Total) / elapsed.Seconds()
+}
+
+// BenchError counts the failures of one kind ("transport", "timeout",
+// "http" or "rpc") and code (HTTP status or JSON-RPC error code)
+type BenchError struct {
+	Kind    string
+	Code    int
+	Count   int
+	Message string // message of the first failure
+}
+
+// LatencySummary holds latency percentiles
+type LatencySummary struct {
+	Min  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+}
+
+// HistogramBucket counts latencies above the previous bucket's bound up to
+// and including UpperBound
+type HistogramBucket struct {
+	UpperBound time.Duration
+	Count      int
+}
+
+// DiffStatus is the outcome of comparing one request across two configs
+type DiffStatus string
+
+const (
+	DiffSame      DiffStatus = "same"
+	DiffDifferent DiffStatus = "different"
+	DiffFailed    DiffStatus = "failed" // a side has no response to compare
+)
+
+// DiffResult compares the responses of one request under two configs
+type DiffResult struct {
+	Request string
+	Status  DiffStatus
+	Changes []DiffChange
+	Errors  []string // failures of DiffFailed results, prefixed with the config name
+}
+
+// DiffChangeKind is how a value differs between two responses
+type DiffChangeKind string
+
+const (
+	DiffAdded   DiffChangeKind = "added"
+	DiffRemoved DiffChangeKind = "removed"
+	DiffChanged DiffChangeKind = "changed"
+)
+
+// DiffChange is a value that differs between two responses. From is unset
+// for added values and To for removed ones.
+type DiffChange struct {
+	Path string
+	Kind DiffChangeKind
+	From any
+	To   any
+}
+
 // EffectiveConfig holds the final merged configuration for a request
 type EffectiveConfig struct {
 	URL     string
 	Headers map[string]string
 	Timeout int
+	Command []string
+	Framing string
+	Retry   *RetryPolicy // nil when requests are not retried
+
+	// RateLimit is shared by all requests resolving to Profile, or by all
+	// requests of a run when it is set by --rate (RateLimitGlobal)
+	RateLimit       string
+	RateLimitGlobal bool
+	Profile         string // empty when no config block applies
+
+	TLS *TLSConfig // nil for the default TLS settings
+
+	IDStrategy string // empty for sequential ids
+
+	MaxResponseSize int64 // in bytes; zero for no limit
+
+	// Auth and Credential are applied by the executor, which puts the
+	// resulting headers in Credentials right before sending. Credentials are
+	// not part of the identity of shared connections.
+	Auth        *AuthConfig
+	Credential  *CredentialHelper
+	Credentials map[string]string
+
+	// Signing is applied by the executor over the exact payload of each
+	// attempt; the signature headers are added to Credentials
+	Signing *SigningConfig
+}
+
+// HasEndpoint returns true if a URL or a command is configured
+func (c *EffectiveConfig) HasEndpoint() bool {
+	return c.URL != "" || len(c.Command) > 0
+}
+
+// Endpoint returns a display name for the configured URL or command
+func (c *EffectiveConfig) Endpoint() string {
+	if len(c.Command) > 0 {
+		return strings.Join(c.Command, " ")
+	}
+	return c.URL
 }
 
 // NewEffectiveConfig creates a new EffectiveConfig with defaults
@@ -130,10 +613,14 @@ func NewEffectiveConfig() *EffectiveConfig {
 
 // CLIOverrides holds configuration overrides from CLI flags
 type CLIOverrides struct {
-	URL     string
-	Headers map[string]string
-	Timeout int
-	Config  string
+	URL       string
+	Headers   map[string]string
+	Timeout   int
+	Config    string
+	RateLimit string
+	TLS       *TLSConfig
+
+	MaxResponseSize int64 // in bytes; zero keeps the configured limit
 }
 
 // NewCLIOverrides creates a new CLIOverrides with initialized maps
@@ -78,7 +78,7 @@ func TestNewJSONRPCRequest(t *testing.T) {
 	}
 
 	if req.ID != id {
-		t.Errorf("Expected ID %d, got %d", id, req.ID)
+		t.Errorf("Expected ID %d, got %v", id, req.ID)
 	}
 }
 
@@ -113,6 +113,46 @@ func TestJSONRPCResponse_IsError(t *testing.T) {
 	}
 }
 
+func TestIDKey(t *testing.T) {
+	tests := []struct {
+		name string
+		id   any
+		want string
+	}{
+		{name: "int", id: 1, want: "1"},
+		{name: "int64", id: int64(42), want: "42"},
+		{name: "string", id: "call-1", want: `"call-1"`},
+		{name: "raw number", id: json.RawMessage(" 1 "), want: "1"},
+		{name: "raw string", id: json.RawMessage(`"call-1"`), want: `"call-1"`},
+		{name: "raw null", id: json.RawMessage("null"), want: "null"},
+		{name: "nil", id: nil, want: "null"},
+		{name: "empty raw", id: json.RawMessage(nil), want: "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IDKey(tt.id); got != tt.want {
+				t.Errorf("IDKey(%v) = %s, want %s", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewJSONRPCNotification(t *testing.T) {
+	req := NewJSONRPCNotification("ping", []any{})
+	if !req.IsNotification() {
+		t.Error("IsNotification() = false, want true")
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"jsonrpc":"2.0","method":"ping","params":[]}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+}
+
 func TestRPCError_Error(t *testing.T) {
 	msg := "Invalid params"
 	rpcErr := &RPCError{